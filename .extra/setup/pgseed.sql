@@ -7,18 +7,21 @@ CREATE EXTENSION IF NOT EXISTS pgcrypto;
 -- Api Keys Table
 CREATE TABLE IF NOT EXISTS api_keys (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    key TEXT NOT NULL UNIQUE,
+    key_hash TEXT NOT NULL UNIQUE,
+    key_prefix TEXT NOT NULL,
     permissions TEXT[],
     comments TEXT[],
     version INTEGER,
+    expires_at TIMESTAMP,
+    allowed_origins TEXT[],
     status BOOLEAN DEFAULT TRUE,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 -- Api Keys Indexes
-CREATE INDEX IF NOT EXISTS api_keys_key_status_idx
-ON api_keys (key, status);
+CREATE INDEX IF NOT EXISTS api_keys_key_hash_status_idx
+ON api_keys (key_hash, status);
 
 -- Roles Table
 CREATE TABLE IF NOT EXISTS roles (
@@ -34,9 +37,10 @@ CREATE TABLE IF NOT EXISTS users (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     name TEXT NOT NULL,
     email TEXT NOT NULL UNIQUE,
-    password TEXT NOT NULL,
+    password TEXT,
 		profile_pic_url TEXT,
 		verified BOOLEAN DEFAULT FALSE,
+		mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE,
     status BOOLEAN DEFAULT TRUE,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
@@ -49,12 +53,41 @@ CREATE TABLE IF NOT EXISTS user_roles (
     PRIMARY KEY (user_id, role_id)
 );
 
+-- Permissions Table
+CREATE TABLE IF NOT EXISTS permissions (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	code TEXT NOT NULL UNIQUE,
+	description TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Join Table for Roles <-> Permissions
+CREATE TABLE IF NOT EXISTS role_permissions (
+	role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	permission_id UUID NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+	PRIMARY KEY (role_id, permission_id)
+);
+
+-- Role Inheritance Table, a role holds the permissions of the roles it inherits
+CREATE TABLE IF NOT EXISTS role_inheritance (
+	role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	inherited_role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	PRIMARY KEY (role_id, inherited_role_id),
+	CHECK (role_id <> inherited_role_id)
+);
+
 -- Keystore Table
 CREATE TABLE IF NOT EXISTS keystore (
 	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
 	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
 	p_key TEXT NOT NULL,
 	s_key TEXT NOT NULL,
+	family_id UUID NOT NULL,
+	parent_id UUID REFERENCES keystore(id) ON DELETE SET NULL,
+	rotated_at TIMESTAMP,
+	user_agent TEXT,
+	ip TEXT,
+	last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 	status BOOLEAN DEFAULT TRUE,
 	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
@@ -70,6 +103,133 @@ ON keystore (user_id, p_key, status);
 CREATE INDEX IF NOT EXISTS keystore_user_pkey_skey_status_idx
 ON keystore (user_id, p_key, s_key, status);
 
+CREATE INDEX IF NOT EXISTS keystore_family_idx
+ON keystore (family_id);
+
+-- Token Reuse Events Table
+CREATE TABLE IF NOT EXISTS token_reuse_events (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	family_id UUID NOT NULL,
+	keystore_id UUID NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- User Tokens Table
+CREATE TABLE IF NOT EXISTS user_tokens (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	purpose TEXT NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	expires_at TIMESTAMP NOT NULL,
+	used_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- User Tokens Indexes
+CREATE INDEX IF NOT EXISTS user_tokens_user_purpose_idx
+ON user_tokens (user_id, purpose, created_at DESC);
+
+-- User MFA Table
+CREATE TABLE IF NOT EXISTS user_mfa (
+	user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	secret TEXT NOT NULL,
+	last_used_step BIGINT NOT NULL DEFAULT 0,
+	confirmed_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- User Recovery Codes Table
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	code_hash TEXT NOT NULL UNIQUE,
+	used_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS user_recovery_codes_user_idx ON user_recovery_codes (user_id);
+
+-- User Identities Table
+CREATE TABLE IF NOT EXISTS user_identities (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	provider TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	email TEXT,
+	last_login_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS user_identities_user_idx ON user_identities (user_id);
+
+-- Personal Access Tokens Table
+CREATE TABLE IF NOT EXISTS access_tokens (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	token_prefix TEXT NOT NULL,
+	scopes TEXT[] NOT NULL,
+	expires_at TIMESTAMP,
+	last_used_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS access_tokens_user_idx ON access_tokens (user_id);
+
+-- Invites Table
+CREATE TABLE IF NOT EXISTS invites (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	email TEXT NOT NULL,
+	roles TEXT[] NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+	accepted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+	expires_at TIMESTAMP NOT NULL,
+	accepted_at TIMESTAMP,
+	revoked_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS invites_pending_email_idx
+	ON invites (email)
+	WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS invites_created_idx ON invites (created_at DESC);
+
+-- Audit Events Table, append-only
+CREATE TABLE IF NOT EXISTS audit_events (
+	id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	action TEXT NOT NULL,
+	outcome TEXT NOT NULL,
+	actor_id UUID,
+	target_type TEXT NOT NULL DEFAULT '',
+	target_id TEXT NOT NULL DEFAULT '',
+	ip TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	metadata JSONB NOT NULL DEFAULT '{}',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actor_id, id DESC);
+CREATE INDEX IF NOT EXISTS audit_events_action_idx ON audit_events (action, id DESC);
+CREATE INDEX IF NOT EXISTS audit_events_target_idx ON audit_events (target_type, target_id, id DESC);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER audit_events_append_only
+BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
 -- Messages Table
 CREATE TABLE messages (
 	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -92,6 +252,10 @@ CREATE TABLE IF NOT EXISTS blogs (
 	img_url TEXT,
 	slug TEXT NOT NULL UNIQUE,
 	score DOUBLE PRECISION DEFAULT 0.01,
+	views BIGINT NOT NULL DEFAULT 0,
+	likes BIGINT NOT NULL DEFAULT 0,
+	comments BIGINT NOT NULL DEFAULT 0,
+	flagged BOOLEAN NOT NULL DEFAULT FALSE,
 	submitted BOOLEAN DEFAULT FALSE,
 	drafted BOOLEAN DEFAULT TRUE,
 	published BOOLEAN DEFAULT FALSE,
@@ -114,13 +278,126 @@ CREATE INDEX IF NOT EXISTS blogs_search_idx
 ON blogs
 USING GIN (to_tsvector('english', title));
 
+CREATE INDEX IF NOT EXISTS blogs_flagged_idx
+ON blogs (id)
+WHERE flagged = TRUE;
+
+-- Blog View Flushes Table
+CREATE TABLE IF NOT EXISTS blog_view_flushes (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS blog_view_flushes_created_idx ON blog_view_flushes (created_at);
+
+-- Blog Reactions Table
+CREATE TABLE IF NOT EXISTS blog_reactions (
+	blog_id UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	reaction TEXT NOT NULL DEFAULT 'LIKE',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (blog_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS blog_reactions_user_idx ON blog_reactions (user_id);
+
+-- a deleted user takes back the likes, the reactions removed by an unlike belong to an
+-- existing user and are counted by the like service
+CREATE OR REPLACE FUNCTION blog_reactions_take_back_likes() RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE blogs AS b
+	SET
+		likes = b.likes - r.removed,
+		score = LEAST(1, 0.01 + LN(1 + b.likes - r.removed + 2 * b.comments) / LN(10001))
+	FROM (
+		SELECT o.blog_id, COUNT(*) AS removed
+		FROM removed_reactions o
+		WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = o.user_id)
+		GROUP BY o.blog_id
+	) r
+	WHERE b.id = r.blog_id;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER blog_reactions_take_back_likes
+AFTER DELETE ON blog_reactions
+REFERENCING OLD TABLE AS removed_reactions
+FOR EACH STATEMENT EXECUTE FUNCTION blog_reactions_take_back_likes();
+
+-- Blog Reports Table
+CREATE TABLE IF NOT EXISTS blog_reports (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	blog_id UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+	reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	reason TEXT NOT NULL,
+	text TEXT,
+	resolution TEXT,
+	resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+	resolved_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS blog_reports_open_idx
+ON blog_reports (blog_id, reporter_id)
+WHERE resolution IS NULL;
+
+CREATE INDEX IF NOT EXISTS blog_reports_reporter_idx ON blog_reports (reporter_id);
+
+-- Blog Revisions Table
+CREATE TABLE IF NOT EXISTS blog_revisions (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	blog_id UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+	version INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	title TEXT NOT NULL,
+	description TEXT NOT NULL,
+	text TEXT NOT NULL,
+	tags TEXT[],
+	restored_from INTEGER,
+	created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (blog_id, version)
+);
+
+-- Comments Table
+CREATE TABLE IF NOT EXISTS comments (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	blog_id UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+	parent_id UUID REFERENCES comments(id) ON DELETE CASCADE,
+	author_id UUID NOT NULL REFERENCES users(id),
+	text TEXT NOT NULL,
+	depth SMALLINT NOT NULL DEFAULT 0,
+	replies BIGINT NOT NULL DEFAULT 0,
+	pinned BOOLEAN NOT NULL DEFAULT FALSE,
+	hidden BOOLEAN NOT NULL DEFAULT FALSE,
+	status BOOLEAN NOT NULL DEFAULT TRUE,
+	edited_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Comments Table Indexes
+CREATE INDEX IF NOT EXISTS comments_blog_idx
+ON comments (blog_id, pinned DESC, created_at DESC)
+WHERE parent_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS comments_parent_idx
+ON comments (parent_id, created_at)
+WHERE parent_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS comments_author_idx ON comments (author_id);
+
 -- Insert Data
 -- --------------
 
 -- Insert API Key
-INSERT INTO api_keys (key, permissions, comments, version, status, created_at, updated_at)
+INSERT INTO api_keys (key_hash, key_prefix, permissions, comments, version, status, created_at, updated_at)
 VALUES (
-    '1D3F2DD1A5DE725DD4DF1D82BBB37',
+    encode(sha256(convert_to('1D3F2DD1A5DE725DD4DF1D82BBB37', 'UTF8')), 'hex'),
+    left('1D3F2DD1A5DE725DD4DF1D82BBB37', 6),
     ARRAY['GENERAL'],
     ARRAY['To be used by the xyz vendor'],
     1,
@@ -128,7 +405,21 @@ VALUES (
     NOW(),
     NOW()
 )
-ON CONFLICT (key) DO NOTHING;
+ON CONFLICT (key_hash) DO NOTHING;
+
+-- Insert Admin Tooling API Key
+INSERT INTO api_keys (key_hash, key_prefix, permissions, comments, version, status, created_at, updated_at)
+VALUES (
+    encode(sha256(convert_to('6A1C9E47B02F4D38AE5B71C0D93F2', 'UTF8')), 'hex'),
+    left('6A1C9E47B02F4D38AE5B71C0D93F2', 6),
+    ARRAY['ADMIN'],
+    ARRAY['To be used by the admin tooling'],
+    1,
+    true,
+    NOW(),
+    NOW()
+)
+ON CONFLICT (key_hash) DO NOTHING;
 
 -- Insert Roles
 INSERT INTO roles (code, status, created_at, updated_at)
@@ -139,12 +430,47 @@ VALUES
     ('ADMIN', true, NOW(), NOW())
 ON CONFLICT (code) DO NOTHING;
 
+-- Insert Permissions
+INSERT INTO permissions (code, description)
+VALUES
+    ('blog.write', 'write and submit own blogs'),
+    ('blog.publish', 'review, publish and unpublish blogs'),
+    ('apikey.manage', 'manage the api keys'),
+    ('lockout.manage', 'clear sign in lockouts'),
+    ('permission.manage', 'inspect and reload the role permissions'),
+    ('user.manage', 'manage the users, their roles and account status'),
+    ('user.impersonate', 'act as another user to reproduce what they see'),
+    ('audit.read', 'query the security audit events'),
+    ('user.invite', 'invite users with the author, editor or admin roles'),
+    ('comment.moderate', 'hide, pin and delete the comments on any blog')
+ON CONFLICT (code) DO NOTHING;
+
+-- Map Roles to Permissions
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+INNER JOIN permissions p
+    ON (r.code = 'AUTHOR' AND p.code = 'blog.write')
+    OR (r.code = 'EDITOR' AND p.code IN ('blog.publish', 'user.invite', 'comment.moderate'))
+    OR (r.code = 'ADMIN' AND p.code IN ('apikey.manage', 'lockout.manage', 'permission.manage', 'user.manage', 'user.impersonate', 'audit.read'))
+ON CONFLICT DO NOTHING;
+
+-- Role Inheritance: ADMIN > EDITOR > AUTHOR
+INSERT INTO role_inheritance (role_id, inherited_role_id)
+SELECT r.id, i.id
+FROM roles r
+INNER JOIN roles i
+    ON (r.code = 'ADMIN' AND i.code = 'EDITOR')
+    OR (r.code = 'EDITOR' AND i.code = 'AUTHOR')
+ON CONFLICT DO NOTHING;
+
 -- Insert Admin User
-INSERT INTO users (name, email, password, status, created_at, updated_at)
+INSERT INTO users (name, email, password, verified, status, created_at, updated_at)
 VALUES (
     'Admin', 
     'admin@afteracademy.com', 
     '$2a$10$psWmSrmtyZYvtIt/FuJL1OLqsK3iR1fZz5.wUYFuSNkkt.EOX9mLa',
+    true,
     true, 
     NOW(), 
     NOW()
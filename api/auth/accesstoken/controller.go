@@ -0,0 +1,84 @@
+package accesstoken
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/accesstoken/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	coredto "github.com/afteracademy/goserve/v2/dto"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/auth/access-tokens", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+	}
+}
+
+// MountRoutes declares no token scope, so the tokens are only managed from an own signed in session
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(common.KeyPermission(model.GeneralPermission), c.Authentication(), common.RejectImpersonation())
+	group.POST("", c.createAccessTokenHandler)
+	group.GET("", c.getAccessTokensHandler)
+	group.DELETE("/id/:id", c.revokeAccessTokenHandler)
+}
+
+func (c *controller) createAccessTokenHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.AccessTokenCreate](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	secret, err := c.service.CreateAccessToken(user, body)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "access token created, store the token now since it is not shown again", secret)
+}
+
+func (c *controller) getAccessTokensHandler(ctx *gin.Context) {
+	user := c.MustGetUser(ctx)
+
+	accessTokens, err := c.service.GetAccessTokens(user)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", &accessTokens)
+}
+
+func (c *controller) revokeAccessTokenHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	err = c.service.RevokeAccessToken(user, uuidParam.ID)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "access token revoked successfully")
+}
@@ -0,0 +1,89 @@
+package accesstoken
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/accesstoken/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func mockAuthProvider(user *userModel.User) *network.MockAuthenticationProvider {
+	provider := new(network.MockAuthenticationProvider)
+	provider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, user)
+		ctx.Next()
+	}))
+	return provider
+}
+
+func newAccessTokenInfo(name string) *dto.AccessTokenInfo {
+	return &dto.AccessTokenInfo{
+		ID:          uuid.New(),
+		Name:        name,
+		TokenPrefix: "gspat_a1b2c3",
+		Scopes:      []model.Scope{model.ScopeBlogRead},
+		CreatedAt:   time.Now(),
+	}
+}
+
+func TestAccessTokenController_CreateInvalidScope(t *testing.T) {
+	user := &userModel.User{ID: uuid.New()}
+	service := new(MockService)
+
+	c := NewController(mockAuthProvider(user), new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "POST", "/auth/access-tokens", `{"name":"ci","scopes":["admin:all"]}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "CreateAccessToken", mock.Anything, mock.Anything)
+}
+
+func TestAccessTokenController_CreateSuccess(t *testing.T) {
+	user := &userModel.User{ID: uuid.New()}
+	createDto := &dto.AccessTokenCreate{Name: "ci", Scopes: []model.Scope{model.ScopeBlogRead}}
+	secret := dto.NewAccessTokenSecret("gspat_a1b2c3d4", newAccessTokenInfo("ci"))
+
+	service := new(MockService)
+	service.On("CreateAccessToken", user, createDto).Return(secret, nil)
+
+	c := NewController(mockAuthProvider(user), new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "POST", "/auth/access-tokens", `{"name":"ci","scopes":["blog:read"]}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"token":"gspat_a1b2c3d4"`)
+	service.AssertExpectations(t)
+}
+
+func TestAccessTokenController_GetAccessTokens(t *testing.T) {
+	user := &userModel.User{ID: uuid.New()}
+
+	service := new(MockService)
+	service.On("GetAccessTokens", user).Return([]*dto.AccessTokenInfo{newAccessTokenInfo("ci")}, nil)
+
+	c := NewController(mockAuthProvider(user), new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "GET", "/auth/access-tokens", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"name":"ci"`)
+}
+
+func TestAccessTokenController_RevokeNotFound(t *testing.T) {
+	user := &userModel.User{ID: uuid.New()}
+	id := uuid.New()
+
+	service := new(MockService)
+	service.On("RevokeAccessToken", user, id).Return(network.NewNotFoundError("access token not found", nil))
+
+	c := NewController(mockAuthProvider(user), new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "DELETE", "/auth/access-tokens/id/"+id.String(), "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
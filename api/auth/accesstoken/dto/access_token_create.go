@@ -0,0 +1,13 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+)
+
+type AccessTokenCreate struct {
+	Name      string        `json:"name" validate:"required,min=1,max=100"`
+	Scopes    []model.Scope `json:"scopes" validate:"required,min=1,dive,oneof=blog:read blog:write profile:read"`
+	ExpiresAt *time.Time    `json:"expiresAt,omitempty" validate:"omitempty"`
+}
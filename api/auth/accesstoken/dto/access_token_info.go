@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve/v2/utility"
+	"github.com/google/uuid"
+)
+
+type AccessTokenInfo struct {
+	ID          uuid.UUID     `json:"id" validate:"required"`
+	Name        string        `json:"name" validate:"required"`
+	TokenPrefix string        `json:"tokenPrefix" validate:"required"`
+	Scopes      []model.Scope `json:"scopes" validate:"required"`
+	ExpiresAt   *time.Time    `json:"expiresAt,omitempty"`
+	LastUsedAt  *time.Time    `json:"lastUsedAt,omitempty"`
+	CreatedAt   time.Time     `json:"createdAt" validate:"required"`
+}
+
+func NewAccessTokenInfo(accessToken *model.AccessToken) (*AccessTokenInfo, error) {
+	return utility.MapTo[AccessTokenInfo](accessToken)
+}
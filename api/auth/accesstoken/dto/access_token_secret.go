@@ -0,0 +1,15 @@
+package dto
+
+// AccessTokenSecret carries the raw token, it is only sent once when the token is created
+// since only its hash is stored
+type AccessTokenSecret struct {
+	Token       string           `json:"token" validate:"required"`
+	AccessToken *AccessTokenInfo `json:"accessToken" validate:"required"`
+}
+
+func NewAccessTokenSecret(token string, info *AccessTokenInfo) *AccessTokenSecret {
+	return &AccessTokenSecret{
+		Token:       token,
+		AccessToken: info,
+	}
+}
@@ -0,0 +1,33 @@
+package accesstoken
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/accesstoken/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) CreateAccessToken(user *userModel.User, d *dto.AccessTokenCreate) (*dto.AccessTokenSecret, error) {
+	args := m.Called(user, d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AccessTokenSecret), args.Error(1)
+}
+
+func (m *MockService) GetAccessTokens(user *userModel.User) ([]*dto.AccessTokenInfo, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.AccessTokenInfo), args.Error(1)
+}
+
+func (m *MockService) RevokeAccessToken(user *userModel.User, id uuid.UUID) error {
+	args := m.Called(user, id)
+	return args.Error(0)
+}
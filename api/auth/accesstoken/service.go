@@ -0,0 +1,179 @@
+package accesstoken
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/accesstoken/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/cache"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/utils"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/afteracademy/goserve/v2/utility"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Service interface {
+	CreateAccessToken(user *userModel.User, d *dto.AccessTokenCreate) (*dto.AccessTokenSecret, error)
+	GetAccessTokens(user *userModel.User) ([]*dto.AccessTokenInfo, error)
+	RevokeAccessToken(user *userModel.User, id uuid.UUID) error
+}
+
+type service struct {
+	db        postgres.Database
+	authCache cache.Service
+}
+
+func NewService(db postgres.Database, authCache cache.Service) Service {
+	return &service{
+		db:        db,
+		authCache: authCache,
+	}
+}
+
+const accessTokenColumns = `
+	id,
+	user_id,
+	name,
+	token_hash,
+	token_prefix,
+	scopes,
+	expires_at,
+	last_used_at,
+	created_at,
+	updated_at
+`
+
+func scanAccessToken(row pgx.Row) (*model.AccessToken, error) {
+	var accessToken model.AccessToken
+	err := row.Scan(
+		&accessToken.ID,
+		&accessToken.UserID,
+		&accessToken.Name,
+		&accessToken.TokenHash,
+		&accessToken.TokenPrefix,
+		&accessToken.Scopes,
+		&accessToken.ExpiresAt,
+		&accessToken.LastUsedAt,
+		&accessToken.CreatedAt,
+		&accessToken.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &accessToken, nil
+}
+
+func (s *service) CreateAccessToken(user *userModel.User, d *dto.AccessTokenCreate) (*dto.AccessTokenSecret, error) {
+	if d.ExpiresAt != nil && !d.ExpiresAt.After(time.Now()) {
+		return nil, network.NewBadRequestError("expiresAt must be in the future", nil)
+	}
+
+	random, err := utility.GenerateRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	token := model.AccessTokenPrefix + random
+
+	ctx := context.Background()
+
+	query := `
+		INSERT INTO access_tokens (
+			user_id,
+			name,
+			token_hash,
+			token_prefix,
+			scopes,
+			expires_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING` + accessTokenColumns
+
+	accessToken, err := scanAccessToken(s.db.Pool().QueryRow(
+		ctx,
+		query,
+		user.ID,
+		d.Name,
+		utils.HashToken(token),
+		model.AccessTokenDisplayPrefix(token),
+		d.Scopes,
+		d.ExpiresAt,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := dto.NewAccessTokenInfo(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewAccessTokenSecret(token, info), nil
+}
+
+func (s *service) GetAccessTokens(user *userModel.User) ([]*dto.AccessTokenInfo, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT` + accessTokenColumns + `
+		FROM access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.Pool().Query(ctx, query, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos := []*dto.AccessTokenInfo{}
+
+	for rows.Next() {
+		accessToken, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		d, err := dto.NewAccessTokenInfo(accessToken)
+		if err != nil {
+			return nil, err
+		}
+
+		dtos = append(dtos, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dtos, nil
+}
+
+// RevokeAccessToken deletes a token of the user, the token stops working with the cache entry cleared
+func (s *service) RevokeAccessToken(user *userModel.User, id uuid.UUID) error {
+	ctx := context.Background()
+
+	query := `
+		DELETE FROM access_tokens
+		WHERE id = $1
+		  AND user_id = $2
+		RETURNING token_hash
+	`
+
+	var tokenHash string
+	err := s.db.Pool().QueryRow(ctx, query, id, user.ID).Scan(&tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return network.NewNotFoundError("access token not found", err)
+		}
+		return err
+	}
+
+	s.authCache.ClearAccessTokens(tokenHash)
+	return nil
+}
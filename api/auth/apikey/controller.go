@@ -0,0 +1,152 @@
+package apikey
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/apikey/dto"
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	coredto "github.com/afteracademy/goserve/v2/dto"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/admin/apikeys", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(
+		common.KeyPermission(authModel.AdminPermission),
+		c.Authentication(),
+		common.RejectImpersonation(),
+		c.Authorization(string(userModel.PermissionApiKeyManage)),
+	)
+	group.POST("", c.createApiKeyHandler)
+	group.GET("", c.getApiKeysHandler)
+	group.GET("/id/:id", c.getApiKeyHandler)
+	group.DELETE("/id/:id", c.disableApiKeyHandler)
+	group.POST("/id/:id/rotate", c.rotateApiKeyHandler)
+	group.POST("/id/:id/comments", c.annotateApiKeyHandler)
+}
+
+func (c *controller) createApiKeyHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.ApiKeyCreate](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	secret, err := c.service.CreateApiKey(body)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "api key created, store the key now since it is not shown again", secret)
+}
+
+func (c *controller) getApiKeysHandler(ctx *gin.Context) {
+	pagination, err := network.ReqQuery[coredto.Pagination](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	apiKeys, err := c.service.GetPaginatedApiKeys(pagination)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", &apiKeys)
+}
+
+func (c *controller) getApiKeyHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	apiKey, err := c.service.GetApiKey(uuidParam.ID)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", apiKey)
+}
+
+func (c *controller) disableApiKeyHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	err = c.service.DisableApiKey(uuidParam.ID)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "api key disabled successfully")
+}
+
+func (c *controller) rotateApiKeyHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	body, err := network.ReqBody[dto.ApiKeyRotate](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	secret, err := c.service.RotateApiKey(uuidParam.ID, body)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "api key rotated, store the key now since it is not shown again", secret)
+}
+
+func (c *controller) annotateApiKeyHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	body, err := network.ReqBody[dto.ApiKeyAnnotate](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	apiKey, err := c.service.AnnotateApiKey(uuidParam.ID, body)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "api key updated successfully", apiKey)
+}
@@ -0,0 +1,128 @@
+package apikey
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/apikey/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func mockProviders() (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	mockAuthzProvider := new(network.MockAuthorizationProvider)
+	mockAuthzProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	return mockAuthProvider, mockAuthzProvider
+}
+
+func newApiKeyInfo(id uuid.UUID, prefix string, version int) *dto.ApiKeyInfo {
+	return &dto.ApiKeyInfo{
+		ID:          id,
+		KeyPrefix:   prefix,
+		Version:     version,
+		Permissions: []model.Permission{model.ReadPermission},
+		Status:      true,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+func TestApiKeyController_CreateBadRequest(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider := mockProviders()
+	service := new(MockService)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, service)
+
+	rr := network.MockTestController(t, "POST", "/admin/apikeys", `{"permissions":["ROOT"]}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "CreateApiKey", mock.Anything)
+}
+
+func TestApiKeyController_CreateSuccess(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider := mockProviders()
+	body := &dto.ApiKeyCreate{Permissions: []model.Permission{model.ReadPermission}}
+	secret := dto.NewApiKeySecret("rawkey", newApiKeyInfo(uuid.New(), "rawkey", 1))
+
+	service := new(MockService)
+	service.On("CreateApiKey", body).Return(secret, nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, service)
+
+	rr := network.MockTestController(t, "POST", "/admin/apikeys", `{"permissions":["READ"]}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"key":"rawkey"`)
+	service.AssertExpectations(t)
+}
+
+func TestApiKeyController_GetApiKeyHidesKey(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider := mockProviders()
+	id := uuid.New()
+
+	service := new(MockService)
+	service.On("GetApiKey", id).Return(newApiKeyInfo(id, "rawkey", 1), nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/admin/apikeys/id/"+id.String(), "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"keyPrefix":"rawkey"`)
+	assert.NotContains(t, rr.Body.String(), `"key":`)
+}
+
+func TestApiKeyController_DisableNotFound(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider := mockProviders()
+	id := uuid.New()
+
+	service := new(MockService)
+	service.On("DisableApiKey", id).Return(network.NewNotFoundError("api key not found", nil))
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, service)
+
+	rr := network.MockTestController(t, "DELETE", "/admin/apikeys/id/"+id.String(), "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"api key not found"`)
+}
+
+func TestApiKeyController_RotateSuccess(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider := mockProviders()
+	id := uuid.New()
+	secret := dto.NewApiKeySecret("newkey", newApiKeyInfo(uuid.New(), "newkey", 2))
+
+	service := new(MockService)
+	service.On("RotateApiKey", id, &dto.ApiKeyRotate{GraceSec: 3600}).Return(secret, nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, service)
+
+	rr := network.MockTestController(t, "POST", "/admin/apikeys/id/"+id.String()+"/rotate", `{"graceSec":3600}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"key":"newkey"`)
+	assert.Contains(t, rr.Body.String(), `"version":2`)
+	service.AssertExpectations(t)
+}
+
+func TestApiKeyController_AnnotateBadRequest(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider := mockProviders()
+	id := uuid.New()
+
+	service := new(MockService)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, service)
+
+	rr := network.MockTestController(t, "POST", "/admin/apikeys/id/"+id.String()+"/comments", `{"comments":[]}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "AnnotateApiKey", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,5 @@
+package dto
+
+type ApiKeyAnnotate struct {
+	Comments []string `json:"comments" validate:"required,min=1,max=20,dive,min=1,max=500"`
+}
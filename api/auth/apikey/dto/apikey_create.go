@@ -0,0 +1,14 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+)
+
+type ApiKeyCreate struct {
+	Permissions    []model.Permission `json:"permissions" validate:"required,min=1,dive,oneof=READ AUTHOR GENERAL ADMIN"`
+	Comments       []string           `json:"comments" validate:"omitempty,max=20,dive,min=1,max=500"`
+	ExpiresAt      *time.Time         `json:"expiresAt,omitempty" validate:"omitempty"`
+	AllowedOrigins []string           `json:"allowedOrigins" validate:"omitempty,max=50,dive,min=1,max=200"`
+}
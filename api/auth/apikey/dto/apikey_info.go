@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve/v2/utility"
+	"github.com/google/uuid"
+)
+
+type ApiKeyInfo struct {
+	ID             uuid.UUID          `json:"id" validate:"required"`
+	KeyPrefix      string             `json:"keyPrefix" validate:"required"`
+	Version        int                `json:"version" validate:"required,min=1"`
+	Permissions    []model.Permission `json:"permissions" validate:"required"`
+	Comments       []string           `json:"comments"`
+	ExpiresAt      *time.Time         `json:"expiresAt,omitempty"`
+	AllowedOrigins []string           `json:"allowedOrigins"`
+	Status         bool               `json:"status"`
+	CreatedAt      time.Time          `json:"createdAt" validate:"required"`
+	UpdatedAt      time.Time          `json:"updatedAt" validate:"required"`
+}
+
+func NewApiKeyInfo(apiKey *model.ApiKey) (*ApiKeyInfo, error) {
+	return utility.MapTo[ApiKeyInfo](apiKey)
+}
@@ -0,0 +1,7 @@
+package dto
+
+// ApiKeyRotate keeps the old key working for GraceSec so that the clients can switch over,
+// without a grace period the old key is disabled right away
+type ApiKeyRotate struct {
+	GraceSec uint32 `json:"graceSec" validate:"omitempty,max=2592000"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+// ApiKeySecret carries the raw key, it is only sent once when the key is issued
+// since only its hash is stored
+type ApiKeySecret struct {
+	Key    string      `json:"key" validate:"required"`
+	ApiKey *ApiKeyInfo `json:"apiKey" validate:"required"`
+}
+
+func NewApiKeySecret(key string, info *ApiKeyInfo) *ApiKeySecret {
+	return &ApiKeySecret{
+		Key:    key,
+		ApiKey: info,
+	}
+}
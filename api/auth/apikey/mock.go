@@ -0,0 +1,57 @@
+package apikey
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/apikey/dto"
+	coredto "github.com/afteracademy/goserve/v2/dto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) CreateApiKey(d *dto.ApiKeyCreate) (*dto.ApiKeySecret, error) {
+	args := m.Called(d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ApiKeySecret), args.Error(1)
+}
+
+func (m *MockService) GetApiKey(id uuid.UUID) (*dto.ApiKeyInfo, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ApiKeyInfo), args.Error(1)
+}
+
+func (m *MockService) GetPaginatedApiKeys(p *coredto.Pagination) ([]*dto.ApiKeyInfo, error) {
+	args := m.Called(p)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.ApiKeyInfo), args.Error(1)
+}
+
+func (m *MockService) DisableApiKey(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockService) RotateApiKey(id uuid.UUID, d *dto.ApiKeyRotate) (*dto.ApiKeySecret, error) {
+	args := m.Called(id, d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ApiKeySecret), args.Error(1)
+}
+
+func (m *MockService) AnnotateApiKey(id uuid.UUID, d *dto.ApiKeyAnnotate) (*dto.ApiKeyInfo, error) {
+	args := m.Called(id, d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ApiKeyInfo), args.Error(1)
+}
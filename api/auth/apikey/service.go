@@ -0,0 +1,372 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/apikey/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/cache"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/utils"
+	coredto "github.com/afteracademy/goserve/v2/dto"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/afteracademy/goserve/v2/utility"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Service interface {
+	CreateApiKey(d *dto.ApiKeyCreate) (*dto.ApiKeySecret, error)
+	GetApiKey(id uuid.UUID) (*dto.ApiKeyInfo, error)
+	GetPaginatedApiKeys(p *coredto.Pagination) ([]*dto.ApiKeyInfo, error)
+	DisableApiKey(id uuid.UUID) error
+	RotateApiKey(id uuid.UUID, d *dto.ApiKeyRotate) (*dto.ApiKeySecret, error)
+	AnnotateApiKey(id uuid.UUID, d *dto.ApiKeyAnnotate) (*dto.ApiKeyInfo, error)
+}
+
+type service struct {
+	db        postgres.Database
+	authCache cache.Service
+}
+
+func NewService(db postgres.Database, authCache cache.Service) Service {
+	return &service{
+		db:        db,
+		authCache: authCache,
+	}
+}
+
+const apiKeyReturning = `
+	RETURNING
+		id,
+		key_hash,
+		key_prefix,
+		permissions,
+		comments,
+		version,
+		expires_at,
+		allowed_origins,
+		status,
+		created_at,
+		updated_at
+`
+
+func scanApiKey(row pgx.Row) (*model.ApiKey, error) {
+	var apiKey model.ApiKey
+	err := row.Scan(
+		&apiKey.ID,
+		&apiKey.KeyHash,
+		&apiKey.KeyPrefix,
+		&apiKey.Permissions,
+		&apiKey.Comments,
+		&apiKey.Version,
+		&apiKey.ExpiresAt,
+		&apiKey.AllowedOrigins,
+		&apiKey.Status,
+		&apiKey.CreatedAt,
+		&apiKey.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (s *service) CreateApiKey(d *dto.ApiKeyCreate) (*dto.ApiKeySecret, error) {
+	if d.ExpiresAt != nil && !d.ExpiresAt.After(time.Now()) {
+		return nil, network.NewBadRequestError("expiresAt must be in the future", nil)
+	}
+
+	ctx := context.Background()
+
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := s.insertApiKey(ctx, s.db.Pool(), key, 1, d.Permissions, d.Comments, d.ExpiresAt, d.AllowedOrigins)
+	if err != nil {
+		return nil, err
+	}
+
+	return newApiKeySecret(key, apiKey)
+}
+
+func (s *service) GetApiKey(id uuid.UUID) (*dto.ApiKeyInfo, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT
+			id,
+			key_hash,
+			key_prefix,
+			permissions,
+			comments,
+			version,
+			expires_at,
+			allowed_origins,
+			status,
+			created_at,
+			updated_at
+		FROM api_keys
+		WHERE id = $1
+	`
+
+	apiKey, err := scanApiKey(s.db.Pool().QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, network.NewNotFoundError("api key not found", err)
+		}
+		return nil, err
+	}
+
+	return dto.NewApiKeyInfo(apiKey)
+}
+
+func (s *service) GetPaginatedApiKeys(p *coredto.Pagination) ([]*dto.ApiKeyInfo, error) {
+	ctx := context.Background()
+	offset := (p.Page - 1) * p.Limit
+
+	query := `
+		SELECT
+			id,
+			key_hash,
+			key_prefix,
+			permissions,
+			comments,
+			version,
+			expires_at,
+			allowed_origins,
+			status,
+			created_at,
+			updated_at
+		FROM api_keys
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := s.db.Pool().Query(ctx, query, p.Limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos := []*dto.ApiKeyInfo{}
+
+	for rows.Next() {
+		apiKey, err := scanApiKey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		d, err := dto.NewApiKeyInfo(apiKey)
+		if err != nil {
+			return nil, err
+		}
+
+		dtos = append(dtos, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dtos, nil
+}
+
+func (s *service) DisableApiKey(id uuid.UUID) error {
+	ctx := context.Background()
+
+	query := `
+		UPDATE api_keys
+		SET
+			status = FALSE,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		  AND status = TRUE
+		RETURNING key_hash
+	`
+
+	var keyHash string
+	err := s.db.Pool().QueryRow(ctx, query, id).Scan(&keyHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return network.NewNotFoundError("api key not found", err)
+		}
+		return err
+	}
+
+	s.authCache.ClearApiKeys(keyHash)
+	return nil
+}
+
+// RotateApiKey issues a new key with the same permissions and restrictions and a bumped version,
+// the old key is disabled or, with a grace period, expires once the grace period is over
+func (s *service) RotateApiKey(id uuid.UUID, d *dto.ApiKeyRotate) (*dto.ApiKeySecret, error) {
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		SELECT
+			id,
+			key_hash,
+			key_prefix,
+			permissions,
+			comments,
+			version,
+			expires_at,
+			allowed_origins,
+			status,
+			created_at,
+			updated_at
+		FROM api_keys
+		WHERE id = $1
+		  AND status = TRUE
+		FOR UPDATE
+	`
+
+	old, err := scanApiKey(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, network.NewNotFoundError("api key not found", err)
+		}
+		return nil, err
+	}
+
+	if old.IsExpired(time.Now()) {
+		return nil, network.NewBadRequestError("api key expired, create a new one instead", nil)
+	}
+
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	comments := append(old.Comments, fmt.Sprintf("rotated from %s", old.ID))
+
+	apiKey, err := s.insertApiKey(ctx, tx, key, old.Version+1, old.Permissions, comments, old.ExpiresAt, old.AllowedOrigins)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.GraceSec == 0 {
+		_, err = tx.Exec(ctx, `
+			UPDATE api_keys
+			SET
+				status = FALSE,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, old.ID)
+	} else {
+		graceEnd := time.Now().Add(time.Duration(d.GraceSec) * time.Second)
+		if old.ExpiresAt != nil && old.ExpiresAt.Before(graceEnd) {
+			graceEnd = *old.ExpiresAt
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE api_keys
+			SET
+				expires_at = $2,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, old.ID, graceEnd)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	s.authCache.ClearApiKeys(old.KeyHash)
+	return newApiKeySecret(key, apiKey)
+}
+
+func (s *service) AnnotateApiKey(id uuid.UUID, d *dto.ApiKeyAnnotate) (*dto.ApiKeyInfo, error) {
+	ctx := context.Background()
+
+	query := `
+		UPDATE api_keys
+		SET
+			comments = COALESCE(comments, '{}') || $2::TEXT[],
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	` + apiKeyReturning
+
+	apiKey, err := scanApiKey(s.db.Pool().QueryRow(ctx, query, id, d.Comments))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, network.NewNotFoundError("api key not found", err)
+		}
+		return nil, err
+	}
+
+	s.authCache.ClearApiKeys(apiKey.KeyHash)
+	return dto.NewApiKeyInfo(apiKey)
+}
+
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func (s *service) insertApiKey(
+	ctx context.Context,
+	q querier,
+	key string,
+	version int,
+	permissions []model.Permission,
+	comments []string,
+	expiresAt *time.Time,
+	allowedOrigins []string,
+) (*model.ApiKey, error) {
+	query := `
+		INSERT INTO api_keys (
+			key_hash,
+			key_prefix,
+			permissions,
+			comments,
+			version,
+			expires_at,
+			allowed_origins
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	` + apiKeyReturning
+
+	apiKey, err := scanApiKey(q.QueryRow(
+		ctx,
+		query,
+		utils.HashToken(key),
+		model.ApiKeyPrefix(key),
+		permissions,
+		comments,
+		version,
+		expiresAt,
+		allowedOrigins,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey.Key = key
+	return apiKey, nil
+}
+
+func generateKey() (string, error) {
+	return utility.GenerateRandomString(24)
+}
+
+func newApiKeySecret(key string, apiKey *model.ApiKey) (*dto.ApiKeySecret, error) {
+	info, err := dto.NewApiKeyInfo(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewApiKeySecret(key, info), nil
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit/dto"
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	service Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller: network.NewController("/admin/audit-events", authProvider, authorizeProvider),
+		service:    service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(
+		common.KeyPermission(authModel.AdminPermission),
+		c.Authentication(),
+		common.RejectImpersonation(),
+		c.Authorization(string(userModel.PermissionAuditRead)),
+	)
+	group.GET("", c.queryEventsHandler)
+}
+
+func (c *controller) queryEventsHandler(ctx *gin.Context) {
+	query, err := network.ReqQuery[dto.AuditQuery](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	page, err := c.service.QueryEvents(query)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", page)
+}
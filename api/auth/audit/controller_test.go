@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func mockProviders() (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider) {
+	authProvider := new(network.MockAuthenticationProvider)
+	authProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+	authorizeProvider := new(network.MockAuthorizationProvider)
+	authorizeProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+	return authProvider, authorizeProvider
+}
+
+func TestAuditController_QueryBadOutcome(t *testing.T) {
+	service := new(MockService)
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/admin/audit-events?outcome=MAYBE", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "QueryEvents", mock.Anything)
+}
+
+func TestAuditController_QuerySuccess(t *testing.T) {
+	service := new(MockService)
+	cursor := int64(41)
+	service.On("QueryEvents", mock.MatchedBy(func(q *dto.AuditQuery) bool {
+		return q.Action == model.AuditActionSignIn && q.Limit == dto.DefaultAuditLimit && q.Cursor == 50
+	})).Return(dto.NewAuditEventPage([]*dto.AuditEventInfo{}, &cursor), nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/admin/audit-events?action=auth.signin&cursor=50", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"nextCursor":41`)
+	service.AssertExpectations(t)
+}
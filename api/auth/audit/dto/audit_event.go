@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/google/uuid"
+)
+
+type AuditEventInfo struct {
+	ID         int64              `json:"id" validate:"required"`
+	Action     model.AuditAction  `json:"action" validate:"required"`
+	Outcome    model.AuditOutcome `json:"outcome" validate:"required"`
+	ActorID    *uuid.UUID         `json:"actorId,omitempty"`
+	TargetType string             `json:"targetType,omitempty"`
+	TargetID   string             `json:"targetId,omitempty"`
+	IP         string             `json:"ip,omitempty"`
+	UserAgent  string             `json:"userAgent,omitempty"`
+	Metadata   map[string]string  `json:"metadata,omitempty"`
+	CreatedAt  time.Time          `json:"createdAt" validate:"required"`
+}
+
+func NewAuditEventInfo(event *model.AuditEvent) *AuditEventInfo {
+	return &AuditEventInfo{
+		ID:         event.ID,
+		Action:     event.Action,
+		Outcome:    event.Outcome,
+		ActorID:    event.ActorID,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		IP:         event.IP,
+		UserAgent:  event.UserAgent,
+		Metadata:   event.Metadata,
+		CreatedAt:  event.CreatedAt,
+	}
+}
+
+// AuditEventPage has no nextCursor on the last page
+type AuditEventPage struct {
+	Events     []*AuditEventInfo `json:"events" validate:"required"`
+	NextCursor *int64            `json:"nextCursor,omitempty"`
+}
+
+func NewAuditEventPage(events []*AuditEventInfo, nextCursor *int64) *AuditEventPage {
+	return &AuditEventPage{
+		Events:     events,
+		NextCursor: nextCursor,
+	}
+}
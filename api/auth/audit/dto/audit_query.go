@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/google/uuid"
+)
+
+const DefaultAuditLimit = 50
+
+// AuditQuery filters the audit events, the events come newest first and the cursor
+// is the nextCursor of the previous page
+type AuditQuery struct {
+	Action     model.AuditAction  `form:"action" validate:"omitempty,max=100"`
+	Outcome    model.AuditOutcome `form:"outcome" validate:"omitempty,oneof=SUCCESS FAILURE DENIED CHALLENGED"`
+	ActorId    string             `form:"actorId" validate:"omitempty,uuid"`
+	TargetType string             `form:"targetType" validate:"omitempty,max=50"`
+	TargetId   string             `form:"targetId" validate:"omitempty,max=200"`
+	From       time.Time          `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time          `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor     int64              `form:"cursor" validate:"omitempty,min=1"`
+	Limit      int64              `form:"limit" validate:"omitempty,min=1,max=100"`
+	ActorID    *uuid.UUID         `form:"-" validate:"-"`
+}
+
+func (d *AuditQuery) GetValue() *AuditQuery {
+	if id, err := uuid.Parse(d.ActorId); err == nil {
+		d.ActorID = &id
+	}
+	if d.Limit == 0 {
+		d.Limit = DefaultAuditLimit
+	}
+	return d
+}
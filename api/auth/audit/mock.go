@@ -0,0 +1,27 @@
+package audit
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) Record(event *model.AuditEvent) {
+	m.Called(event)
+}
+
+func (m *MockService) QueryEvents(d *dto.AuditQuery) (*dto.AuditEventPage, error) {
+	args := m.Called(d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AuditEventPage), args.Error(1)
+}
+
+func (m *MockService) Close() {
+	m.Called()
+}
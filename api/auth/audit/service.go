@@ -0,0 +1,258 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	queueSize     = 1024
+	batchSize     = 100
+	flushInterval = time.Second
+)
+
+// Service records the audit events off the request path, Record only queues the event and
+// a single writer stores the queued events in batches. Events are dropped, with a log,
+// when the queue is full so that a slow database never blocks the requests.
+type Service interface {
+	Record(event *model.AuditEvent)
+	QueryEvents(d *dto.AuditQuery) (*dto.AuditEventPage, error)
+	Close()
+}
+
+type service struct {
+	db     postgres.Database
+	write  func(events []*model.AuditEvent) error
+	events chan *model.AuditEvent
+	done   chan struct{}
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewService(db postgres.Database) Service {
+	s := &service{db: db}
+	s.write = s.insertEvents
+	s.start()
+	return s
+}
+
+func (s *service) start() {
+	s.events = make(chan *model.AuditEvent, queueSize)
+	s.done = make(chan struct{})
+	go s.run()
+}
+
+func (s *service) Record(event *model.AuditEvent) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		log.Printf("audit event %s %s dropped: queue is full", event.Action, event.Outcome)
+	}
+}
+
+// Close stops taking events and returns once the queued events are written
+func (s *service) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.events)
+	s.mu.Unlock()
+
+	<-s.done
+}
+
+func (s *service) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*model.AuditEvent, 0, batchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.write(batch); err != nil {
+			log.Printf("%d audit events could not be written: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case event, ok := <-s.events:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (s *service) insertEvents(events []*model.AuditEvent) error {
+	ctx := context.Background()
+
+	rows := make([][]any, len(events))
+	for i, event := range events {
+		metadata := event.Metadata
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		rows[i] = []any{
+			event.Action,
+			event.Outcome,
+			event.ActorID,
+			event.TargetType,
+			event.TargetID,
+			event.IP,
+			event.UserAgent,
+			metadata,
+			event.CreatedAt,
+		}
+	}
+
+	_, err := s.db.Pool().CopyFrom(
+		ctx,
+		pgx.Identifier{model.AuditEventTableName},
+		[]string{
+			"action",
+			"outcome",
+			"actor_id",
+			"target_type",
+			"target_id",
+			"ip",
+			"user_agent",
+			"metadata",
+			"created_at",
+		},
+		pgx.CopyFromRows(rows),
+	)
+	return err
+}
+
+func (s *service) QueryEvents(d *dto.AuditQuery) (*dto.AuditEventPage, error) {
+	ctx := context.Background()
+
+	var conditions []string
+	var args []any
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if d.Action != "" {
+		where("action = $%d", d.Action)
+	}
+	if d.Outcome != "" {
+		where("outcome = $%d", d.Outcome)
+	}
+	if d.ActorID != nil {
+		where("actor_id = $%d", *d.ActorID)
+	}
+	if d.TargetType != "" {
+		where("target_type = $%d", d.TargetType)
+	}
+	if d.TargetId != "" {
+		where("target_id = $%d", d.TargetId)
+	}
+	if !d.From.IsZero() {
+		where("created_at >= $%d", d.From)
+	}
+	if !d.To.IsZero() {
+		where("created_at < $%d", d.To)
+	}
+	if d.Cursor > 0 {
+		where("id < $%d", d.Cursor)
+	}
+
+	query := `
+		SELECT
+			id,
+			action,
+			outcome,
+			actor_id,
+			target_type,
+			target_id,
+			ip,
+			user_agent,
+			metadata,
+			created_at
+		FROM audit_events
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// one more row tells whether there is a next page
+	args = append(args, d.Limit+1)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	rows, err := s.db.Pool().Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*dto.AuditEventInfo{}
+
+	for rows.Next() {
+		var event model.AuditEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.Action,
+			&event.Outcome,
+			&event.ActorID,
+			&event.TargetType,
+			&event.TargetID,
+			&event.IP,
+			&event.UserAgent,
+			&event.Metadata,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, dto.NewAuditEventInfo(&event))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var nextCursor *int64
+	if int64(len(events)) > d.Limit {
+		events = events[:d.Limit]
+		nextCursor = &events[len(events)-1].ID
+	}
+
+	return dto.NewAuditEventPage(events, nextCursor), nil
+}
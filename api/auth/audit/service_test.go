@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestService(write func(events []*model.AuditEvent) error) *service {
+	s := &service{write: write}
+	s.start()
+	return s
+}
+
+func TestAuditService_CloseFlushesQueuedEvents(t *testing.T) {
+	var mu sync.Mutex
+	var written []*model.AuditEvent
+	s := newTestService(func(events []*model.AuditEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		written = append(written, events...)
+		return nil
+	})
+
+	for i := 0; i < batchSize+10; i++ {
+		s.Record(&model.AuditEvent{Action: model.AuditActionSignIn, Outcome: model.AuditOutcomeSuccess})
+	}
+	s.Close()
+
+	assert.Len(t, written, batchSize+10)
+	for _, event := range written {
+		assert.False(t, event.CreatedAt.IsZero())
+	}
+}
+
+func TestAuditService_RecordAfterCloseIsIgnored(t *testing.T) {
+	calls := 0
+	s := newTestService(func(events []*model.AuditEvent) error {
+		calls++
+		return nil
+	})
+	s.Close()
+	s.Close()
+
+	s.Record(&model.AuditEvent{Action: model.AuditActionSignOut, Outcome: model.AuditOutcomeSuccess})
+	assert.Equal(t, 0, calls)
+}
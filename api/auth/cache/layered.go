@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/afteracademy/goserve/v2/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// localMaxEntries bounds the in-process layer, it is cleared when full
+const localMaxEntries = 10000
+
+type localEntry[T any] struct {
+	value     *T
+	expiresAt time.Time
+}
+
+// localCache is the in-process layer, deletes only reach the instance they run on
+// so its ttl is the time other instances may serve a stale value
+type localCache[T any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]localEntry[T]
+}
+
+func newLocalCache[T any](ttl time.Duration) *localCache[T] {
+	return &localCache[T]{
+		ttl:     ttl,
+		entries: make(map[string]localEntry[T]),
+	}
+}
+
+func (c *localCache[T]) get(key string, now time.Time) (*T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	// callers may modify the value they get
+	value := *entry.value
+	return &value, true
+}
+
+func (c *localCache[T]) set(key string, value *T, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= localMaxEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= localMaxEntries {
+			c.entries = make(map[string]localEntry[T])
+		}
+	}
+
+	copied := *value
+	c.entries[key] = localEntry[T]{value: &copied, expiresAt: now.Add(c.ttl)}
+}
+
+func (c *localCache[T]) delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+}
+
+// layered keeps json values in redis with an optional in-process layer in front,
+// cache errors are only logged since the database remains the source of truth
+type layered[T any] struct {
+	context context.Context
+	store   redis.Store
+	prefix  string
+	ttl     time.Duration
+	local   *localCache[T]
+}
+
+func newLayered[T any](store redis.Store, prefix string, ttl time.Duration, localTTL time.Duration) *layered[T] {
+	c := &layered[T]{
+		context: context.Background(),
+		store:   store,
+		prefix:  prefix + "_",
+		ttl:     ttl,
+	}
+	if localTTL > 0 {
+		c.local = newLocalCache[T](localTTL)
+	}
+	return c
+}
+
+func (c *layered[T]) enabled() bool {
+	return c.ttl > 0
+}
+
+func (c *layered[T]) get(key string) (*T, bool) {
+	if !c.enabled() {
+		return nil, false
+	}
+
+	key = c.prefix + key
+	now := time.Now()
+
+	if c.local != nil {
+		if value, ok := c.local.get(key, now); ok {
+			return value, true
+		}
+	}
+
+	data, err := c.store.GetInstance().Get(c.context, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, goredis.Nil) {
+			log.Printf("auth cache read failed for %s: %v", key, err)
+		}
+		return nil, false
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		log.Printf("auth cache entry %s could not be decoded: %v", key, err)
+		return nil, false
+	}
+
+	if c.local != nil {
+		c.local.set(key, &value, now)
+	}
+	return &value, true
+}
+
+func (c *layered[T]) set(key string, value *T) {
+	if !c.enabled() {
+		return
+	}
+
+	key = c.prefix + key
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("auth cache entry %s could not be encoded: %v", key, err)
+		return
+	}
+
+	err = c.store.GetInstance().Set(c.context, key, data, c.ttl).Err()
+	if err != nil {
+		log.Printf("auth cache write failed for %s: %v", key, err)
+		return
+	}
+
+	if c.local != nil {
+		c.local.set(key, value, time.Now())
+	}
+}
+
+func (c *layered[T]) delete(keys ...string) {
+	if !c.enabled() || len(keys) == 0 {
+		return
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+
+	if c.local != nil {
+		c.local.delete(prefixed...)
+	}
+
+	err := c.store.GetInstance().Del(c.context, prefixed...).Err()
+	if err != nil {
+		log.Printf("auth cache invalidation failed for %v: %v", prefixed, err)
+	}
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalCache_Expiry(t *testing.T) {
+	c := newLocalCache[model.ApiKey](5 * time.Second)
+	now := time.Now()
+	apiKey := &model.ApiKey{ID: uuid.New(), KeyHash: "hash"}
+
+	c.set("hash", apiKey, now)
+
+	cached, ok := c.get("hash", now.Add(4*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, apiKey.ID, cached.ID)
+
+	_, ok = c.get("hash", now.Add(5*time.Second))
+	assert.False(t, ok)
+}
+
+func TestLocalCache_ReturnsCopies(t *testing.T) {
+	c := newLocalCache[model.Keystore](time.Minute)
+	now := time.Now()
+	keystore := &model.Keystore{ID: uuid.New(), LastUsedAt: now}
+
+	c.set("key", keystore, now)
+	keystore.LastUsedAt = now.Add(time.Hour)
+
+	cached, ok := c.get("key", now)
+	assert.True(t, ok)
+	assert.Equal(t, now, cached.LastUsedAt)
+
+	cached.LastUsedAt = now.Add(time.Hour)
+	again, _ := c.get("key", now)
+	assert.Equal(t, now, again.LastUsedAt)
+}
+
+func TestLocalCache_Delete(t *testing.T) {
+	c := newLocalCache[model.ApiKey](time.Minute)
+	now := time.Now()
+
+	c.set("a", &model.ApiKey{}, now)
+	c.set("b", &model.ApiKey{}, now)
+	c.delete("a", "b")
+
+	_, ok := c.get("a", now)
+	assert.False(t, ok)
+	_, ok = c.get("b", now)
+	assert.False(t, ok)
+}
+
+func TestLayered_DisabledWithoutTTL(t *testing.T) {
+	c := newLayered[model.ApiKey](nil, "test", 0, time.Minute)
+
+	c.set("hash", &model.ApiKey{KeyHash: "hash"})
+	_, ok := c.get("hash")
+	assert.False(t, ok)
+	c.delete("hash")
+}
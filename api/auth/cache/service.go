@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve/v2/redis"
+	"github.com/google/uuid"
+)
+
+// Service caches the api key, user, keystore and personal access token lookups done by the auth middlewares
+// on every request, the owners of the data clear the entries when it changes
+type Service interface {
+	GetApiKey(keyHash string) (*model.ApiKey, bool)
+	SetApiKey(apiKey *model.ApiKey)
+	ClearApiKeys(keyHashes ...string)
+	GetUser(id uuid.UUID) (*userModel.User, bool)
+	SetUser(user *userModel.User)
+	ClearUser(id uuid.UUID)
+	GetKeystore(userId uuid.UUID, primaryKey string) (*model.Keystore, bool)
+	SetKeystore(keystore *model.Keystore)
+	ClearKeystores(userId uuid.UUID, primaryKeys ...string)
+	GetAccessToken(tokenHash string) (*model.AccessToken, bool)
+	SetAccessToken(accessToken *model.AccessToken)
+	ClearAccessTokens(tokenHashes ...string)
+}
+
+type service struct {
+	apiKeys      *layered[model.ApiKey]
+	users        *layered[userModel.User]
+	keystores    *layered[model.Keystore]
+	accessTokens *layered[model.AccessToken]
+}
+
+func NewService(env *config.Env, store redis.Store) Service {
+	ttl := time.Duration(env.AuthCacheTTLSec) * time.Second
+	localTTL := time.Duration(env.AuthCacheLocalTTLSec) * time.Second
+
+	return &service{
+		apiKeys:      newLayered[model.ApiKey](store, "auth_cache_apikey", ttl, localTTL),
+		users:        newLayered[userModel.User](store, "auth_cache_user", ttl, localTTL),
+		keystores:    newLayered[model.Keystore](store, "auth_cache_keystore", ttl, localTTL),
+		accessTokens: newLayered[model.AccessToken](store, "auth_cache_accesstoken", ttl, localTTL),
+	}
+}
+
+func (s *service) GetApiKey(keyHash string) (*model.ApiKey, bool) {
+	return s.apiKeys.get(keyHash)
+}
+
+func (s *service) SetApiKey(apiKey *model.ApiKey) {
+	s.apiKeys.set(apiKey.KeyHash, apiKey)
+}
+
+func (s *service) ClearApiKeys(keyHashes ...string) {
+	s.apiKeys.delete(keyHashes...)
+}
+
+func (s *service) GetUser(id uuid.UUID) (*userModel.User, bool) {
+	return s.users.get(id.String())
+}
+
+func (s *service) SetUser(user *userModel.User) {
+	s.users.set(user.ID.String(), user)
+}
+
+func (s *service) ClearUser(id uuid.UUID) {
+	s.users.delete(id.String())
+}
+
+func (s *service) GetKeystore(userId uuid.UUID, primaryKey string) (*model.Keystore, bool) {
+	return s.keystores.get(keystoreKey(userId, primaryKey))
+}
+
+func (s *service) SetKeystore(keystore *model.Keystore) {
+	s.keystores.set(keystoreKey(keystore.UserID, keystore.PrimaryKey), keystore)
+}
+
+func (s *service) ClearKeystores(userId uuid.UUID, primaryKeys ...string) {
+	keys := make([]string, len(primaryKeys))
+	for i, primaryKey := range primaryKeys {
+		keys[i] = keystoreKey(userId, primaryKey)
+	}
+	s.keystores.delete(keys...)
+}
+
+func (s *service) GetAccessToken(tokenHash string) (*model.AccessToken, bool) {
+	return s.accessTokens.get(tokenHash)
+}
+
+func (s *service) SetAccessToken(accessToken *model.AccessToken) {
+	s.accessTokens.set(accessToken.TokenHash, accessToken)
+}
+
+func (s *service) ClearAccessTokens(tokenHashes ...string) {
+	s.accessTokens.delete(tokenHashes...)
+}
+
+func keystoreKey(userId uuid.UUID, primaryKey string) string {
+	return userId.String() + "_" + primaryKey
+}
@@ -1,7 +1,12 @@
 package auth
 
 import (
+	"errors"
+
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/lockout"
+	mfaDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
 	"github.com/afteracademy/goserve-example-api-server-postgres/common"
 	"github.com/afteracademy/goserve-example-api-server-postgres/utils"
 	"github.com/afteracademy/goserve/v2/network"
@@ -12,25 +17,30 @@ type controller struct {
 	network.Controller
 	common.ContextPayload
 	service Service
+	cookies common.TokenCookies
 }
 
 func NewController(
 	authProvider network.AuthenticationProvider,
 	authorizeProvider network.AuthorizationProvider,
 	service Service,
+	cookies common.TokenCookies,
 ) network.Controller {
 	return &controller{
 		Controller:     network.NewController("/auth", authProvider, authorizeProvider),
 		ContextPayload: common.NewContextPayload(),
 		service:        service,
+		cookies:        cookies,
 	}
 }
 
 func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(common.KeyPermission(model.GeneralPermission))
 	group.POST("/signup/basic", c.signUpBasicHandler)
 	group.POST("/signin/basic", c.signInBasicHandler)
+	group.POST("/signin/mfa", c.signInMfaHandler)
 	group.POST("/token/refresh", c.tokenRefreshHandler)
-	group.DELETE("/signout", c.Authentication(), c.signOutBasic)
+	group.DELETE("/signout", c.Authentication(), common.RejectImpersonation(), c.signOutBasic)
 }
 
 func (c *controller) signUpBasicHandler(ctx *gin.Context) {
@@ -40,13 +50,13 @@ func (c *controller) signUpBasicHandler(ctx *gin.Context) {
 		return
 	}
 
-	data, err := c.service.SignUpBasic(body)
+	data, err := c.service.SignUpBasic(body, dto.NewClientInfo(ctx.Request.UserAgent(), ctx.ClientIP()))
 	if err != nil {
 		network.SendMixedError(ctx, err)
 		return
 	}
 
-	network.SendSuccessDataResponse(ctx, "success", data)
+	common.SendUserAuth(ctx, c.cookies, data)
 }
 
 func (c *controller) signInBasicHandler(ctx *gin.Context) {
@@ -56,28 +66,63 @@ func (c *controller) signInBasicHandler(ctx *gin.Context) {
 		return
 	}
 
-	dto, err := c.service.SignInBasic(body)
+	dto, challenge, err := c.service.SignInBasic(body, dto.NewClientInfo(ctx.Request.UserAgent(), ctx.ClientIP()))
 	if err != nil {
+		var lockedErr *lockout.LockedError
+		if errors.As(err, &lockedErr) {
+			lockout.SendLockedError(ctx, lockedErr)
+			return
+		}
 		network.SendMixedError(ctx, err)
 		return
 	}
 
-	network.SendSuccessDataResponse(ctx, "success", dto)
+	if challenge != nil {
+		network.SendSuccessDataResponse(ctx, "mfa required", challenge)
+		return
+	}
+
+	common.SendUserAuth(ctx, c.cookies, dto)
+}
+
+func (c *controller) signInMfaHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[mfaDto.MfaVerify](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	dto, err := c.service.SignInMfa(body, dto.NewClientInfo(ctx.Request.UserAgent(), ctx.ClientIP()))
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	common.SendUserAuth(ctx, c.cookies, dto)
 }
 
 func (c *controller) signOutBasic(ctx *gin.Context) {
 	keystore := c.MustGetKeystore(ctx)
 
-	err := c.service.SignOut(keystore)
+	err := c.service.SignOut(keystore, dto.NewClientInfo(ctx.Request.UserAgent(), ctx.ClientIP()))
 	if err != nil {
 		network.SendInternalServerError(ctx, "something went wrong", err)
 		return
 	}
 
+	if c.cookies.AccessToken(ctx) != "" {
+		c.cookies.Clear(ctx)
+	}
+
 	network.SendSuccessMsgResponse(ctx, "signout success")
 }
 
 func (c *controller) tokenRefreshHandler(ctx *gin.Context) {
+	if c.cookies.Requested(ctx) {
+		c.cookieTokenRefresh(ctx)
+		return
+	}
+
 	body, err := network.ReqBody[dto.TokenRefresh](ctx)
 	if err != nil {
 		network.SendBadRequestError(ctx, err.Error(), err)
@@ -87,7 +132,7 @@ func (c *controller) tokenRefreshHandler(ctx *gin.Context) {
 	authHeader := ctx.GetHeader(network.AuthorizationHeader)
 	accessToken := utils.ExtractBearerToken(authHeader)
 
-	dto, err := c.service.RenewToken(body, accessToken)
+	dto, err := c.service.RenewToken(body, accessToken, dto.NewClientInfo(ctx.Request.UserAgent(), ctx.ClientIP()))
 	if err != nil {
 		network.SendMixedError(ctx, err)
 		return
@@ -95,3 +140,32 @@ func (c *controller) tokenRefreshHandler(ctx *gin.Context) {
 
 	network.SendSuccessDataResponse(ctx, "success", dto)
 }
+
+// cookieTokenRefresh renews the tokens kept in the cookies, the cookies are sent by the browser
+// on their own so the request has to carry the csrf token as well
+func (c *controller) cookieTokenRefresh(ctx *gin.Context) {
+	if !c.cookies.VerifyCsrf(ctx) {
+		network.SendForbiddenError(ctx, "permission denied: invalid csrf token", nil)
+		return
+	}
+
+	refreshToken := c.cookies.RefreshToken(ctx)
+	if refreshToken == "" {
+		network.SendUnauthorizedError(ctx, "permission denied: missing refresh token", nil)
+		return
+	}
+
+	body := &dto.TokenRefresh{RefreshToken: refreshToken}
+	tokens, err := c.service.RenewToken(body, c.cookies.AccessToken(ctx), dto.NewClientInfo(ctx.Request.UserAgent(), ctx.ClientIP()))
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	if err := c.cookies.SetTokens(ctx, tokens); err != nil {
+		network.SendInternalServerError(ctx, "something went wrong", err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "token refreshed")
+}
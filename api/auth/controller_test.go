@@ -2,17 +2,27 @@ package auth
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/lockout"
+	mfaDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
 	userDto "github.com/afteracademy/goserve-example-api-server-postgres/api/user/dto"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
 	"github.com/afteracademy/goserve/v2/network"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+// bearerCookies has the cookie mode disabled
+var bearerCookies = common.NewTokenCookies(&config.Env{})
+
 func TestAuthController_SignupBadRequest(t *testing.T) {
 	mockAuthProvider := new(network.MockAuthenticationProvider)
 	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
@@ -26,9 +36,9 @@ func TestAuthController_SignupBadRequest(t *testing.T) {
 
 	authService := new(MockService)
 
-	c := NewController(mockAuthProvider, mockAuthzProvider, authService)
+	c := NewController(mockAuthProvider, mockAuthzProvider, authService, bearerCookies)
 
-	rr := network.MockTestController(t, "POST", "/auth/signup/basic", "{}", c)
+	rr := network.MockTestController(t, "POST", "/auth/signup/basic", "{}", common.MockApiKey(c))
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
 	assert.Contains(t, rr.Body.String(), `"message":"email is required, password is required, name is required"`)
 }
@@ -72,11 +82,145 @@ func TestAuthController_SignupSuccess(t *testing.T) {
 	}
 
 	authService := new(MockService)
-	authService.On("SignUpBasic", singUpDto).Return(authDto, nil)
+	authService.On("SignUpBasic", singUpDto, mock.Anything).Return(authDto, nil)
 
-	c := NewController(mockAuthProvider, mockAuthzProvider, authService)
+	c := NewController(mockAuthProvider, mockAuthzProvider, authService, bearerCookies)
 
-	rr := network.MockTestController(t, "POST", "/auth/signup/basic", body, c)
+	rr := network.MockTestController(t, "POST", "/auth/signup/basic", body, common.MockApiKey(c))
 	assert.Equal(t, http.StatusOK, rr.Code)
 	assert.Contains(t, rr.Body.String(), `"message":"success"`)
 }
+
+func TestAuthController_SigninLocked(t *testing.T) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	mockAuthzProvider := new(network.MockAuthorizationProvider)
+
+	body := `{"email":"test@abc.com","password":"123456"}`
+
+	signInDto := &dto.SignInBasic{
+		Email:    "test@abc.com",
+		Password: "123456",
+	}
+
+	authService := new(MockService)
+	authService.On("SignInBasic", signInDto, mock.Anything).
+		Return(nil, nil, &lockout.LockedError{RetryAfter: 90 * time.Second})
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, authService, bearerCookies)
+
+	rr := network.MockTestController(t, "POST", "/auth/signin/basic", body, common.MockApiKey(c))
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "90", rr.Header().Get("Retry-After"))
+	assert.Contains(t, rr.Body.String(), `"message":"too many failed attempts, try again later"`)
+}
+
+func TestAuthController_SigninInvalidCredentials(t *testing.T) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	mockAuthzProvider := new(network.MockAuthorizationProvider)
+
+	body := `{"email":"test@abc.com","password":"123456"}`
+
+	authService := new(MockService)
+	authService.On("SignInBasic", mock.Anything, mock.Anything).
+		Return(nil, nil, network.NewUnauthorizedError("invalid credentials", nil))
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, authService, bearerCookies)
+
+	rr := network.MockTestController(t, "POST", "/auth/signin/basic", body, common.MockApiKey(c))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"invalid credentials"`)
+}
+
+func TestAuthController_SigninMfaRequired(t *testing.T) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	mockAuthzProvider := new(network.MockAuthorizationProvider)
+
+	body := `{"email":"test@abc.com","password":"123456"}`
+
+	challenge := mfaDto.NewMfaChallenge("challenge-token", time.Now().Add(5*time.Minute))
+
+	authService := new(MockService)
+	authService.On("SignInBasic", mock.Anything, mock.Anything).Return(nil, challenge, nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, authService, bearerCookies)
+
+	rr := network.MockTestController(t, "POST", "/auth/signin/basic", body, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"mfa required"`)
+	assert.Contains(t, rr.Body.String(), `"challengeToken":"challenge-token"`)
+	assert.NotContains(t, rr.Body.String(), `"tokens"`)
+}
+
+func TestAuthController_SigninMfaBadRequest(t *testing.T) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	mockAuthzProvider := new(network.MockAuthorizationProvider)
+
+	authService := new(MockService)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, authService, bearerCookies)
+
+	rr := network.MockTestController(t, "POST", "/auth/signin/mfa", `{"challengeToken":"challenge-token"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"code is required"`)
+}
+
+func serveCookieTokenRefresh(t *testing.T, authService *MockService, csrfToken string) *httptest.ResponseRecorder {
+	cookies := common.NewTokenCookies(&config.Env{AuthCookieEnabled: true, RefreshTokenValiditySec: 3600})
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), authService, cookies)
+
+	headers := map[string]string{
+		common.TokenTransportHeader: common.TokenTransportCookie,
+		"Cookie":                    "access_token=access; refresh_token=refresh; csrf_token=csrf",
+	}
+	if csrfToken != "" {
+		headers[common.CsrfTokenHeader] = csrfToken
+	}
+
+	return network.MockTestHandler(
+		t, "POST", "/auth/token/refresh", "/auth/token/refresh", "",
+		c.(*controller).tokenRefreshHandler, headers,
+	)
+}
+
+func TestAuthController_CookieTokenRefreshInvalidCsrf(t *testing.T) {
+	authService := new(MockService)
+
+	rr := serveCookieTokenRefresh(t, authService, "other")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	authService.AssertNotCalled(t, "RenewToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthController_CookieTokenRefreshSuccess(t *testing.T) {
+	authService := new(MockService)
+	authService.On("RenewToken", &dto.TokenRefresh{RefreshToken: "refresh"}, "access", mock.Anything).
+		Return(dto.NewTokens("new-access", "new-refresh"), nil)
+
+	rr := serveCookieTokenRefresh(t, authService, "csrf")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "new-access")
+
+	values := map[string]string{}
+	for _, cookie := range rr.Result().Cookies() {
+		values[cookie.Name] = cookie.Value
+	}
+	assert.Equal(t, "new-access", values[common.AccessTokenCookie])
+	assert.Equal(t, "new-refresh", values[common.RefreshTokenCookie])
+	assert.NotEqual(t, "csrf", values[common.CsrfTokenCookie])
+	authService.AssertExpectations(t)
+}
@@ -5,9 +5,10 @@ import (
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
 )
 
+// UserAuth has no tokens when they are sent as cookies
 type UserAuth struct {
 	User   *dto.UserPrivate `json:"user" validate:"required"`
-	Tokens *Tokens          `json:"tokens" validate:"required"`
+	Tokens *Tokens          `json:"tokens,omitempty"`
 }
 
 func NewUserAuth(user *model.User, tokens *Tokens) *UserAuth {
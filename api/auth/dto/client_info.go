@@ -0,0 +1,14 @@
+package dto
+
+// ClientInfo describes the device behind a request, it is stored with the keystore
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+func NewClientInfo(userAgent string, ip string) *ClientInfo {
+	return &ClientInfo{
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+}
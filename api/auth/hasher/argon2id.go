@@ -0,0 +1,124 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	defaultArgon2Memory      = 64 * 1024
+	defaultArgon2Iterations  = 3
+	defaultArgon2Parallelism = 2
+	argon2SaltLength         = 16
+	argon2KeyLength          = 32
+)
+
+var errInvalidArgon2Hash = errors.New("invalid argon2id hash")
+
+type argon2idParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// argon2id encodes the hashes in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+type argon2id struct {
+	params argon2idParams
+}
+
+func newArgon2id(memory uint32, iterations uint32, parallelism uint8) *argon2id {
+	if memory == 0 {
+		memory = defaultArgon2Memory
+	}
+	if iterations == 0 {
+		iterations = defaultArgon2Iterations
+	}
+	if parallelism == 0 {
+		parallelism = defaultArgon2Parallelism
+	}
+	return &argon2id{params: argon2idParams{memory, iterations, parallelism}}
+}
+
+func (a *argon2id) name() string {
+	return AlgorithmArgon2id
+}
+
+func (a *argon2id) owns(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (a *argon2id) hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	p := a.params
+	key := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, argon2KeyLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		p.memory,
+		p.iterations,
+		p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (a *argon2id) verify(password string, encoded string) (bool, error) {
+	_, p, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (a *argon2id) outdated(encoded string) bool {
+	version, p, _, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return version != argon2.Version || p != a.params || len(key) != argon2KeyLength
+}
+
+func decodeArgon2id(encoded string) (int, argon2idParams, []byte, []byte, error) {
+	var p argon2idParams
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return 0, p, nil, nil, errInvalidArgon2Hash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return 0, p, nil, nil, errInvalidArgon2Hash
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism)
+	if err != nil || p.iterations == 0 || p.parallelism == 0 {
+		return 0, p, nil, nil, errInvalidArgon2Hash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return 0, p, nil, nil, errInvalidArgon2Hash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return 0, p, nil, nil, errInvalidArgon2Hash
+	}
+
+	return version, p, salt, key, nil
+}
@@ -0,0 +1,54 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const defaultBcryptCost = 12
+
+// bcryptHash keeps the modular crypt format of bcrypt, $2a$12$<salt and key>,
+// which is also the format of the hashes stored before argon2id
+type bcryptHash struct {
+	cost int
+}
+
+func newBcrypt(cost int) *bcryptHash {
+	if cost == 0 {
+		cost = defaultBcryptCost
+	}
+	return &bcryptHash{cost: cost}
+}
+
+func (b *bcryptHash) name() string {
+	return AlgorithmBcrypt
+}
+
+func (b *bcryptHash) owns(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+func (b *bcryptHash) hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func (b *bcryptHash) verify(password string, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (b *bcryptHash) outdated(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != b.cost
+}
@@ -0,0 +1,78 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var ErrUnknownHash = errors.New("password hash format is not supported")
+
+// Hasher hashes the new passwords with the configured algorithm and verifies the hashes of
+// every supported algorithm, so that the stored hashes can be upgraded as the users sign in
+type Hasher interface {
+	Hash(password string) (string, error)
+	// Verify tells whether the password matches, rehash is set for a matching hash made
+	// with another algorithm or with outdated parameters
+	Verify(password string, encoded string) (ok bool, rehash bool, err error)
+}
+
+// algorithm is a hashing scheme with its encoded hash format
+type algorithm interface {
+	name() string
+	// owns tells whether the encoded hash was made by this algorithm
+	owns(encoded string) bool
+	hash(password string) (string, error)
+	verify(password string, encoded string) (bool, error)
+	// outdated tells whether the encoded hash was made with other parameters than the current
+	outdated(encoded string) bool
+}
+
+type hasher struct {
+	current    algorithm
+	algorithms []algorithm
+}
+
+// NewHasher hashes with argon2id unless bcrypt is configured, the parameters left empty get the defaults
+func NewHasher(env *config.Env) Hasher {
+	argon := newArgon2id(env.Argon2MemoryKiB, env.Argon2Iterations, env.Argon2Parallelism)
+	bcrypt := newBcrypt(env.BcryptCost)
+
+	current := algorithm(argon)
+	if strings.EqualFold(env.PasswordHashAlgorithm, AlgorithmBcrypt) {
+		current = bcrypt
+	}
+
+	return &hasher{
+		current:    current,
+		algorithms: []algorithm{argon, bcrypt},
+	}
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	return h.current.hash(password)
+}
+
+func (h *hasher) Verify(password string, encoded string) (bool, bool, error) {
+	for _, a := range h.algorithms {
+		if !a.owns(encoded) {
+			continue
+		}
+
+		ok, err := a.verify(password, encoded)
+		if err != nil || !ok {
+			return false, false, err
+		}
+
+		rehash := a.name() != h.current.name() || a.outdated(encoded)
+		return true, rehash, nil
+	}
+
+	return false, false, ErrUnknownHash
+}
@@ -0,0 +1,81 @@
+package hasher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func testArgon2Env() *config.Env {
+	return &config.Env{Argon2MemoryKiB: 1024, Argon2Iterations: 1, Argon2Parallelism: 1, BcryptCost: 4}
+}
+
+func TestHasher_Argon2idRoundTrip(t *testing.T) {
+	h := NewHasher(testArgon2Env())
+
+	encoded, err := h.Hash("correct horse")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	ok, rehash, err := h.Verify("correct horse", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, rehash)
+
+	ok, rehash, err = h.Verify("wrong horse", encoded)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, rehash)
+}
+
+func TestHasher_RehashOtherAlgorithm(t *testing.T) {
+	env := testArgon2Env()
+	env.PasswordHashAlgorithm = AlgorithmBcrypt
+	old := NewHasher(env)
+
+	encoded, err := old.Hash("correct horse")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$2a$04$"))
+
+	ok, rehash, err := NewHasher(testArgon2Env()).Verify("correct horse", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+}
+
+func TestHasher_RehashOutdatedParameters(t *testing.T) {
+	encoded, err := NewHasher(testArgon2Env()).Hash("correct horse")
+	assert.NoError(t, err)
+
+	env := testArgon2Env()
+	env.Argon2Iterations = 2
+	ok, rehash, err := NewHasher(env).Verify("correct horse", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+
+	env = testArgon2Env()
+	env.PasswordHashAlgorithm = AlgorithmBcrypt
+	encoded, err = NewHasher(env).Hash("correct horse")
+	assert.NoError(t, err)
+
+	env.BcryptCost = 5
+	ok, rehash, err = NewHasher(env).Verify("correct horse", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+}
+
+func TestHasher_VerifyInvalidHash(t *testing.T) {
+	h := NewHasher(testArgon2Env())
+
+	ok, _, err := h.Verify("correct horse", "plain-text")
+	assert.ErrorIs(t, err, ErrUnknownHash)
+	assert.False(t, ok)
+
+	ok, _, err = h.Verify("correct horse", "$argon2id$v=19$m=1024,t=1,p=1$not-base64!$")
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,127 @@
+package hasher
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve/v2/network"
+)
+
+const (
+	defaultPasswordMinLength = 8
+	// lower, upper, digit and symbol are the classes
+	passwordMinClasses = 2
+	// inputs shorter than this are not looked for in the password
+	userInputMinLength = 3
+)
+
+// Policy rejects the passwords that are easy to guess before they are hashed
+type Policy interface {
+	// Check validates a new password, userInputs are the values like the email and
+	// name of the user that the password must not contain
+	Check(password string, userInputs ...string) error
+}
+
+type policy struct {
+	minLength int
+	breached  map[string]struct{}
+}
+
+// NewPolicy loads the breached passwords list, one password per line, an empty path disables the list
+func NewPolicy(env *config.Env) Policy {
+	breached, err := loadBreachedList(env.PasswordBreachedListPath)
+	if err != nil {
+		panic(err)
+	}
+	return NewPolicyFromList(int(env.PasswordMinLength), breached)
+}
+
+func NewPolicyFromList(minLength int, breached []string) Policy {
+	if minLength == 0 {
+		minLength = defaultPasswordMinLength
+	}
+
+	list := make(map[string]struct{}, len(breached))
+	for _, password := range breached {
+		list[strings.ToLower(password)] = struct{}{}
+	}
+
+	return &policy{minLength: minLength, breached: list}
+}
+
+func (p *policy) Check(password string, userInputs ...string) error {
+	if utf8.RuneCountInString(password) < p.minLength {
+		return weakPasswordError("it must have at least " + strconv.Itoa(p.minLength) + " characters")
+	}
+
+	if countClasses(password) < passwordMinClasses {
+		return weakPasswordError("it must mix letters with digits, symbols or letters of the other case")
+	}
+
+	lower := strings.ToLower(password)
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if local, _, found := strings.Cut(input, "@"); found {
+			input = local
+		}
+		if utf8.RuneCountInString(input) >= userInputMinLength && strings.Contains(lower, input) {
+			return weakPasswordError("it must not contain your email or name")
+		}
+	}
+
+	if _, ok := p.breached[lower]; ok {
+		return weakPasswordError("it is found in a list of breached passwords")
+	}
+
+	return nil
+}
+
+func weakPasswordError(reason string) error {
+	return network.NewBadRequestError("password is too weak, "+reason, nil)
+}
+
+func countClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+func loadBreachedList(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list = append(list, line)
+	}
+
+	return list, scanner.Err()
+}
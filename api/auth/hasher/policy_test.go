@@ -0,0 +1,26 @@
+package hasher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	p := NewPolicyFromList(0, []string{"Password123"})
+
+	assert.NoError(t, p.Check("blue-Ocean7", "user@abc.com", "test name"))
+
+	assert.Error(t, p.Check("blue7", "user@abc.com"), "short")
+	assert.Error(t, p.Check("blueoceanwaves"), "single class")
+	assert.Error(t, p.Check("PASSWORD123"), "breached without case")
+	assert.Error(t, p.Check("my-user-2024", "user@abc.com"), "email local part")
+	assert.Error(t, p.Check("Alice-2024!", "alice@abc.com", "Alice"), "name")
+}
+
+func TestPolicy_MinLength(t *testing.T) {
+	p := NewPolicyFromList(12, nil)
+
+	assert.Error(t, p.Check("blue-Ocean7"))
+	assert.NoError(t, p.Check("blue-Ocean7-waves"))
+}
@@ -0,0 +1,60 @@
+package impersonation
+
+import (
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	coredto "github.com/afteracademy/goserve/v2/dto"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/admin/impersonations", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(
+		common.KeyPermission(authModel.AdminPermission),
+		c.Authentication(),
+		common.RejectImpersonation(),
+		c.Authorization(string(userModel.PermissionUserImpersonate)),
+	)
+	group.POST("/id/:id", c.impersonateHandler)
+}
+
+func (c *controller) impersonateHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	actor := c.MustGetUser(ctx)
+	keystore := c.MustGetKeystore(ctx)
+
+	clientInfo := authDto.NewClientInfo(ctx.Request.UserAgent(), ctx.ClientIP())
+
+	token, err := c.service.Impersonate(actor, keystore, uuidParam.ID, clientInfo)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "impersonation started", token)
+}
@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"time"
+
+	userDto "github.com/afteracademy/goserve-example-api-server-postgres/api/user/dto"
+)
+
+// ImpersonationToken is an access token without a refresh token, a new one is requested once it expires
+type ImpersonationToken struct {
+	AccessToken string              `json:"accessToken" validate:"required"`
+	ExpiresAt   time.Time           `json:"expiresAt" validate:"required"`
+	User        *userDto.UserPublic `json:"user" validate:"required"`
+}
+
+func NewImpersonationToken(accessToken string, expiresAt time.Time, user *userDto.UserPublic) *ImpersonationToken {
+	return &ImpersonationToken{
+		AccessToken: accessToken,
+		ExpiresAt:   expiresAt,
+		User:        user,
+	}
+}
@@ -0,0 +1,24 @@
+package impersonation
+
+import (
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/impersonation/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) Impersonate(
+	actor *userModel.User, keystore *model.Keystore, userId uuid.UUID, clientInfo *authDto.ClientInfo,
+) (*dto.ImpersonationToken, error) {
+	args := m.Called(actor, keystore, userId, clientInfo)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ImpersonationToken), args.Error(1)
+}
@@ -0,0 +1,77 @@
+package impersonation
+
+import (
+	"slices"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit"
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/impersonation/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
+	userDto "github.com/afteracademy/goserve-example-api-server-postgres/api/user/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/google/uuid"
+)
+
+type Service interface {
+	Impersonate(
+		actor *userModel.User, keystore *model.Keystore, userId uuid.UUID, clientInfo *authDto.ClientInfo,
+	) (*dto.ImpersonationToken, error)
+}
+
+type service struct {
+	authService  auth.Service
+	userService  user.Service
+	auditService audit.Service
+}
+
+func NewService(authService auth.Service, userService user.Service, auditService audit.Service) Service {
+	return &service{
+		authService:  authService,
+		userService:  userService,
+		auditService: auditService,
+	}
+}
+
+// Impersonate issues a token to act as an active user, admins are never impersonated
+// so that the impersonation can not be used to gain other admins' sessions
+func (s *service) Impersonate(
+	actor *userModel.User, keystore *model.Keystore, userId uuid.UUID, clientInfo *authDto.ClientInfo,
+) (*dto.ImpersonationToken, error) {
+	if actor.ID == userId {
+		return nil, network.NewBadRequestError("you can not impersonate yourself", nil)
+	}
+
+	user, err := s.userService.FetchUserById(userId)
+	if err != nil {
+		return nil, network.NewNotFoundError("user not found", err)
+	}
+
+	isAdmin := slices.ContainsFunc(user.Roles, func(role *userModel.Role) bool {
+		return role.Code == userModel.RoleCodeAdmin
+	})
+	if isAdmin {
+		return nil, network.NewForbiddenError("admins can not be impersonated", nil)
+	}
+
+	token, expiresAt, err := s.authService.GenerateImpersonationToken(actor, keystore, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(&model.AuditEvent{
+		Action:     model.AuditActionImpersonationStart,
+		Outcome:    model.AuditOutcomeSuccess,
+		ActorID:    &actor.ID,
+		TargetType: model.AuditTargetUser,
+		TargetID:   user.ID.String(),
+		IP:         clientInfo.IP,
+		UserAgent:  clientInfo.UserAgent,
+		Metadata:   map[string]string{"expiresAt": expiresAt.UTC().Format(time.RFC3339)},
+	})
+
+	return dto.NewImpersonationToken(token, expiresAt, userDto.NewUserPublic(user)), nil
+}
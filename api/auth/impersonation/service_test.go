@@ -0,0 +1,88 @@
+package impersonation
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit"
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var clientInfo = authDto.NewClientInfo("test-agent", "127.0.0.1")
+
+func assertNetworkError(t *testing.T, err error, status int) {
+	apiErr, ok := err.(network.ApiError)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, status, apiErr.GetCode())
+	}
+}
+
+func TestImpersonationService_Self(t *testing.T) {
+	authService := new(auth.MockService)
+	userService := new(user.MockService)
+	actor := &userModel.User{ID: uuid.New()}
+
+	_, err := NewService(authService, userService, new(audit.MockService)).Impersonate(actor, &model.Keystore{}, actor.ID, clientInfo)
+	assertNetworkError(t, err, http.StatusBadRequest)
+	userService.AssertNotCalled(t, "FetchUserById", mock.Anything)
+}
+
+func TestImpersonationService_UnknownUser(t *testing.T) {
+	authService := new(auth.MockService)
+	userService := new(user.MockService)
+	actor := &userModel.User{ID: uuid.New()}
+	userId := uuid.New()
+	userService.On("FetchUserById", userId).Return(nil, errors.New("no rows"))
+
+	_, err := NewService(authService, userService, new(audit.MockService)).Impersonate(actor, &model.Keystore{}, userId, clientInfo)
+	assertNetworkError(t, err, http.StatusNotFound)
+}
+
+func TestImpersonationService_Admin(t *testing.T) {
+	authService := new(auth.MockService)
+	userService := new(user.MockService)
+	actor := &userModel.User{ID: uuid.New()}
+	admin := &userModel.User{ID: uuid.New(), Roles: []*userModel.Role{{Code: userModel.RoleCodeAdmin}}}
+	userService.On("FetchUserById", admin.ID).Return(admin, nil)
+
+	_, err := NewService(authService, userService, new(audit.MockService)).Impersonate(actor, &model.Keystore{}, admin.ID, clientInfo)
+	assertNetworkError(t, err, http.StatusForbidden)
+	authService.AssertNotCalled(t, "GenerateImpersonationToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImpersonationService_Success(t *testing.T) {
+	authService := new(auth.MockService)
+	userService := new(user.MockService)
+	actor := &userModel.User{ID: uuid.New()}
+	keystore := &model.Keystore{PrimaryKey: "actor-session"}
+	author := &userModel.User{ID: uuid.New(), Name: "author", Roles: []*userModel.Role{{Code: userModel.RoleCodeAuthor}}}
+	expiresAt := time.Now().Add(15 * time.Minute)
+	userService.On("FetchUserById", author.ID).Return(author, nil)
+	authService.On("GenerateImpersonationToken", actor, keystore, author).Return("token", expiresAt, nil)
+
+	auditService := new(audit.MockService)
+	auditService.On("Record", mock.MatchedBy(func(event *model.AuditEvent) bool {
+		return event.Action == model.AuditActionImpersonationStart &&
+			*event.ActorID == actor.ID &&
+			event.TargetID == author.ID.String() &&
+			event.IP == clientInfo.IP
+	})).Return()
+
+	token, err := NewService(authService, userService, auditService).Impersonate(actor, keystore, author.ID, clientInfo)
+	assert.NoError(t, err)
+	auditService.AssertExpectations(t)
+	assert.Equal(t, "token", token.AccessToken)
+	assert.Equal(t, expiresAt, token.ExpiresAt)
+	assert.Equal(t, author.ID, token.User.ID)
+}
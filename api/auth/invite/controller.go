@@ -0,0 +1,137 @@
+package invite
+
+import (
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/invite/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	coredto "github.com/afteracademy/goserve/v2/dto"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+	cookies common.TokenCookies
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+	cookies common.TokenCookies,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/invites", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+		cookies:        cookies,
+	}
+}
+
+// MountRoutes serves the invitee routes to the app clients and the invite management
+// to the editor and admin clients
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	invitee := group.Group("", common.KeyPermission(model.GeneralPermission))
+	invitee.POST("/signup", c.signUpHandler)
+	invitee.POST("/accept", c.Authentication(), common.RejectImpersonation(), c.acceptHandler)
+
+	manage := group.Group("",
+		common.KeyPermission(model.AuthorPermission, model.AdminPermission),
+		c.Authentication(),
+		common.RejectImpersonation(),
+		c.Authorization(string(userModel.PermissionUserInvite)),
+	)
+	manage.POST("", c.createInviteHandler)
+	manage.GET("", c.getInvitesHandler)
+	manage.DELETE("/id/:id", c.revokeInviteHandler)
+}
+
+func (c *controller) createInviteHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.InviteCreate](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	inviter := c.MustGetUser(ctx)
+
+	invite, err := c.service.CreateInvite(inviter, body, authDto.NewClientInfo(ctx.Request.UserAgent(), ctx.ClientIP()))
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "invite sent", invite)
+}
+
+func (c *controller) getInvitesHandler(ctx *gin.Context) {
+	search, err := network.ReqQuery[dto.InviteSearch](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	invites, err := c.service.GetInvites(search)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", &invites)
+}
+
+func (c *controller) revokeInviteHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	inviter := c.MustGetUser(ctx)
+
+	err = c.service.RevokeInvite(inviter, uuidParam.ID, authDto.NewClientInfo(ctx.Request.UserAgent(), ctx.ClientIP()))
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "invite revoked successfully")
+}
+
+func (c *controller) signUpHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.InviteSignUp](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	data, err := c.service.SignUp(body, authDto.NewClientInfo(ctx.Request.UserAgent(), ctx.ClientIP()))
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	common.SendUserAuth(ctx, c.cookies, data)
+}
+
+func (c *controller) acceptHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.InviteAccept](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	invite, err := c.service.Accept(user, body, authDto.NewClientInfo(ctx.Request.UserAgent(), ctx.ClientIP()))
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "invite accepted", invite)
+}
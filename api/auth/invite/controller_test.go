@@ -0,0 +1,146 @@
+package invite
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/invite/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var editorUser = &userModel.User{
+	ID:    uuid.New(),
+	Email: "editor@abc.com",
+	Name:  "editor",
+	Roles: []*userModel.Role{{Code: userModel.RoleCodeEditor}},
+}
+
+func mockProviders() (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider) {
+	authProvider := new(network.MockAuthenticationProvider)
+	authProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, editorUser)
+		ctx.Next()
+	}))
+	authorizeProvider := new(network.MockAuthorizationProvider)
+	authorizeProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+	return authProvider, authorizeProvider
+}
+
+func newInviteInfo(email string) *dto.InviteInfo {
+	return &dto.InviteInfo{
+		ID:        uuid.New(),
+		Email:     email,
+		Roles:     []userModel.RoleCode{userModel.RoleCodeAuthor},
+		Status:    model.InviteStatusPending,
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestInviteController_CreateLearnerRole(t *testing.T) {
+	service := new(MockService)
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service, common.NewTokenCookies(&config.Env{}))
+
+	body := `{"email":"new@abc.com","roles":["LEARNER"]}`
+	rr := network.MockTestController(t, "POST", "/invites", body, common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "CreateInvite", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestInviteController_CreateSuccess(t *testing.T) {
+	service := new(MockService)
+	create := &dto.InviteCreate{Email: "new@abc.com", Roles: []userModel.RoleCode{userModel.RoleCodeAuthor}}
+	service.On("CreateInvite", editorUser, create, mock.Anything).Return(newInviteInfo(create.Email), nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service, common.NewTokenCookies(&config.Env{}))
+
+	body := `{"email":"new@abc.com","roles":["AUTHOR"]}`
+	rr := network.MockTestController(t, "POST", "/invites", body, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"PENDING"`)
+	service.AssertExpectations(t)
+}
+
+func TestInviteController_ListBadStatus(t *testing.T) {
+	service := new(MockService)
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "GET", "/invites?page=1&limit=10&status=USED", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "GetInvites", mock.Anything)
+}
+
+func TestInviteController_RevokeNotFound(t *testing.T) {
+	service := new(MockService)
+	id := uuid.New()
+	service.On("RevokeInvite", editorUser, id, mock.Anything).
+		Return(network.NewNotFoundError("pending invite not found", nil))
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "DELETE", "/invites/id/"+id.String(), "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	service.AssertExpectations(t)
+}
+
+func TestInviteController_SignUpShortPassword(t *testing.T) {
+	service := new(MockService)
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service, common.NewTokenCookies(&config.Env{}))
+
+	body := `{"token":"abc","password":"123","name":"new user"}`
+	rr := network.MockTestController(t, "POST", "/invites/signup", body, common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "SignUp", mock.Anything, mock.Anything)
+}
+
+func TestInviteController_SignUpSuccess(t *testing.T) {
+	service := new(MockService)
+	user := &userModel.User{
+		ID:    uuid.New(),
+		Email: "new@abc.com",
+		Name:  "new user",
+		Roles: []*userModel.Role{{ID: uuid.New(), Code: userModel.RoleCodeAuthor}},
+	}
+	service.On("SignUp", mock.MatchedBy(func(d *dto.InviteSignUp) bool {
+		return d.Token == "abc" && d.Name == "new user"
+	}), mock.Anything).Return(authDto.NewUserAuth(user, authDto.NewTokens("access", "refresh")), nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service, common.NewTokenCookies(&config.Env{}))
+
+	body := `{"token":"abc","password":"123456","name":"new user"}`
+	rr := network.MockTestController(t, "POST", "/invites/signup", body, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"accessToken":"access"`)
+	service.AssertExpectations(t)
+}
+
+func TestInviteController_AcceptOtherEmail(t *testing.T) {
+	service := new(MockService)
+	service.On("Accept", editorUser, &dto.InviteAccept{Token: "abc"}, mock.Anything).
+		Return(nil, network.NewForbiddenError("the invite is for another email", nil))
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "POST", "/invites/accept", `{"token":"abc"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	service.AssertExpectations(t)
+}
@@ -0,0 +1,5 @@
+package dto
+
+type InviteAccept struct {
+	Token string `json:"token" binding:"required" validate:"required"`
+}
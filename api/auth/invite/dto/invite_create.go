@@ -0,0 +1,9 @@
+package dto
+
+import userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+
+// InviteCreate lists the roles granted on top of the learner role, only admins can invite admins
+type InviteCreate struct {
+	Email string               `json:"email" binding:"required" validate:"required,email"`
+	Roles []userModel.RoleCode `json:"roles" binding:"required" validate:"required,min=1,max=3,unique,dive,oneof=AUTHOR EDITOR ADMIN"`
+}
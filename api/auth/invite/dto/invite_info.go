@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/google/uuid"
+)
+
+type InviteInfo struct {
+	ID         uuid.UUID            `json:"id" validate:"required"`
+	Email      string               `json:"email" validate:"required,email"`
+	Roles      []userModel.RoleCode `json:"roles" validate:"required"`
+	Status     model.InviteStatus   `json:"status" validate:"required"`
+	InvitedBy  *uuid.UUID           `json:"invitedBy,omitempty"`
+	AcceptedBy *uuid.UUID           `json:"acceptedBy,omitempty"`
+	ExpiresAt  time.Time            `json:"expiresAt" validate:"required"`
+	AcceptedAt *time.Time           `json:"acceptedAt,omitempty"`
+	RevokedAt  *time.Time           `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time            `json:"createdAt" validate:"required"`
+}
+
+func NewInviteInfo(invite *model.Invite) *InviteInfo {
+	return &InviteInfo{
+		ID:         invite.ID,
+		Email:      invite.Email,
+		Roles:      invite.Roles,
+		Status:     invite.Status(),
+		InvitedBy:  invite.InvitedBy,
+		AcceptedBy: invite.AcceptedBy,
+		ExpiresAt:  invite.ExpiresAt,
+		AcceptedAt: invite.AcceptedAt,
+		RevokedAt:  invite.RevokedAt,
+		CreatedAt:  invite.CreatedAt,
+	}
+}
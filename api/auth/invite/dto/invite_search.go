@@ -0,0 +1,10 @@
+package dto
+
+import "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+
+type InviteSearch struct {
+	Page   int64              `form:"page" binding:"required" validate:"required,min=1,max=1000"`
+	Limit  int64              `form:"limit" binding:"required" validate:"required,min=1,max=1000"`
+	Email  string             `form:"email" validate:"omitempty,max=200"`
+	Status model.InviteStatus `form:"status" validate:"omitempty,oneof=PENDING ACCEPTED REVOKED EXPIRED"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// InviteSignUp creates the account of the invited email, so the email is not asked again
+type InviteSignUp struct {
+	Token         string  `json:"token" binding:"required" validate:"required"`
+	Password      string  `json:"password" binding:"required" validate:"required,min=6,max=100"`
+	Name          string  `json:"name" binding:"required" validate:"required,min=2,max=200"`
+	ProfilePicUrl *string `json:"profilePicUrl,omitempty" validate:"omitempty,url"`
+}
@@ -0,0 +1,54 @@
+package invite
+
+import (
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/invite/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) CreateInvite(
+	inviter *userModel.User, d *dto.InviteCreate, clientInfo *authDto.ClientInfo,
+) (*dto.InviteInfo, error) {
+	args := m.Called(inviter, d, clientInfo)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.InviteInfo), args.Error(1)
+}
+
+func (m *MockService) GetInvites(d *dto.InviteSearch) ([]*dto.InviteInfo, error) {
+	args := m.Called(d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.InviteInfo), args.Error(1)
+}
+
+func (m *MockService) RevokeInvite(inviter *userModel.User, id uuid.UUID, clientInfo *authDto.ClientInfo) error {
+	args := m.Called(inviter, id, clientInfo)
+	return args.Error(0)
+}
+
+func (m *MockService) SignUp(d *dto.InviteSignUp, clientInfo *authDto.ClientInfo) (*authDto.UserAuth, error) {
+	args := m.Called(d, clientInfo)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authDto.UserAuth), args.Error(1)
+}
+
+func (m *MockService) Accept(
+	user *userModel.User, d *dto.InviteAccept, clientInfo *authDto.ClientInfo,
+) (*dto.InviteInfo, error) {
+	args := m.Called(user, d, clientInfo)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.InviteInfo), args.Error(1)
+}
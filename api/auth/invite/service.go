@@ -0,0 +1,450 @@
+package invite
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/cache"
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/hasher"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/invite/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve-example-api-server-postgres/mailer"
+	"github.com/afteracademy/goserve-example-api-server-postgres/utils"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/afteracademy/goserve/v2/utility"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Service interface {
+	CreateInvite(inviter *userModel.User, d *dto.InviteCreate, clientInfo *authDto.ClientInfo) (*dto.InviteInfo, error)
+	GetInvites(d *dto.InviteSearch) ([]*dto.InviteInfo, error)
+	RevokeInvite(inviter *userModel.User, id uuid.UUID, clientInfo *authDto.ClientInfo) error
+	SignUp(d *dto.InviteSignUp, clientInfo *authDto.ClientInfo) (*authDto.UserAuth, error)
+	Accept(user *userModel.User, d *dto.InviteAccept, clientInfo *authDto.ClientInfo) (*dto.InviteInfo, error)
+}
+
+type service struct {
+	db           postgres.Database
+	authService  auth.Service
+	userService  user.Service
+	auditService audit.Service
+	authCache    cache.Service
+	mailer       mailer.Sender
+	hasher       hasher.Hasher
+	policy       hasher.Policy
+	// link
+	inviteUrl      string
+	inviteValidity time.Duration
+}
+
+func NewService(
+	db postgres.Database,
+	env *config.Env,
+	authService auth.Service,
+	userService user.Service,
+	auditService audit.Service,
+	authCache cache.Service,
+	mailer mailer.Sender,
+	passwordHasher hasher.Hasher,
+	passwordPolicy hasher.Policy,
+) Service {
+	return &service{
+		db:             db,
+		authService:    authService,
+		userService:    userService,
+		auditService:   auditService,
+		authCache:      authCache,
+		mailer:         mailer,
+		hasher:         passwordHasher,
+		policy:         passwordPolicy,
+		inviteUrl:      env.InviteUrl,
+		inviteValidity: time.Duration(env.InviteValiditySec) * time.Second,
+	}
+}
+
+const inviteColumns = `
+	id,
+	email,
+	roles,
+	token_hash,
+	invited_by,
+	accepted_by,
+	expires_at,
+	accepted_at,
+	revoked_at,
+	created_at
+`
+
+func scanInvite(row pgx.Row) (*model.Invite, error) {
+	var invite model.Invite
+	err := row.Scan(
+		&invite.ID,
+		&invite.Email,
+		&invite.Roles,
+		&invite.TokenHash,
+		&invite.InvitedBy,
+		&invite.AcceptedBy,
+		&invite.ExpiresAt,
+		&invite.AcceptedAt,
+		&invite.RevokedAt,
+		&invite.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// the pending condition of an invite, the expiry is checked by the database as for the user tokens
+const pendingCondition = `
+	accepted_at IS NULL
+	AND revoked_at IS NULL
+	AND expires_at > CURRENT_TIMESTAMP
+`
+
+func isAdmin(user *userModel.User) bool {
+	return slices.ContainsFunc(user.Roles, func(role *userModel.Role) bool {
+		return role.Code == userModel.RoleCodeAdmin
+	})
+}
+
+// CreateInvite mails a link with a random token, a pending invite of the same email is revoked
+// so that only the latest link works
+func (s *service) CreateInvite(
+	inviter *userModel.User, d *dto.InviteCreate, clientInfo *authDto.ClientInfo,
+) (*dto.InviteInfo, error) {
+	if slices.Contains(d.Roles, userModel.RoleCodeAdmin) && !isAdmin(inviter) {
+		return nil, network.NewForbiddenError("only admins can invite admins", nil)
+	}
+
+	token, err := utility.GenerateRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	revokeQuery := `
+		UPDATE invites
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE email = $1
+		  AND accepted_at IS NULL
+		  AND revoked_at IS NULL
+	`
+
+	_, err = tx.Exec(ctx, revokeQuery, d.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	insertQuery := `
+		INSERT INTO invites (
+			email,
+			roles,
+			token_hash,
+			invited_by,
+			expires_at
+		)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING` + inviteColumns
+
+	invite, err := scanInvite(tx.QueryRow(
+		ctx,
+		insertQuery,
+		d.Email,
+		d.Roles,
+		utils.HashToken(token),
+		inviter.ID,
+		time.Now().Add(s.inviteValidity),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	s.record(inviteEvent(model.AuditActionInviteCreate, &inviter.ID, invite), clientInfo)
+
+	msg := &mailer.Message{
+		To:      invite.Email,
+		Subject: "You are invited",
+		Body: "Hi,\n\n" +
+			inviter.Name + " invited you to join as " + joinRoles(invite.Roles) + ".\n" +
+			"Open the link below to sign up, or to sign in if you already have an account:\n" +
+			s.inviteUrl + "?token=" + url.QueryEscape(token) + "\n\n" +
+			"The invite expires in " + s.inviteValidity.String() + ".",
+	}
+
+	if err := s.mailer.Send(msg); err != nil {
+		return nil, err
+	}
+
+	return dto.NewInviteInfo(invite), nil
+}
+
+func (s *service) GetInvites(d *dto.InviteSearch) ([]*dto.InviteInfo, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT` + inviteColumns + `
+		FROM invites
+		WHERE ($1 = '' OR email = $1)
+		  AND CASE $2
+			WHEN 'PENDING' THEN ` + pendingCondition + `
+			WHEN 'ACCEPTED' THEN accepted_at IS NOT NULL
+			WHEN 'REVOKED' THEN revoked_at IS NOT NULL AND accepted_at IS NULL
+			WHEN 'EXPIRED' THEN accepted_at IS NULL AND revoked_at IS NULL AND expires_at <= CURRENT_TIMESTAMP
+			ELSE TRUE
+		  END
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	offset := (d.Page - 1) * d.Limit
+
+	rows, err := s.db.Pool().Query(ctx, query, d.Email, string(d.Status), d.Limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos := []*dto.InviteInfo{}
+
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		dtos = append(dtos, dto.NewInviteInfo(invite))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dtos, nil
+}
+
+// RevokeInvite stops a pending invite, admins revoke any invite and the others only their own
+func (s *service) RevokeInvite(inviter *userModel.User, id uuid.UUID, clientInfo *authDto.ClientInfo) error {
+	ctx := context.Background()
+
+	query := `
+		UPDATE invites
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		  AND accepted_at IS NULL
+		  AND revoked_at IS NULL
+		  AND ($2 OR invited_by = $3)
+		RETURNING` + inviteColumns
+
+	invite, err := scanInvite(s.db.Pool().QueryRow(ctx, query, id, isAdmin(inviter), inviter.ID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return network.NewNotFoundError("pending invite not found", err)
+		}
+		return err
+	}
+
+	s.record(inviteEvent(model.AuditActionInviteRevoke, &inviter.ID, invite), clientInfo)
+	return nil
+}
+
+// SignUp creates the invited user with the roles of the invite, the user is created only
+// if the invite is still pending when it is marked accepted in the same transaction
+func (s *service) SignUp(d *dto.InviteSignUp, clientInfo *authDto.ClientInfo) (*authDto.UserAuth, error) {
+	invite, err := s.findPendingInvite(d.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.authService.IsEmailRegisted(invite.Email) {
+		return nil, network.NewBadRequestError("user already registered, sign in to accept the invite", nil)
+	}
+
+	codes := append([]userModel.RoleCode{userModel.RoleCodeLearner}, invite.Roles...)
+	roles := make([]*userModel.Role, 0, len(codes))
+	for _, code := range codes {
+		role, err := s.userService.FetchRoleByCode(code)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	err = s.policy.Check(d.Password, invite.Email, d.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	hashed, err := s.hasher.Hash(d.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.CreateInvitedUser(
+		invite.Email, hashed, d.Name, d.ProfilePicUrl, roles,
+		func(ctx context.Context, tx pgx.Tx, user *userModel.User) error {
+			return acceptInvite(ctx, tx, invite.ID, user.ID)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(inviteEvent(model.AuditActionInviteAccept, &user.ID, invite), clientInfo)
+
+	accessToken, refreshToken, err := s.authService.GenerateToken(user, clientInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := authDto.NewTokens(accessToken, refreshToken)
+	return authDto.NewUserAuth(user, tokens), nil
+}
+
+// Accept grants the roles of an invite to a signed in user of the invited email,
+// the invite is used and the roles are granted in one transaction
+func (s *service) Accept(
+	user *userModel.User, d *dto.InviteAccept, clientInfo *authDto.ClientInfo,
+) (*dto.InviteInfo, error) {
+	invite, err := s.findPendingInvite(d.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if invite.Email != user.Email {
+		return nil, network.NewForbiddenError("the invite is for another email", nil)
+	}
+
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := acceptInvite(ctx, tx, invite.ID, user.ID); err != nil {
+		return nil, err
+	}
+
+	grantQuery := `
+		INSERT INTO user_roles (user_id, role_id)
+		SELECT $1, id
+		FROM roles
+		WHERE code = ANY($2)
+		  AND status = TRUE
+		ON CONFLICT DO NOTHING
+	`
+
+	_, err = tx.Exec(ctx, grantQuery, user.ID, invite.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	// the roles of the user are cached for the authorization
+	s.authCache.ClearUser(user.ID)
+
+	s.record(inviteEvent(model.AuditActionInviteAccept, &user.ID, invite), clientInfo)
+
+	now := time.Now()
+	invite.AcceptedAt = &now
+	invite.AcceptedBy = &user.ID
+	return dto.NewInviteInfo(invite), nil
+}
+
+func (s *service) findPendingInvite(token string) (*model.Invite, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT` + inviteColumns + `
+		FROM invites
+		WHERE token_hash = $1
+		  AND ` + pendingCondition
+
+	invite, err := scanInvite(s.db.Pool().QueryRow(ctx, query, utils.HashToken(token)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, network.NewBadRequestError("invalid or expired invite", err)
+		}
+		return nil, err
+	}
+
+	return invite, nil
+}
+
+// acceptInvite marks the invite used, the row lock makes a concurrent accept of the same
+// invite find it accepted and fail
+func acceptInvite(ctx context.Context, tx pgx.Tx, id uuid.UUID, userId uuid.UUID) error {
+	query := `
+		UPDATE invites
+		SET accepted_at = CURRENT_TIMESTAMP,
+			accepted_by = $2
+		WHERE id = $1
+		  AND ` + pendingCondition
+
+	tag, err := tx.Exec(ctx, query, id, userId)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return network.NewBadRequestError("invalid or expired invite", nil)
+	}
+	return nil
+}
+
+func (s *service) record(event *model.AuditEvent, clientInfo *authDto.ClientInfo) {
+	if clientInfo != nil {
+		event.IP = clientInfo.IP
+		event.UserAgent = clientInfo.UserAgent
+	}
+	s.auditService.Record(event)
+}
+
+func inviteEvent(action model.AuditAction, actorId *uuid.UUID, invite *model.Invite) *model.AuditEvent {
+	return &model.AuditEvent{
+		Action:     action,
+		Outcome:    model.AuditOutcomeSuccess,
+		ActorID:    actorId,
+		TargetType: model.AuditTargetInvite,
+		TargetID:   invite.ID.String(),
+		Metadata: map[string]string{
+			"email": invite.Email,
+			"roles": joinRoles(invite.Roles),
+		},
+	}
+}
+
+func joinRoles(roles []userModel.RoleCode) string {
+	codes := make([]string, len(roles))
+	for i, role := range roles {
+		codes[i] = string(role)
+	}
+	return strings.Join(codes, ", ")
+}
@@ -0,0 +1,46 @@
+package invite
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit"
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/invite/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInviteService_OnlyAdminsInviteAdmins(t *testing.T) {
+	auditService := new(audit.MockService)
+	s := &service{auditService: auditService}
+
+	editor := &userModel.User{
+		ID:    uuid.New(),
+		Roles: []*userModel.Role{{Code: userModel.RoleCodeEditor}},
+	}
+
+	_, err := s.CreateInvite(editor, &dto.InviteCreate{
+		Email: "new@abc.com",
+		Roles: []userModel.RoleCode{userModel.RoleCodeAuthor, userModel.RoleCodeAdmin},
+	}, authDto.NewClientInfo("test-agent", "127.0.0.1"))
+
+	apiErr, ok := err.(network.ApiError)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, http.StatusForbidden, apiErr.GetCode())
+	}
+	auditService.AssertNotCalled(t, "Record", mock.Anything)
+}
+
+func TestInviteService_IsAdmin(t *testing.T) {
+	assert.True(t, isAdmin(&userModel.User{Roles: []*userModel.Role{
+		{Code: userModel.RoleCodeLearner},
+		{Code: userModel.RoleCodeAdmin},
+	}}))
+	assert.False(t, isAdmin(&userModel.User{Roles: []*userModel.Role{{Code: userModel.RoleCodeEditor}}}))
+	assert.False(t, isAdmin(&userModel.User{}))
+}
@@ -0,0 +1,33 @@
+package jwks
+
+import (
+	"net/http"
+
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	service Service
+}
+
+func NewController(
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller: network.NewController("/.well-known", nil, nil),
+		service:    service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.GET("/jwks.json", c.getJwksHandler)
+}
+
+// getJwksHandler responds with a bare JWK Set (RFC 7517) instead of the api envelope
+// so that standard jwt libraries of other services can consume it
+func (c *controller) getJwksHandler(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, c.service.GetJwks())
+}
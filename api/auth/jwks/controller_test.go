@@ -0,0 +1,22 @@
+package jwks
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJwksController_GetJwks(t *testing.T) {
+	key := generateKey(t)
+	ring := NewKeyRingFromKeys(key)
+
+	c := NewController(NewService(ring))
+
+	rr := network.MockTestController(t, "GET", "/.well-known/jwks.json", "", c)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"kid":"`+Thumbprint(&key.PublicKey)+`"`)
+	assert.Contains(t, rr.Body.String(), `"e":"AQAB"`)
+	assert.Contains(t, rr.Body.String(), `"alg":"RS256"`)
+}
@@ -0,0 +1,25 @@
+package dto
+
+type Jwk struct {
+	Kty string `json:"kty" validate:"required"`
+	Use string `json:"use" validate:"required"`
+	Alg string `json:"alg" validate:"required"`
+	Kid string `json:"kid" validate:"required"`
+	N   string `json:"n" validate:"required"`
+	E   string `json:"e" validate:"required"`
+}
+
+func NewRS256Jwk(kid string, n string, e string) *Jwk {
+	return &Jwk{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: "RS256",
+		Kid: kid,
+		N:   n,
+		E:   e,
+	}
+}
+
+type Jwks struct {
+	Keys []*Jwk `json:"keys" validate:"required,dive,required"`
+}
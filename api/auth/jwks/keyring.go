@@ -0,0 +1,153 @@
+package jwks
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve-example-api-server-postgres/utils"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Key struct {
+	ID        string
+	PublicKey *rsa.PublicKey
+}
+
+// KeyRing holds the active signing key and every public key a token may still be signed with
+type KeyRing interface {
+	SigningKey() (string, *rsa.PrivateKey)
+	PublicKey(kid string) (*rsa.PublicKey, bool)
+	PublicKeys() []*Key
+}
+
+type keyRing struct {
+	signingKeyID string
+	signingKey   *rsa.PrivateKey
+	keys         []*Key
+}
+
+// NewKeyRing loads the active key pair and the retired public keys from the env
+func NewKeyRing(env *config.Env) KeyRing {
+	privatePem, err := utils.LoadPEMFileInto(env.RSAPrivateKeyPath)
+	if err != nil {
+		panic(err)
+	}
+	rsaPrivateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePem)
+	if err != nil {
+		panic(err)
+	}
+
+	publicKeys := make([]*rsa.PublicKey, 0)
+
+	paths := []string{env.RSAPublicKeyPath}
+	paths = append(paths, strings.Split(env.RSARetiredPublicKeyPaths, ",")...)
+
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		publicPem, err := utils.LoadPEMFileInto(path)
+		if err != nil {
+			panic(err)
+		}
+
+		rsaPublicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPem)
+		if err != nil {
+			panic(err)
+		}
+
+		publicKeys = append(publicKeys, rsaPublicKey)
+	}
+
+	if !rsaPrivateKey.PublicKey.Equal(publicKeys[0]) {
+		panic(errors.New("RSA_PUBLIC_KEY_PATH does not belong to RSA_PRIVATE_KEY_PATH"))
+	}
+
+	return NewKeyRingFromKeys(rsaPrivateKey, publicKeys[1:]...)
+}
+
+func NewKeyRingFromKeys(signingKey *rsa.PrivateKey, retiredKeys ...*rsa.PublicKey) KeyRing {
+	signingKeyID := Thumbprint(&signingKey.PublicKey)
+
+	keys := []*Key{{ID: signingKeyID, PublicKey: &signingKey.PublicKey}}
+	seen := map[string]bool{signingKeyID: true}
+
+	for _, publicKey := range retiredKeys {
+		kid := Thumbprint(publicKey)
+		if seen[kid] {
+			continue
+		}
+		seen[kid] = true
+		keys = append(keys, &Key{ID: kid, PublicKey: publicKey})
+	}
+
+	return &keyRing{
+		signingKeyID: signingKeyID,
+		signingKey:   signingKey,
+		keys:         keys,
+	}
+}
+
+func (r *keyRing) SigningKey() (string, *rsa.PrivateKey) {
+	return r.signingKeyID, r.signingKey
+}
+
+func (r *keyRing) PublicKey(kid string) (*rsa.PublicKey, bool) {
+	for _, key := range r.keys {
+		if key.ID == kid {
+			return key.PublicKey, true
+		}
+	}
+	return nil, false
+}
+
+func (r *keyRing) PublicKeys() []*Key {
+	return r.keys
+}
+
+// Thumbprint is the RFC 7638 JWK thumbprint of the key, used as its kid
+func Thumbprint(publicKey *rsa.PublicKey) string {
+	n := encodeModulus(publicKey)
+	e := encodeExponent(publicKey)
+	// members in lexicographic order without whitespace
+	canonical := `{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func encodeModulus(publicKey *rsa.PublicKey) string {
+	return base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+}
+
+func encodeExponent(publicKey *rsa.PublicKey) string {
+	return base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+}
+
+// DecodePublicKey reads the n and e members of a RSA jwk, like the ones of an external jwks
+func DecodePublicKey(n string, e string) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+
+	exp := new(big.Int).SetBytes(exponent)
+	if len(modulus) == 0 || !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid rsa jwk")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(exp.Int64()),
+	}, nil
+}
@@ -0,0 +1,68 @@
+package jwks
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return key
+}
+
+func TestThumbprint(t *testing.T) {
+	key := generateKey(t)
+	other := generateKey(t)
+
+	kid := Thumbprint(&key.PublicKey)
+	assert.Len(t, kid, 43)
+	assert.Equal(t, kid, Thumbprint(&key.PublicKey))
+	assert.NotEqual(t, kid, Thumbprint(&other.PublicKey))
+}
+
+func TestKeyRing_SigningKey(t *testing.T) {
+	key := generateKey(t)
+
+	ring := NewKeyRingFromKeys(key)
+
+	kid, signingKey := ring.SigningKey()
+	assert.Equal(t, Thumbprint(&key.PublicKey), kid)
+	assert.Equal(t, key, signingKey)
+}
+
+func TestKeyRing_PublicKeys(t *testing.T) {
+	key := generateKey(t)
+	retired := generateKey(t)
+
+	// the active key repeated as retired must not be listed twice
+	ring := NewKeyRingFromKeys(key, &retired.PublicKey, &key.PublicKey)
+
+	keys := ring.PublicKeys()
+	assert.Len(t, keys, 2)
+	assert.Equal(t, Thumbprint(&key.PublicKey), keys[0].ID)
+
+	publicKey, found := ring.PublicKey(Thumbprint(&retired.PublicKey))
+	assert.True(t, found)
+	assert.True(t, retired.PublicKey.Equal(publicKey))
+
+	_, found = ring.PublicKey("unknown")
+	assert.False(t, found)
+}
+
+func TestDecodePublicKey(t *testing.T) {
+	key := generateKey(t)
+
+	decoded, err := DecodePublicKey(encodeModulus(&key.PublicKey), encodeExponent(&key.PublicKey))
+	assert.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(decoded))
+
+	_, err = DecodePublicKey("", encodeExponent(&key.PublicKey))
+	assert.Error(t, err)
+
+	_, err = DecodePublicKey(encodeModulus(&key.PublicKey), "!")
+	assert.Error(t, err)
+}
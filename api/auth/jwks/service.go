@@ -0,0 +1,27 @@
+package jwks
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/jwks/dto"
+)
+
+type Service interface {
+	GetJwks() *dto.Jwks
+}
+
+type service struct {
+	keyRing KeyRing
+}
+
+func NewService(keyRing KeyRing) Service {
+	return &service{
+		keyRing: keyRing,
+	}
+}
+
+func (s *service) GetJwks() *dto.Jwks {
+	keys := make([]*dto.Jwk, 0)
+	for _, key := range s.keyRing.PublicKeys() {
+		keys = append(keys, dto.NewRS256Jwk(key.ID, encodeModulus(key.PublicKey), encodeExponent(key.PublicKey)))
+	}
+	return &dto.Jwks{Keys: keys}
+}
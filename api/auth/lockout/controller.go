@@ -0,0 +1,54 @@
+package lockout
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/lockout/dto"
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/admin/lockouts", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(
+		common.KeyPermission(authModel.AdminPermission),
+		c.Authentication(),
+		common.RejectImpersonation(),
+		c.Authorization(string(userModel.PermissionLockoutManage)),
+	)
+	group.DELETE("", c.clearLockoutHandler)
+}
+
+func (c *controller) clearLockoutHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.LockoutClear](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	err = c.service.ClearLockout(body)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "lockout cleared successfully")
+}
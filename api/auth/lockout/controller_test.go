@@ -0,0 +1,51 @@
+package lockout
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/lockout/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func mockProviders() (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	mockAuthzProvider := new(network.MockAuthorizationProvider)
+	mockAuthzProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	return mockAuthProvider, mockAuthzProvider
+}
+
+func TestLockoutController_ClearBadRequest(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider := mockProviders()
+	service := new(MockService)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, service)
+
+	rr := network.MockTestController(t, "DELETE", "/admin/lockouts", "{}", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "ClearLockout", mock.Anything)
+}
+
+func TestLockoutController_ClearSuccess(t *testing.T) {
+	mockAuthProvider, mockAuthzProvider := mockProviders()
+	service := new(MockService)
+	service.On("ClearLockout", &dto.LockoutClear{Email: "test@abc.com"}).Return(nil)
+
+	c := NewController(mockAuthProvider, mockAuthzProvider, service)
+
+	rr := network.MockTestController(t, "DELETE", "/admin/lockouts", `{"email":"test@abc.com"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"lockout cleared successfully"`)
+	service.AssertExpectations(t)
+}
@@ -0,0 +1,6 @@
+package dto
+
+type LockoutClear struct {
+	Email string `json:"email" binding:"required_without=IP,omitempty,email" validate:"required_without=IP,omitempty,email"`
+	IP    string `json:"ip" binding:"required_without=Email,omitempty,ip" validate:"required_without=Email,omitempty,ip"`
+}
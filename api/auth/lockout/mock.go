@@ -0,0 +1,30 @@
+package lockout
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/lockout/dto"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) Check(email string, ip string) error {
+	args := m.Called(email, ip)
+	return args.Error(0)
+}
+
+func (m *MockService) RegisterFailure(email string, ip string) error {
+	args := m.Called(email, ip)
+	return args.Error(0)
+}
+
+func (m *MockService) RegisterSuccess(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockService) ClearLockout(lockoutClearDto *dto.LockoutClear) error {
+	args := m.Called(lockoutClearDto)
+	return args.Error(0)
+}
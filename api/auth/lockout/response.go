@@ -0,0 +1,26 @@
+package lockout
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+// same as the failure code of the network package responses
+const failureResCode network.ResCode = "10001"
+
+// SendLockedError responds with 429 since network.SendMixedError only knows the standard api errors
+func SendLockedError(ctx *gin.Context, err *LockedError) {
+	retryAfter := int(math.Ceil(err.RetryAfter.Seconds()))
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+	network.SendCustomResponse[any](
+		ctx,
+		failureResCode,
+		http.StatusTooManyRequests,
+		"too many failed attempts, try again later",
+		nil,
+	)
+}
@@ -0,0 +1,168 @@
+package lockout
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/lockout/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve/v2/redis"
+)
+
+// LockedError is returned while the email or the ip is locked out from signing in
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return "too many failed sign in attempts, retry after " + e.RetryAfter.String()
+}
+
+type Service interface {
+	Check(email string, ip string) error
+	RegisterFailure(email string, ip string) error
+	RegisterSuccess(email string) error
+	ClearLockout(lockoutClearDto *dto.LockoutClear) error
+}
+
+type service struct {
+	store            redis.Store
+	emailMaxAttempts int64
+	ipMaxAttempts    int64
+	window           time.Duration
+	baseLockout      time.Duration
+	maxLockout       time.Duration
+}
+
+func NewService(env *config.Env, store redis.Store) Service {
+	return &service{
+		store:            store,
+		emailMaxAttempts: int64(env.LockoutEmailMaxAttempts),
+		ipMaxAttempts:    int64(env.LockoutIPMaxAttempts),
+		window:           time.Duration(env.LockoutWindowSec) * time.Second,
+		baseLockout:      time.Duration(env.LockoutBaseSec) * time.Second,
+		maxLockout:       time.Duration(env.LockoutMaxSec) * time.Second,
+	}
+}
+
+// Backoff doubles the lockout for every failure past the allowed attempts
+func Backoff(failures int64, maxAttempts int64, base time.Duration, max time.Duration) time.Duration {
+	if failures < maxAttempts {
+		return 0
+	}
+
+	lockout := base
+	for i := maxAttempts; i < failures && lockout < max; i++ {
+		lockout *= 2
+	}
+
+	if lockout > max {
+		return max
+	}
+	return lockout
+}
+
+func emailSubject(email string) string {
+	return "email_" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipSubject(ip string) string {
+	return "ip_" + ip
+}
+
+func failuresKey(subject string) string {
+	return "lockout_failures_" + subject
+}
+
+func lockKey(subject string) string {
+	return "lockout_lock_" + subject
+}
+
+func (s *service) Check(email string, ip string) error {
+	ctx := context.Background()
+
+	subjects := []string{emailSubject(email)}
+	if ip != "" {
+		subjects = append(subjects, ipSubject(ip))
+	}
+
+	var retryAfter time.Duration
+	for _, subject := range subjects {
+		ttl, err := s.store.GetInstance().PTTL(ctx, lockKey(subject)).Result()
+		if err != nil {
+			return err
+		}
+		if ttl > retryAfter {
+			retryAfter = ttl
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LockedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// RegisterFailure counts the failure for both the email and the ip,
+// the email is counted even when no such user exists so that a lockout reveals nothing
+func (s *service) RegisterFailure(email string, ip string) error {
+	err := s.registerFailure(emailSubject(email), s.emailMaxAttempts)
+	if err != nil {
+		return err
+	}
+
+	if ip == "" {
+		return nil
+	}
+
+	return s.registerFailure(ipSubject(ip), s.ipMaxAttempts)
+}
+
+func (s *service) registerFailure(subject string, maxAttempts int64) error {
+	ctx := context.Background()
+	client := s.store.GetInstance()
+
+	key := failuresKey(subject)
+
+	failures, err := client.Incr(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	err = client.Expire(ctx, key, s.window).Err()
+	if err != nil {
+		return err
+	}
+
+	lockout := Backoff(failures, maxAttempts, s.baseLockout, s.maxLockout)
+	if lockout == 0 {
+		return nil
+	}
+
+	return client.Set(ctx, lockKey(subject), failures, lockout).Err()
+}
+
+// RegisterSuccess forgets the failures of the email, the ip keeps its count
+// since a single client may be guessing across many accounts
+func (s *service) RegisterSuccess(email string) error {
+	ctx := context.Background()
+	return s.store.GetInstance().Del(ctx, failuresKey(emailSubject(email))).Err()
+}
+
+func (s *service) ClearLockout(lockoutClearDto *dto.LockoutClear) error {
+	ctx := context.Background()
+
+	keys := make([]string, 0)
+	if lockoutClearDto.Email != "" {
+		subject := emailSubject(lockoutClearDto.Email)
+		keys = append(keys, failuresKey(subject), lockKey(subject))
+	}
+	if lockoutClearDto.IP != "" {
+		subject := ipSubject(lockoutClearDto.IP)
+		keys = append(keys, failuresKey(subject), lockKey(subject))
+	}
+
+	return s.store.GetInstance().Del(ctx, keys...).Err()
+}
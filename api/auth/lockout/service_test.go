@@ -0,0 +1,26 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	base := 30 * time.Second
+	max := time.Hour
+
+	assert.Equal(t, time.Duration(0), Backoff(0, 5, base, max))
+	assert.Equal(t, time.Duration(0), Backoff(4, 5, base, max))
+	assert.Equal(t, 30*time.Second, Backoff(5, 5, base, max))
+	assert.Equal(t, 60*time.Second, Backoff(6, 5, base, max))
+	assert.Equal(t, 120*time.Second, Backoff(7, 5, base, max))
+	assert.Equal(t, max, Backoff(12, 5, base, max))
+	assert.Equal(t, max, Backoff(1000, 5, base, max))
+}
+
+func TestLockedError(t *testing.T) {
+	err := &LockedError{RetryAfter: time.Minute}
+	assert.Contains(t, err.Error(), "1m0s")
+}
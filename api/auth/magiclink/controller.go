@@ -0,0 +1,89 @@
+package magiclink
+
+import (
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/magiclink/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+	cookies common.TokenCookies
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+	cookies common.TokenCookies,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/auth/signin/link", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+		cookies:        cookies,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(common.KeyPermission(model.GeneralPermission))
+	group.POST("", c.requestLinkHandler)
+	group.GET("/verify", c.verifyLinkQueryHandler)
+	group.POST("/verify", c.verifyLinkHandler)
+}
+
+func (c *controller) requestLinkHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.MagicLinkRequest](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	err = c.service.RequestLink(body)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "if the email is registered, a sign in link has been sent")
+}
+
+func (c *controller) verifyLinkQueryHandler(ctx *gin.Context) {
+	query, err := network.ReqQuery[dto.MagicLinkVerify](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	c.signIn(ctx, query)
+}
+
+func (c *controller) verifyLinkHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.MagicLinkVerify](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	c.signIn(ctx, body)
+}
+
+func (c *controller) signIn(ctx *gin.Context, verifyDto *dto.MagicLinkVerify) {
+	data, challenge, err := c.service.SignIn(verifyDto, authDto.NewClientInfo(ctx.Request.UserAgent(), ctx.ClientIP()))
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	if challenge != nil {
+		network.SendSuccessDataResponse(ctx, "mfa required", challenge)
+		return
+	}
+
+	common.SendUserAuth(ctx, c.cookies, data)
+}
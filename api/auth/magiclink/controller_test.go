@@ -0,0 +1,94 @@
+package magiclink
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/magiclink/dto"
+	mfaDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMagicLinkController_RequestUnknownEmail(t *testing.T) {
+	service := new(MockService)
+	service.On("RequestLink", &dto.MagicLinkRequest{Email: "unknown@abc.com"}).Return(nil)
+
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "POST", "/auth/signin/link", `{"email":"unknown@abc.com"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"if the email is registered, a sign in link has been sent"`)
+}
+
+func TestMagicLinkController_RequestInvalidEmail(t *testing.T) {
+	service := new(MockService)
+
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "POST", "/auth/signin/link", `{"email":"abc"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "RequestLink", mock.Anything)
+}
+
+func TestMagicLinkController_VerifyQuerySuccess(t *testing.T) {
+	user := &userModel.User{
+		ID:    uuid.New(),
+		Email: "learner@abc.com",
+		Name:  "learner",
+		Roles: []*userModel.Role{{ID: uuid.New(), Code: userModel.RoleCodeLearner, Status: true}},
+	}
+	userAuth := authDto.NewUserAuth(user, authDto.NewTokens("access", "refresh"))
+
+	service := new(MockService)
+	service.On("SignIn", &dto.MagicLinkVerify{Token: "link-token"}, mock.Anything).Return(userAuth, nil, nil)
+
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "GET", "/auth/signin/link/verify?token=link-token", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"accessToken":"access"`)
+	service.AssertExpectations(t)
+}
+
+func TestMagicLinkController_VerifyMfaRequired(t *testing.T) {
+	challenge := mfaDto.NewMfaChallenge("challenge-token", time.Now().Add(5*time.Minute))
+
+	service := new(MockService)
+	service.On("SignIn", &dto.MagicLinkVerify{Token: "link-token"}, mock.Anything).Return(nil, challenge, nil)
+
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "POST", "/auth/signin/link/verify", `{"token":"link-token"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"mfa required"`)
+}
+
+func TestMagicLinkController_VerifyInvalidLink(t *testing.T) {
+	service := new(MockService)
+	service.On("SignIn", mock.Anything, mock.Anything).
+		Return(nil, nil, network.NewUnauthorizedError("invalid or expired link", nil))
+
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "POST", "/auth/signin/link/verify", `{"token":"used-token"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"invalid or expired link"`)
+}
+
+func TestMagicLinkController_VerifyMissingToken(t *testing.T) {
+	service := new(MockService)
+
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "GET", "/auth/signin/link/verify", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "SignIn", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,5 @@
+package dto
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required" validate:"required,email"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type MagicLinkVerify struct {
+	Token string `json:"token" form:"token" binding:"required" validate:"required"`
+}
@@ -0,0 +1,33 @@
+package magiclink
+
+import (
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/magiclink/dto"
+	mfaDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) RequestLink(requestDto *dto.MagicLinkRequest) error {
+	args := m.Called(requestDto)
+	return args.Error(0)
+}
+
+func (m *MockService) SignIn(
+	verifyDto *dto.MagicLinkVerify,
+	clientInfo *authDto.ClientInfo,
+) (*authDto.UserAuth, *mfaDto.MfaChallenge, error) {
+	args := m.Called(verifyDto, clientInfo)
+	var data *authDto.UserAuth
+	if args.Get(0) != nil {
+		data = args.Get(0).(*authDto.UserAuth)
+	}
+	var challenge *mfaDto.MfaChallenge
+	if args.Get(1) != nil {
+		challenge = args.Get(1).(*mfaDto.MfaChallenge)
+	}
+	return data, challenge, args.Error(2)
+}
@@ -0,0 +1,149 @@
+package magiclink
+
+import (
+	"log"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/magiclink/dto"
+	mfaDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/verification"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve-example-api-server-postgres/mailer"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// the audience keeps a link token from ever passing as an access token and the other way around
+const linkAudience = "magic_link"
+
+type Service interface {
+	RequestLink(requestDto *dto.MagicLinkRequest) error
+	SignIn(verifyDto *dto.MagicLinkVerify, clientInfo *authDto.ClientInfo) (*authDto.UserAuth, *mfaDto.MfaChallenge, error)
+}
+
+type service struct {
+	authService         auth.Service
+	userService         user.Service
+	verificationService verification.Service
+	mailer              mailer.Sender
+	tokenIssuer         string
+	// link
+	linkUrl        string
+	linkValidity   time.Duration
+	resendCooldown time.Duration
+}
+
+func NewService(
+	env *config.Env,
+	authService auth.Service,
+	userService user.Service,
+	verificationService verification.Service,
+	mailer mailer.Sender,
+) Service {
+	return &service{
+		authService:         authService,
+		userService:         userService,
+		verificationService: verificationService,
+		mailer:              mailer,
+		tokenIssuer:         env.TokenIssuer,
+		linkUrl:             env.MagicLinkUrl,
+		linkValidity:        time.Duration(env.MagicLinkValiditySec) * time.Second,
+		resendCooldown:      time.Duration(env.MagicLinkResendSec) * time.Second,
+	}
+}
+
+// RequestLink mails a signed link token whose id is a single use user token, so a new link
+// replaces the previous one. Unknown emails are not reported so that accounts can't be discovered
+func (s *service) RequestLink(requestDto *dto.MagicLinkRequest) error {
+	user, err := s.userService.FetchUserByEmail(requestDto.Email)
+	if err != nil {
+		return nil
+	}
+
+	lastSentAt, err := s.verificationService.LastTokenIssuedAt(user, model.TokenPurposeMagicLink)
+	if err != nil {
+		return err
+	}
+
+	if lastSentAt != nil && time.Since(*lastSentAt) < s.resendCooldown {
+		return nil
+	}
+
+	tokenId, err := s.verificationService.IssueToken(user, model.TokenPurposeMagicLink, s.linkValidity)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	token, err := s.authService.SignToken(jwt.RegisteredClaims{
+		Issuer:    s.tokenIssuer,
+		Subject:   user.ID.String(),
+		Audience:  []string{linkAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(s.linkValidity)),
+		ID:        tokenId,
+	})
+	if err != nil {
+		return err
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Your sign in link",
+		Body: "Hi " + user.Name + ",\n\n" +
+			"Open the link below to sign in:\n" +
+			s.linkUrl + "?token=" + url.QueryEscape(token) + "\n\n" +
+			"The link expires in " + s.linkValidity.String() + " and can be used once. " +
+			"If you did not request it, you can ignore this email.",
+	}
+
+	return s.mailer.Send(msg)
+}
+
+// SignIn redeems the link token and signs the user in like with a password,
+// opening the link also proves the ownership of the email
+func (s *service) SignIn(
+	verifyDto *dto.MagicLinkVerify,
+	clientInfo *authDto.ClientInfo,
+) (*authDto.UserAuth, *mfaDto.MfaChallenge, error) {
+	claims, err := s.authService.VerifyToken(verifyDto.Token)
+	if err != nil {
+		return nil, nil, network.NewUnauthorizedError("invalid or expired link", err)
+	}
+
+	if claims.Issuer != s.tokenIssuer || !slices.Contains(claims.Audience, linkAudience) || claims.ID == "" {
+		return nil, nil, network.NewUnauthorizedError("invalid or expired link", nil)
+	}
+
+	userToken, err := s.verificationService.ConsumeToken(claims.ID, model.TokenPurposeMagicLink)
+	if err != nil {
+		return nil, nil, network.NewUnauthorizedError("invalid or expired link", err)
+	}
+
+	if userToken.UserID.String() != claims.Subject {
+		return nil, nil, network.NewUnauthorizedError("invalid or expired link", nil)
+	}
+
+	user, err := s.userService.FetchUserById(userToken.UserID)
+	if err != nil {
+		return nil, nil, network.NewUnauthorizedError("user is not available", err)
+	}
+
+	if !user.Verified {
+		err = s.userService.MarkUserVerified(user.ID)
+		if err != nil {
+			log.Printf("email of %s could not be marked verified after magic link sign in: %v", user.ID, err)
+		} else {
+			user.Verified = true
+		}
+	}
+
+	return s.authService.SignInUser(user, clientInfo)
+}
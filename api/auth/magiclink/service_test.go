@@ -0,0 +1,171 @@
+package magiclink
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/magiclink/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/verification"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve-example-api-server-postgres/mailer"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testDeps struct {
+	authService         *auth.MockService
+	userService         *user.MockService
+	verificationService *verification.MockService
+	outbox              *mailer.MemoryOutbox
+	service             Service
+}
+
+func newTestService() *testDeps {
+	d := &testDeps{
+		authService:         new(auth.MockService),
+		userService:         new(user.MockService),
+		verificationService: new(verification.MockService),
+		outbox:              mailer.NewMemoryOutbox(),
+	}
+	env := &config.Env{
+		TokenIssuer:          "api.goserve.afteracademy.com",
+		MagicLinkUrl:         "https://goserve.afteracademy.com/signin/link",
+		MagicLinkValiditySec: 900,
+		MagicLinkResendSec:   60,
+	}
+	d.service = NewService(env, d.authService, d.userService, d.verificationService, d.outbox)
+	return d
+}
+
+func linkClaims(userId uuid.UUID, tokenId string) *model.TokenClaims {
+	return &model.TokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:   "api.goserve.afteracademy.com",
+			Subject:  userId.String(),
+			Audience: []string{linkAudience},
+			ID:       tokenId,
+		},
+	}
+}
+
+func assertNetworkError(t *testing.T, err error, status int) {
+	apiErr, ok := err.(network.ApiError)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, status, apiErr.GetCode())
+	}
+}
+
+func TestMagicLinkService_RequestLinkSendsSignedToken(t *testing.T) {
+	d := newTestService()
+	user := &userModel.User{ID: uuid.New(), Email: "learner@abc.com", Name: "learner"}
+
+	d.userService.On("FetchUserByEmail", user.Email).Return(user, nil)
+	d.verificationService.On("LastTokenIssuedAt", user, model.TokenPurposeMagicLink).Return(nil, nil)
+	d.verificationService.On("IssueToken", user, model.TokenPurposeMagicLink, 900*time.Second).Return("token-id", nil)
+	d.authService.On("SignToken", mock.MatchedBy(func(claims jwt.RegisteredClaims) bool {
+		return claims.Subject == user.ID.String() &&
+			claims.ID == "token-id" &&
+			len(claims.Audience) == 1 && claims.Audience[0] == linkAudience &&
+			claims.ExpiresAt != nil
+	})).Return("signed-link-token", nil)
+
+	err := d.service.RequestLink(&dto.MagicLinkRequest{Email: user.Email})
+	assert.NoError(t, err)
+
+	msg := d.outbox.LastTo(user.Email)
+	assert.NotNil(t, msg)
+	assert.True(t, strings.Contains(msg.Body, "https://goserve.afteracademy.com/signin/link?token=signed-link-token"))
+	d.authService.AssertExpectations(t)
+}
+
+func TestMagicLinkService_RequestLinkUnknownEmail(t *testing.T) {
+	d := newTestService()
+	d.userService.On("FetchUserByEmail", "unknown@abc.com").Return(nil, network.NewNotFoundError("user not found", nil))
+
+	err := d.service.RequestLink(&dto.MagicLinkRequest{Email: "unknown@abc.com"})
+	assert.NoError(t, err)
+	assert.Empty(t, d.outbox.Messages())
+}
+
+func TestMagicLinkService_RequestLinkCooldown(t *testing.T) {
+	d := newTestService()
+	user := &userModel.User{ID: uuid.New(), Email: "learner@abc.com", Name: "learner"}
+	lastSentAt := time.Now().Add(-10 * time.Second)
+
+	d.userService.On("FetchUserByEmail", user.Email).Return(user, nil)
+	d.verificationService.On("LastTokenIssuedAt", user, model.TokenPurposeMagicLink).Return(&lastSentAt, nil)
+
+	err := d.service.RequestLink(&dto.MagicLinkRequest{Email: user.Email})
+	assert.NoError(t, err)
+	assert.Empty(t, d.outbox.Messages())
+	d.verificationService.AssertNotCalled(t, "IssueToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMagicLinkService_SignInVerifiesEmail(t *testing.T) {
+	d := newTestService()
+	user := &userModel.User{ID: uuid.New(), Email: "learner@abc.com", Name: "learner"}
+	clientInfo := authDto.NewClientInfo("agent", "127.0.0.1")
+	userAuth := authDto.NewUserAuth(user, authDto.NewTokens("access", "refresh"))
+
+	d.authService.On("VerifyToken", "signed-link-token").Return(linkClaims(user.ID, "token-id"), nil)
+	d.verificationService.On("ConsumeToken", "token-id", model.TokenPurposeMagicLink).
+		Return(&model.UserToken{UserID: user.ID, Purpose: model.TokenPurposeMagicLink}, nil)
+	d.userService.On("FetchUserById", user.ID).Return(user, nil)
+	d.userService.On("MarkUserVerified", user.ID).Return(nil)
+	d.authService.On("SignInUser", user, clientInfo).Return(userAuth, nil, nil)
+
+	data, challenge, err := d.service.SignIn(&dto.MagicLinkVerify{Token: "signed-link-token"}, clientInfo)
+	assert.NoError(t, err)
+	assert.Nil(t, challenge)
+	assert.Equal(t, userAuth, data)
+	assert.True(t, user.Verified)
+	d.userService.AssertExpectations(t)
+}
+
+func TestMagicLinkService_SignInRejectsAccessToken(t *testing.T) {
+	d := newTestService()
+	claims := linkClaims(uuid.New(), "keystore-key")
+	claims.Audience = []string{"goserve.afteracademy.com"}
+
+	d.authService.On("VerifyToken", "access-token").Return(claims, nil)
+
+	_, _, err := d.service.SignIn(&dto.MagicLinkVerify{Token: "access-token"}, nil)
+	assertNetworkError(t, err, http.StatusUnauthorized)
+	d.verificationService.AssertNotCalled(t, "ConsumeToken", mock.Anything, mock.Anything)
+}
+
+func TestMagicLinkService_SignInUsedLink(t *testing.T) {
+	d := newTestService()
+	userId := uuid.New()
+
+	d.authService.On("VerifyToken", "signed-link-token").Return(linkClaims(userId, "token-id"), nil)
+	d.verificationService.On("ConsumeToken", "token-id", model.TokenPurposeMagicLink).
+		Return(nil, network.NewBadRequestError("invalid or expired token", nil))
+
+	_, _, err := d.service.SignIn(&dto.MagicLinkVerify{Token: "signed-link-token"}, nil)
+	assertNetworkError(t, err, http.StatusUnauthorized)
+	d.authService.AssertNotCalled(t, "SignInUser", mock.Anything, mock.Anything)
+}
+
+func TestMagicLinkService_SignInSubjectMismatch(t *testing.T) {
+	d := newTestService()
+
+	d.authService.On("VerifyToken", "signed-link-token").Return(linkClaims(uuid.New(), "token-id"), nil)
+	d.verificationService.On("ConsumeToken", "token-id", model.TokenPurposeMagicLink).
+		Return(&model.UserToken{UserID: uuid.New(), Purpose: model.TokenPurposeMagicLink}, nil)
+
+	_, _, err := d.service.SignIn(&dto.MagicLinkVerify{Token: "signed-link-token"}, nil)
+	assertNetworkError(t, err, http.StatusUnauthorized)
+	d.userService.AssertNotCalled(t, "FetchUserById", mock.Anything)
+}
@@ -0,0 +1,101 @@
+package mfa
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/auth/mfa", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(common.KeyPermission(model.GeneralPermission), c.Authentication(), common.RejectImpersonation())
+	group.POST("/enroll", c.enrollHandler)
+	group.POST("/confirm", c.confirmHandler)
+	group.POST("/recovery-codes", c.regenerateRecoveryCodesHandler)
+	group.DELETE("", c.disableHandler)
+}
+
+func (c *controller) enrollHandler(ctx *gin.Context) {
+	user := c.MustGetUser(ctx)
+
+	enrollment, err := c.service.Enroll(user)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", enrollment)
+}
+
+func (c *controller) confirmHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.MfaCode](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	codes, err := c.service.Confirm(body, user)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "mfa enabled successfully", codes)
+}
+
+func (c *controller) regenerateRecoveryCodesHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.MfaCode](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	codes, err := c.service.RegenerateRecoveryCodes(body, user)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", codes)
+}
+
+func (c *controller) disableHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.MfaCode](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	err = c.service.Disable(body, user)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "mfa disabled successfully")
+}
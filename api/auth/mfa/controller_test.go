@@ -0,0 +1,78 @@
+package mfa
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockAuthentication(user *userModel.User) *network.MockAuthenticationProvider {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, user)
+		ctx.Next()
+	}))
+	return mockAuthProvider
+}
+
+func TestMfaController_EnrollSuccess(t *testing.T) {
+	user := &userModel.User{ID: uuid.New(), Email: "test@abc.com"}
+
+	service := new(MockService)
+	service.On("Enroll", user).Return(dto.NewMfaEnrollment("SECRET", "otpauth://totp/GoServe:test@abc.com?secret=SECRET"), nil)
+
+	c := NewController(mockAuthentication(user), new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "POST", "/auth/mfa/enroll", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"secret":"SECRET"`)
+	service.AssertExpectations(t)
+}
+
+func TestMfaController_ConfirmBadRequest(t *testing.T) {
+	user := &userModel.User{ID: uuid.New()}
+
+	service := new(MockService)
+
+	c := NewController(mockAuthentication(user), new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "POST", "/auth/mfa/confirm", "{}", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"code is required"`)
+}
+
+func TestMfaController_ConfirmSuccess(t *testing.T) {
+	user := &userModel.User{ID: uuid.New()}
+	codes := dto.NewMfaRecoveryCodes([]string{"1a2b3-4c5d6"})
+
+	service := new(MockService)
+	service.On("Confirm", &dto.MfaCode{Code: "123456"}, user).Return(codes, nil)
+
+	c := NewController(mockAuthentication(user), new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "POST", "/auth/mfa/confirm", `{"code":"123456"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"mfa enabled successfully"`)
+	assert.Contains(t, rr.Body.String(), `"1a2b3-4c5d6"`)
+}
+
+func TestMfaController_DisableForbidden(t *testing.T) {
+	user := &userModel.User{ID: uuid.New(), MfaEnabled: true}
+
+	service := new(MockService)
+	service.On("Disable", &dto.MfaCode{Code: "123456"}, user).
+		Return(network.NewForbiddenError("permission denied: mfa is required for your role", nil))
+
+	c := NewController(mockAuthentication(user), new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "DELETE", "/auth/mfa", `{"code":"123456"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: mfa is required for your role"`)
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// MfaChallenge is returned by the sign in instead of the tokens when the user has mfa enabled
+type MfaChallenge struct {
+	ChallengeToken string    `json:"challengeToken" validate:"required"`
+	ExpiresAt      time.Time `json:"expiresAt" validate:"required"`
+}
+
+func NewMfaChallenge(challengeToken string, expiresAt time.Time) *MfaChallenge {
+	return &MfaChallenge{
+		ChallengeToken: challengeToken,
+		ExpiresAt:      expiresAt,
+	}
+}
@@ -0,0 +1,6 @@
+package dto
+
+// MfaCode is a totp code of the authenticator app or a recovery code
+type MfaCode struct {
+	Code string `json:"code" binding:"required" validate:"required,min=6,max=20"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+type MfaEnrollment struct {
+	Secret     string `json:"secret" validate:"required"`
+	OtpauthURI string `json:"otpauthUri" validate:"required"`
+}
+
+func NewMfaEnrollment(secret string, otpauthURI string) *MfaEnrollment {
+	return &MfaEnrollment{
+		Secret:     secret,
+		OtpauthURI: otpauthURI,
+	}
+}
@@ -0,0 +1,12 @@
+package dto
+
+// MfaRecoveryCodes are shown only once, the server keeps their hashes
+type MfaRecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes" validate:"required,dive,required"`
+}
+
+func NewMfaRecoveryCodes(codes []string) *MfaRecoveryCodes {
+	return &MfaRecoveryCodes{
+		RecoveryCodes: codes,
+	}
+}
@@ -0,0 +1,6 @@
+package dto
+
+type MfaVerify struct {
+	ChallengeToken string `json:"challengeToken" binding:"required" validate:"required"`
+	Code           string `json:"code" binding:"required" validate:"required,min=6,max=20"`
+}
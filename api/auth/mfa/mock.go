@@ -0,0 +1,69 @@
+package mfa
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) Enroll(user *userModel.User) (*dto.MfaEnrollment, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MfaEnrollment), args.Error(1)
+}
+
+func (m *MockService) Confirm(codeDto *dto.MfaCode, user *userModel.User) (*dto.MfaRecoveryCodes, error) {
+	args := m.Called(codeDto, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MfaRecoveryCodes), args.Error(1)
+}
+
+func (m *MockService) Disable(codeDto *dto.MfaCode, user *userModel.User) error {
+	args := m.Called(codeDto, user)
+	return args.Error(0)
+}
+
+func (m *MockService) RegenerateRecoveryCodes(codeDto *dto.MfaCode, user *userModel.User) (*dto.MfaRecoveryCodes, error) {
+	args := m.Called(codeDto, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MfaRecoveryCodes), args.Error(1)
+}
+
+func (m *MockService) VerifyCode(userId uuid.UUID, code string) error {
+	args := m.Called(userId, code)
+	return args.Error(0)
+}
+
+func (m *MockService) CreateChallenge(user *userModel.User) (*dto.MfaChallenge, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MfaChallenge), args.Error(1)
+}
+
+func (m *MockService) VerifyChallenge(verifyDto *dto.MfaVerify) (uuid.UUID, error) {
+	args := m.Called(verifyDto)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockService) IsRequired(user *userModel.User) bool {
+	args := m.Called(user)
+	return args.Bool(0)
+}
+
+func (m *MockService) RequiredRoles() []userModel.RoleCode {
+	args := m.Called()
+	return args.Get(0).([]userModel.RoleCode)
+}
@@ -0,0 +1,31 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+const recoveryCodeCount = 10
+
+// GenerateRecoveryCodes creates codes formatted like 1a2b3-4c5d6 for readability
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		raw := make([]byte, 5)
+		_, err := rand.Read(raw)
+		if err != nil {
+			return nil, err
+		}
+		code := hex.EncodeToString(raw)
+		codes = append(codes, code[:5]+"-"+code[5:])
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode makes the comparison tolerant to case and separators
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
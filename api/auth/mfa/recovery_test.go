@@ -0,0 +1,25 @@
+package mfa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes()
+	assert.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Regexp(t, `^[0-9a-f]{5}-[0-9a-f]{5}$`, code)
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	assert.Equal(t, "1a2b34c5d6", NormalizeRecoveryCode("1A2B3-4C5D6"))
+	assert.Equal(t, "1a2b34c5d6", NormalizeRecoveryCode(" 1a2b3 4c5d6 "))
+}
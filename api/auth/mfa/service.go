@@ -0,0 +1,454 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/cache"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve-example-api-server-postgres/utils"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/afteracademy/goserve/v2/redis"
+	"github.com/afteracademy/goserve/v2/utility"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// a challenge is dropped after these many wrong codes, the password has to be entered again
+const maxChallengeAttempts = 5
+
+type Service interface {
+	Enroll(user *userModel.User) (*dto.MfaEnrollment, error)
+	Confirm(codeDto *dto.MfaCode, user *userModel.User) (*dto.MfaRecoveryCodes, error)
+	Disable(codeDto *dto.MfaCode, user *userModel.User) error
+	RegenerateRecoveryCodes(codeDto *dto.MfaCode, user *userModel.User) (*dto.MfaRecoveryCodes, error)
+	VerifyCode(userId uuid.UUID, code string) error
+	CreateChallenge(user *userModel.User) (*dto.MfaChallenge, error)
+	VerifyChallenge(verifyDto *dto.MfaVerify) (uuid.UUID, error)
+	IsRequired(user *userModel.User) bool
+	RequiredRoles() []userModel.RoleCode
+}
+
+type service struct {
+	db                postgres.Database
+	store             redis.Store
+	issuer            string
+	challengeValidity time.Duration
+	requiredRoles     []userModel.RoleCode
+	authCache         cache.Service
+}
+
+func NewService(db postgres.Database, env *config.Env, store redis.Store, authCache cache.Service) Service {
+	requiredRoles := make([]userModel.RoleCode, 0)
+	for _, code := range strings.Split(env.MfaRequiredRoles, ",") {
+		code = strings.TrimSpace(code)
+		if code != "" {
+			requiredRoles = append(requiredRoles, userModel.RoleCode(code))
+		}
+	}
+
+	return &service{
+		db:                db,
+		store:             store,
+		issuer:            env.MfaIssuer,
+		challengeValidity: time.Duration(env.MfaChallengeValiditySec) * time.Second,
+		requiredRoles:     requiredRoles,
+		authCache:         authCache,
+	}
+}
+
+// Enroll starts a pending enrollment, a new call replaces the secret until it is confirmed
+func (s *service) Enroll(user *userModel.User) (*dto.MfaEnrollment, error) {
+	if user.MfaEnabled {
+		return nil, network.NewBadRequestError("mfa already enabled", nil)
+	}
+
+	ctx := context.Background()
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO user_mfa (
+			user_id,
+			secret
+		)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET
+			secret = EXCLUDED.secret,
+			last_used_step = 0,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE user_mfa.confirmed_at IS NULL
+	`
+
+	tag, err := s.db.Pool().Exec(ctx, query, user.ID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return nil, network.NewBadRequestError("mfa already enabled", nil)
+	}
+
+	return dto.NewMfaEnrollment(secret, ProvisioningURI(s.issuer, user.Email, secret)), nil
+}
+
+func (s *service) Confirm(codeDto *dto.MfaCode, user *userModel.User) (*dto.MfaRecoveryCodes, error) {
+	ctx := context.Background()
+
+	userMfa, err := s.findUserMfa(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, network.NewBadRequestError("mfa enrollment not started", err)
+		}
+		return nil, err
+	}
+
+	if userMfa.ConfirmedAt != nil {
+		return nil, network.NewBadRequestError("mfa already enabled", nil)
+	}
+
+	step, valid := ValidateTOTP(userMfa.Secret, codeDto.Code, time.Now())
+	if !valid {
+		return nil, network.NewBadRequestError("invalid mfa code", nil)
+	}
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	confirmQuery := `
+		UPDATE user_mfa
+		SET
+			confirmed_at = CURRENT_TIMESTAMP,
+			last_used_step = $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1
+		  AND confirmed_at IS NULL
+	`
+
+	tag, err := tx.Exec(ctx, confirmQuery, user.ID, step)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return nil, network.NewBadRequestError("mfa already enabled", nil)
+	}
+
+	err = s.setUserMfaEnabled(ctx, tx, user.ID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	s.authCache.ClearUser(user.ID)
+	return dto.NewMfaRecoveryCodes(codes), nil
+}
+
+func (s *service) Disable(codeDto *dto.MfaCode, user *userModel.User) error {
+	if !user.MfaEnabled {
+		return network.NewBadRequestError("mfa is not enabled", nil)
+	}
+
+	if s.IsRequired(user) {
+		return network.NewForbiddenError("permission denied: mfa is required for your role", nil)
+	}
+
+	err := s.VerifyCode(user.ID, codeDto.Code)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, user.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, user.ID)
+	if err != nil {
+		return err
+	}
+
+	err = s.setUserMfaEnabled(ctx, tx, user.ID, false)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	s.authCache.ClearUser(user.ID)
+	return nil
+}
+
+func (s *service) RegenerateRecoveryCodes(codeDto *dto.MfaCode, user *userModel.User) (*dto.MfaRecoveryCodes, error) {
+	if !user.MfaEnabled {
+		return nil, network.NewBadRequestError("mfa is not enabled", nil)
+	}
+
+	err := s.VerifyCode(user.ID, codeDto.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	codes, err := s.replaceRecoveryCodes(ctx, tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return dto.NewMfaRecoveryCodes(codes), nil
+}
+
+// VerifyCode accepts a totp code once or an unused recovery code
+func (s *service) VerifyCode(userId uuid.UUID, code string) error {
+	ctx := context.Background()
+
+	userMfa, err := s.findUserMfa(ctx, userId)
+	if err != nil || userMfa.ConfirmedAt == nil {
+		return network.NewUnauthorizedError("invalid mfa code", err)
+	}
+
+	step, valid := ValidateTOTP(userMfa.Secret, code, time.Now())
+	if valid {
+		// a step can only be used once so an observed code can not be replayed
+		query := `
+			UPDATE user_mfa
+			SET
+				last_used_step = $2,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE user_id = $1
+			  AND last_used_step < $2
+		`
+
+		tag, err := s.db.Pool().Exec(ctx, query, userId, step)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() > 0 {
+			return nil
+		}
+
+		return network.NewUnauthorizedError("invalid mfa code", nil)
+	}
+
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1
+		  AND code_hash = $2
+		  AND used_at IS NULL
+	`
+
+	tag, err := s.db.Pool().Exec(ctx, query, userId, utils.HashToken(NormalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return network.NewUnauthorizedError("invalid mfa code", nil)
+	}
+
+	return nil
+}
+
+func challengeKey(token string) string {
+	return "mfa_challenge_" + utils.HashToken(token)
+}
+
+func challengeAttemptsKey(token string) string {
+	return "mfa_challenge_attempts_" + utils.HashToken(token)
+}
+
+func (s *service) CreateChallenge(user *userModel.User) (*dto.MfaChallenge, error) {
+	ctx := context.Background()
+
+	token, err := utility.GenerateRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.GetInstance().Set(ctx, challengeKey(token), user.ID.String(), s.challengeValidity).Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewMfaChallenge(token, time.Now().Add(s.challengeValidity)), nil
+}
+
+// VerifyChallenge consumes the challenge on a valid code and returns the user to sign in
+func (s *service) VerifyChallenge(verifyDto *dto.MfaVerify) (uuid.UUID, error) {
+	ctx := context.Background()
+	client := s.store.GetInstance()
+
+	key := challengeKey(verifyDto.ChallengeToken)
+
+	value, err := client.Get(ctx, key).Result()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return uuid.Nil, network.NewUnauthorizedError("invalid or expired mfa challenge", nil)
+		}
+		return uuid.Nil, err
+	}
+
+	userId, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, network.NewUnauthorizedError("invalid or expired mfa challenge", err)
+	}
+
+	err = s.VerifyCode(userId, verifyDto.Code)
+	if err != nil {
+		attemptsKey := challengeAttemptsKey(verifyDto.ChallengeToken)
+		attempts, incrErr := client.Incr(ctx, attemptsKey).Result()
+		if incrErr == nil {
+			client.Expire(ctx, attemptsKey, s.challengeValidity)
+		}
+		if incrErr != nil || attempts >= maxChallengeAttempts {
+			client.Del(ctx, key, attemptsKey)
+		}
+		return uuid.Nil, err
+	}
+
+	// only the request deleting the challenge may sign in with it
+	deleted, err := client.Del(ctx, key).Result()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if deleted == 0 {
+		return uuid.Nil, network.NewUnauthorizedError("invalid or expired mfa challenge", nil)
+	}
+
+	return userId, nil
+}
+
+func (s *service) IsRequired(user *userModel.User) bool {
+	for _, role := range user.Roles {
+		for _, code := range s.requiredRoles {
+			if role.Code == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *service) RequiredRoles() []userModel.RoleCode {
+	return s.requiredRoles
+}
+
+func (s *service) findUserMfa(ctx context.Context, userId uuid.UUID) (*model.UserMfa, error) {
+	query := `
+		SELECT
+			user_id,
+			secret,
+			last_used_step,
+			confirmed_at,
+			created_at,
+			updated_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+
+	var userMfa model.UserMfa
+
+	err := s.db.Pool().QueryRow(ctx, query, userId).Scan(
+		&userMfa.UserID,
+		&userMfa.Secret,
+		&userMfa.LastUsedStep,
+		&userMfa.ConfirmedAt,
+		&userMfa.CreatedAt,
+		&userMfa.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &userMfa, nil
+}
+
+func (s *service) setUserMfaEnabled(ctx context.Context, tx pgx.Tx, userId uuid.UUID, enabled bool) error {
+	query := `
+		UPDATE users
+		SET
+			mfa_enabled = $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	_, err := tx.Exec(ctx, query, userId, enabled)
+	return err
+}
+
+// replaceRecoveryCodes invalidates the previous codes and returns the new raw codes
+func (s *service) replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userId uuid.UUID) ([]string, error) {
+	codes, err := GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	insertQuery := `
+		INSERT INTO user_recovery_codes (
+			user_id,
+			code_hash
+		)
+		VALUES ($1, $2)
+	`
+
+	for _, code := range codes {
+		_, err = tx.Exec(ctx, insertQuery, userId, utils.HashToken(NormalizeRecoveryCode(code)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
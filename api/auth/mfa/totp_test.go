@@ -0,0 +1,71 @@
+package mfa
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// test vectors of RFC 6238 appendix B for SHA1
+func TestHOTP_RFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	vectors := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+
+	for unix, code := range vectors {
+		assert.Equal(t, code, HOTP(key, uint64(TimeStep(time.Unix(unix, 0))), 8))
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	key := []byte("12345678901234567890")
+	secret := secretEncoding.EncodeToString(key)
+	now := time.Unix(1111111111, 0)
+
+	code := HOTP(key, uint64(TimeStep(now)), 6)
+	step, ok := ValidateTOTP(secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, TimeStep(now), step)
+
+	// one step of clock drift is accepted
+	previous := HOTP(key, uint64(TimeStep(now)-1), 6)
+	step, ok = ValidateTOTP(secret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, TimeStep(now)-1, step)
+
+	old := HOTP(key, uint64(TimeStep(now)-3), 6)
+	_, ok = ValidateTOTP(secret, old, now)
+	assert.False(t, ok)
+
+	_, ok = ValidateTOTP(secret, "12345", now)
+	assert.False(t, ok)
+
+	_, ok = ValidateTOTP("not base32 !", code, now)
+	assert.False(t, ok)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	other, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("GoServe", "test@abc.com", "SECRET")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/GoServe:test@abc.com?"))
+	assert.Contains(t, uri, "secret=SECRET")
+	assert.Contains(t, uri, "issuer=GoServe")
+}
@@ -1,8 +1,15 @@
 package middleware
 
 import (
+	"log"
+	"time"
+
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/permission"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
 	"github.com/afteracademy/goserve-example-api-server-postgres/common"
 	"github.com/afteracademy/goserve-example-api-server-postgres/utils"
 	"github.com/afteracademy/goserve/v2/network"
@@ -12,29 +19,44 @@ import (
 
 type authenticationProvider struct {
 	common.ContextPayload
-	authService auth.Service
-	userService user.Service
+	authService       auth.Service
+	userService       user.Service
+	permissionService permission.Service
+	auditService      audit.Service
+	cookies           common.TokenCookies
+	mfaRequiredRoles  []userModel.RoleCode
 }
 
-func NewAuthenticationProvider(authService auth.Service, userService user.Service) network.AuthenticationProvider {
+// NewAuthenticationProvider takes the permissions and the mfaRequiredRoles of the
+// authorization, the impersonating admin must still be allowed to impersonate
+func NewAuthenticationProvider(
+	authService auth.Service,
+	userService user.Service,
+	permissionService permission.Service,
+	auditService audit.Service,
+	cookies common.TokenCookies,
+	mfaRequiredRoles ...userModel.RoleCode,
+) network.AuthenticationProvider {
 	return &authenticationProvider{
-		ContextPayload: common.NewContextPayload(),
-		authService:    authService,
-		userService:    userService,
+		ContextPayload:    common.NewContextPayload(),
+		authService:       authService,
+		userService:       userService,
+		permissionService: permissionService,
+		auditService:      auditService,
+		cookies:           cookies,
+		mfaRequiredRoles:  mfaRequiredRoles,
 	}
 }
 
 func (m *authenticationProvider) Middleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		authHeader := ctx.GetHeader(network.AuthorizationHeader)
-		if len(authHeader) == 0 {
-			network.SendUnauthorizedError(ctx, "permission denied: missing Authorization", nil)
+		token, ok := m.requestToken(ctx)
+		if !ok {
 			return
 		}
 
-		token := utils.ExtractBearerToken(authHeader)
-		if token == "" {
-			network.SendUnauthorizedError(ctx, "permission denied: invalid Authorization", nil)
+		if model.IsAccessToken(token) {
+			m.authenticateAccessToken(ctx, token)
 			return
 		}
 
@@ -62,15 +84,138 @@ func (m *authenticationProvider) Middleware() gin.HandlerFunc {
 			return
 		}
 
+		if claims.IsImpersonation() {
+			m.authenticateImpersonation(ctx, claims, user)
+			return
+		}
+
 		keystore, err := m.authService.FetchKeystore(user, claims.ID)
 		if err != nil || keystore == nil {
 			network.SendUnauthorizedError(ctx, "permission denied: invalid access token", err)
 			return
 		}
 
+		err = m.authService.TouchKeystore(keystore)
+		if err != nil {
+			log.Printf("keystore %s last used time could not be updated: %v", keystore.ID, err)
+		}
+
 		m.SetUser(ctx, user)
 		m.SetKeystore(ctx, keystore)
 
 		ctx.Next()
 	}
 }
+
+// requestToken reads the bearer token, or the access token cookie of the browser clients
+// when there is no Authorization header. The cookie is sent by the browser on its own,
+// so the cookie authenticated requests must also pass the csrf check.
+func (m *authenticationProvider) requestToken(ctx *gin.Context) (string, bool) {
+	authHeader := ctx.GetHeader(network.AuthorizationHeader)
+	if len(authHeader) == 0 {
+		token := m.cookies.AccessToken(ctx)
+		if token == "" {
+			network.SendUnauthorizedError(ctx, "permission denied: missing Authorization", nil)
+			return "", false
+		}
+
+		if !m.cookies.VerifyCsrf(ctx) {
+			network.SendForbiddenError(ctx, "permission denied: invalid csrf token", nil)
+			return "", false
+		}
+		return token, true
+	}
+
+	token := utils.ExtractBearerToken(authHeader)
+	if token == "" {
+		network.SendUnauthorizedError(ctx, "permission denied: invalid Authorization", nil)
+		return "", false
+	}
+	return token, true
+}
+
+// authenticateImpersonation accepts the token of an admin acting as the user, the token lives
+// only as long as the admin's session and every request is audited with the admin as the actor
+func (m *authenticationProvider) authenticateImpersonation(
+	ctx *gin.Context,
+	claims *model.TokenClaims,
+	user *userModel.User,
+) {
+	actorId, err := uuid.Parse(claims.Actor.Subject)
+	if err != nil {
+		network.SendUnauthorizedError(ctx, "permission denied: invalid claims actor", nil)
+		return
+	}
+
+	actor, err := m.userService.FetchUserById(actorId)
+	if err != nil {
+		network.SendUnauthorizedError(ctx, "permission denied: claims actor does not exists", err)
+		return
+	}
+
+	keystore, err := m.authService.FetchKeystore(actor, claims.ID)
+	if err != nil || keystore == nil {
+		network.SendUnauthorizedError(ctx, "permission denied: impersonation ended", err)
+		return
+	}
+
+	// the admin could have lost the permission since the impersonation started
+	granted, _ := rolesGrant(m.permissionService, m.mfaRequiredRoles, actor, userModel.PermissionUserImpersonate)
+	if !granted {
+		network.SendForbiddenError(ctx, "permission denied: impersonation not permitted", nil)
+		return
+	}
+
+	m.auditService.Record(&model.AuditEvent{
+		Action:     model.AuditActionImpersonationRequest,
+		Outcome:    model.AuditOutcomeSuccess,
+		ActorID:    &actor.ID,
+		TargetType: model.AuditTargetUser,
+		TargetID:   user.ID.String(),
+		IP:         ctx.ClientIP(),
+		UserAgent:  ctx.Request.UserAgent(),
+		Metadata:   map[string]string{"request": ctx.Request.Method + " " + ctx.Request.URL.Path},
+	})
+
+	m.SetUser(ctx, user)
+	m.SetActor(ctx, actor)
+	m.SetKeystore(ctx, keystore)
+
+	ctx.Next()
+}
+
+// authenticateAccessToken accepts a personal access token in place of the jwt,
+// only on the routes that declared one of its scopes
+func (m *authenticationProvider) authenticateAccessToken(ctx *gin.Context, token string) {
+	accessToken, err := m.authService.FetchAccessToken(token)
+	if err != nil {
+		network.SendUnauthorizedError(ctx, "permission denied: invalid access token", err)
+		return
+	}
+
+	if accessToken.IsExpired(time.Now()) {
+		network.SendUnauthorizedError(ctx, "permission denied: access token expired", nil)
+		return
+	}
+
+	if !common.AccessTokenAllowed(ctx, accessToken) {
+		network.SendForbiddenError(ctx, "permission denied: access token lacks scope", nil)
+		return
+	}
+
+	user, err := m.userService.FetchUserById(accessToken.UserID)
+	if err != nil {
+		network.SendUnauthorizedError(ctx, "permission denied: access token owner does not exists", err)
+		return
+	}
+
+	err = m.authService.TouchAccessToken(accessToken)
+	if err != nil {
+		log.Printf("access token %s last used time could not be updated: %v", accessToken.ID, err)
+	}
+
+	m.SetUser(ctx, user)
+	m.SetAccessToken(ctx, accessToken)
+
+	ctx.Next()
+}
@@ -3,13 +3,18 @@ package middleware
 import (
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/permission"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
 	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
 	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
 	"github.com/afteracademy/goserve/v2/network"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -18,6 +23,9 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+// bearerCookies has the cookie mode disabled
+var bearerCookies = common.NewTokenCookies(&config.Env{})
+
 func TestAuthenticationProvider_NoAccessToken(t *testing.T) {
 	mockAuthService := new(auth.MockService)
 	mockUserService := new(user.MockService)
@@ -26,7 +34,7 @@ func TestAuthenticationProvider_NoAccessToken(t *testing.T) {
 
 	rr := network.MockTestAuthenticationProvider(
 		t,
-		NewAuthenticationProvider(mockAuthService, mockUserService),
+		NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(), bearerCookies),
 		network.MockSuccessMsgHandler("success"),
 		nil,
 	)
@@ -46,7 +54,7 @@ func TestAuthenticationProvider_WrongAccessToken(t *testing.T) {
 
 	rr := network.MockTestAuthenticationProvider(
 		t,
-		NewAuthenticationProvider(mockAuthService, mockUserService),
+		NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(), bearerCookies),
 		network.MockSuccessMsgHandler("success"),
 		map[string]string{network.AuthorizationHeader: token},
 	)
@@ -67,7 +75,7 @@ func TestAuthenticationProvider_VerifyTokenInvalid(t *testing.T) {
 
 	rr := network.MockTestAuthenticationProvider(
 		t,
-		NewAuthenticationProvider(mockAuthService, mockUserService),
+		NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(), bearerCookies),
 		network.MockSuccessMsgHandler("success"),
 		map[string]string{network.AuthorizationHeader: token},
 	)
@@ -83,14 +91,14 @@ func TestAuthenticationProvider_VerifyTokenInvalidClaim(t *testing.T) {
 	mockAuthService.AssertNotCalled(t, "FetchUserById", mock.Anything)
 
 	token := "Bearer token"
-	claims := &jwt.RegisteredClaims{}
+	claims := &model.TokenClaims{RegisteredClaims: jwt.RegisteredClaims{}}
 
 	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
 	mockAuthService.On("ValidateClaims", claims).Return(false)
 
 	rr := network.MockTestAuthenticationProvider(
 		t,
-		NewAuthenticationProvider(mockAuthService, mockUserService),
+		NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(), bearerCookies),
 		network.MockSuccessMsgHandler("success"),
 		map[string]string{network.AuthorizationHeader: token},
 	)
@@ -106,14 +114,14 @@ func TestAuthenticationProvider_VerifyTokenInvalidClaimUser(t *testing.T) {
 	mockAuthService.AssertNotCalled(t, "FetchUserById", mock.Anything)
 
 	token := "Bearer token"
-	claims := &jwt.RegisteredClaims{}
+	claims := &model.TokenClaims{RegisteredClaims: jwt.RegisteredClaims{}}
 
 	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
 	mockAuthService.On("ValidateClaims", claims).Return(true)
 
 	rr := network.MockTestAuthenticationProvider(
 		t,
-		NewAuthenticationProvider(mockAuthService, mockUserService),
+		NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(), bearerCookies),
 		network.MockSuccessMsgHandler("success"),
 		map[string]string{network.AuthorizationHeader: token},
 	)
@@ -130,7 +138,7 @@ func TestAuthenticationProvider_VerifyTokenInvalidUser(t *testing.T) {
 
 	token := "Bearer token"
 	userId := uuid.New()
-	claims := &jwt.RegisteredClaims{Subject: userId.String()}
+	claims := &model.TokenClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: userId.String()}}
 
 	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
 	mockAuthService.On("ValidateClaims", claims).Return(true)
@@ -138,7 +146,7 @@ func TestAuthenticationProvider_VerifyTokenInvalidUser(t *testing.T) {
 
 	rr := network.MockTestAuthenticationProvider(
 		t,
-		NewAuthenticationProvider(mockAuthService, mockUserService),
+		NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(), bearerCookies),
 		network.MockSuccessMsgHandler("success"),
 		map[string]string{network.AuthorizationHeader: token},
 	)
@@ -154,7 +162,7 @@ func TestAuthenticationProvider_VerifyTokenInvalidKaystore(t *testing.T) {
 
 	token := "Bearer token"
 	userId := uuid.New()
-	claims := &jwt.RegisteredClaims{ID: "claimId", Subject: userId.String()}
+	claims := &model.TokenClaims{RegisteredClaims: jwt.RegisteredClaims{ID: "claimId", Subject: userId.String()}}
 	user := &userModel.User{ID: userId}
 
 	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
@@ -164,7 +172,7 @@ func TestAuthenticationProvider_VerifyTokenInvalidKaystore(t *testing.T) {
 
 	rr := network.MockTestAuthenticationProvider(
 		t,
-		NewAuthenticationProvider(mockAuthService, mockUserService),
+		NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(), bearerCookies),
 		network.MockSuccessMsgHandler("success"),
 		map[string]string{network.AuthorizationHeader: token},
 	)
@@ -180,7 +188,7 @@ func TestAuthenticationProvider_Success(t *testing.T) {
 	token := "Bearer token"
 	userId := uuid.New()
 	keystoreId := uuid.New()
-	claims := &jwt.RegisteredClaims{ID: "claimId", Subject: userId.String()}
+	claims := &model.TokenClaims{RegisteredClaims: jwt.RegisteredClaims{ID: "claimId", Subject: userId.String()}}
 	user := &userModel.User{ID: userId}
 	keystore := &model.Keystore{ID: keystoreId}
 
@@ -188,6 +196,7 @@ func TestAuthenticationProvider_Success(t *testing.T) {
 	mockAuthService.On("ValidateClaims", claims).Return(true)
 	mockUserService.On("FetchUserById", userId).Return(user, nil)
 	mockAuthService.On("FetchKeystore", user, claims.ID).Return(keystore, nil)
+	mockAuthService.On("TouchKeystore", keystore).Return(nil)
 
 	mockHandler := func(ctx *gin.Context) {
 		assert.Equal(t, common.NewContextPayload().MustGetUser(ctx).ID, userId)
@@ -197,7 +206,7 @@ func TestAuthenticationProvider_Success(t *testing.T) {
 
 	rr := network.MockTestAuthenticationProvider(
 		t,
-		NewAuthenticationProvider(mockAuthService, mockUserService),
+		NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(), bearerCookies),
 		mockHandler,
 		map[string]string{network.AuthorizationHeader: token},
 	)
@@ -205,3 +214,308 @@ func TestAuthenticationProvider_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rr.Code)
 	assert.Contains(t, rr.Body.String(), `"message":"success"`)
 }
+
+func serveWithTokenScope(
+	provider network.AuthenticationProvider,
+	handler gin.HandlerFunc,
+	authorization string,
+	scopes ...model.Scope,
+) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/", common.TokenScope(scopes...), provider.Middleware(), handler)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(network.AuthorizationHeader, authorization)
+	engine.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAuthenticationProvider_AccessTokenRouteWithoutScope(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	mockUserService := new(user.MockService)
+
+	accessToken := &model.AccessToken{ID: uuid.New(), UserID: uuid.New(), Scopes: []model.Scope{model.ScopeBlogWrite}}
+	mockAuthService.On("FetchAccessToken", "gspat_token").Return(accessToken, nil)
+
+	rr := network.MockTestAuthenticationProvider(
+		t,
+		NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(), bearerCookies),
+		network.MockSuccessMsgHandler("success"),
+		map[string]string{network.AuthorizationHeader: "Bearer gspat_token"},
+	)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: access token lacks scope"`)
+	mockAuthService.AssertNotCalled(t, "VerifyToken", mock.Anything)
+	mockUserService.AssertNotCalled(t, "FetchUserById", mock.Anything)
+}
+
+func TestAuthenticationProvider_AccessTokenExpired(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	mockUserService := new(user.MockService)
+
+	expiresAt := time.Now().Add(-time.Minute)
+	accessToken := &model.AccessToken{ID: uuid.New(), Scopes: []model.Scope{model.ScopeBlogRead}, ExpiresAt: &expiresAt}
+	mockAuthService.On("FetchAccessToken", "gspat_token").Return(accessToken, nil)
+
+	rr := serveWithTokenScope(
+		NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(), bearerCookies),
+		network.MockSuccessMsgHandler("success"),
+		"Bearer gspat_token",
+		model.ScopeBlogRead,
+	)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: access token expired"`)
+}
+
+func TestAuthenticationProvider_AccessTokenSuccess(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	mockUserService := new(user.MockService)
+
+	userId := uuid.New()
+	user := &userModel.User{ID: userId}
+	accessToken := &model.AccessToken{ID: uuid.New(), UserID: userId, Scopes: []model.Scope{model.ScopeBlogWrite}}
+
+	mockAuthService.On("FetchAccessToken", "gspat_token").Return(accessToken, nil)
+	mockAuthService.On("TouchAccessToken", accessToken).Return(nil)
+	mockUserService.On("FetchUserById", userId).Return(user, nil)
+
+	mockHandler := func(ctx *gin.Context) {
+		payload := common.NewContextPayload()
+		assert.Equal(t, userId, payload.MustGetUser(ctx).ID)
+		found, ok := payload.GetAccessToken(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, accessToken.ID, found.ID)
+		network.SendSuccessMsgResponse(ctx, "success")
+	}
+
+	rr := serveWithTokenScope(
+		NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(), bearerCookies),
+		mockHandler,
+		"Bearer gspat_token",
+		model.ScopeBlogRead,
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"success"`)
+	mockAuthService.AssertExpectations(t)
+	mockAuthService.AssertNotCalled(t, "VerifyToken", mock.Anything)
+}
+
+func TestAuthenticationProvider_ImpersonationEnded(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	mockUserService := new(user.MockService)
+
+	userId := uuid.New()
+	actorId := uuid.New()
+	claims := &model.TokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ID: "actorKey", Subject: userId.String()},
+		Actor:            &model.ActorClaim{Subject: actorId.String()},
+	}
+	user := &userModel.User{ID: userId}
+	actor := &userModel.User{ID: actorId}
+
+	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
+	mockAuthService.On("ValidateClaims", claims).Return(true)
+	mockUserService.On("FetchUserById", userId).Return(user, nil)
+	mockUserService.On("FetchUserById", actorId).Return(actor, nil)
+	mockAuthService.On("FetchKeystore", actor, claims.ID).Return(nil, errors.New("not found"))
+
+	rr := network.MockTestAuthenticationProvider(
+		t,
+		NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(), bearerCookies),
+		network.MockSuccessMsgHandler("success"),
+		map[string]string{network.AuthorizationHeader: "Bearer token"},
+	)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: impersonation ended"`)
+	mockAuthService.AssertNotCalled(t, "FetchKeystore", user, claims.ID)
+}
+
+func TestAuthenticationProvider_ImpersonationSuccess(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	mockUserService := new(user.MockService)
+
+	userId := uuid.New()
+	actorId := uuid.New()
+	claims := &model.TokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ID: "actorKey", Subject: userId.String()},
+		Actor:            &model.ActorClaim{Subject: actorId.String()},
+	}
+	user := &userModel.User{ID: userId}
+	actor := &userModel.User{ID: actorId, Roles: []*userModel.Role{{ID: uuid.New(), Code: userModel.RoleCodeAdmin}}}
+	keystore := &model.Keystore{ID: uuid.New(), UserID: actorId}
+
+	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
+	mockAuthService.On("ValidateClaims", claims).Return(true)
+	mockUserService.On("FetchUserById", userId).Return(user, nil)
+	mockUserService.On("FetchUserById", actorId).Return(actor, nil)
+	mockAuthService.On("FetchKeystore", actor, claims.ID).Return(keystore, nil)
+
+	mockHandler := func(ctx *gin.Context) {
+		payload := common.NewContextPayload()
+		assert.Equal(t, userId, payload.MustGetUser(ctx).ID)
+		impersonator, ok := payload.GetActor(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, actorId, impersonator.ID)
+		network.SendSuccessMsgResponse(ctx, "success")
+	}
+
+	auditService := new(audit.MockService)
+	auditService.On("Record", mock.MatchedBy(func(event *model.AuditEvent) bool {
+		return event.Action == model.AuditActionImpersonationRequest &&
+			*event.ActorID == actorId &&
+			event.TargetID == userId.String()
+	})).Return()
+
+	rr := network.MockTestAuthenticationProvider(
+		t,
+		NewAuthenticationProvider(
+			mockAuthService,
+			mockUserService,
+			mockPermissionService(userModel.RoleCodeAdmin, userModel.PermissionUserImpersonate),
+			auditService,
+			bearerCookies,
+		),
+		mockHandler,
+		map[string]string{network.AuthorizationHeader: "Bearer token"},
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockAuthService.AssertNotCalled(t, "TouchKeystore", mock.Anything)
+	auditService.AssertExpectations(t)
+}
+
+func TestAuthenticationProvider_ImpersonationNotPermitted(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	mockUserService := new(user.MockService)
+
+	userId := uuid.New()
+	actorId := uuid.New()
+	claims := &model.TokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ID: "actorKey", Subject: userId.String()},
+		Actor:            &model.ActorClaim{Subject: actorId.String()},
+	}
+	user := &userModel.User{ID: userId}
+	actor := &userModel.User{ID: actorId, Roles: []*userModel.Role{{ID: uuid.New(), Code: userModel.RoleCodeLearner}}}
+	keystore := &model.Keystore{ID: uuid.New(), UserID: actorId}
+
+	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
+	mockAuthService.On("ValidateClaims", claims).Return(true)
+	mockUserService.On("FetchUserById", userId).Return(user, nil)
+	mockUserService.On("FetchUserById", actorId).Return(actor, nil)
+	mockAuthService.On("FetchKeystore", actor, claims.ID).Return(keystore, nil)
+
+	rr := network.MockTestAuthenticationProvider(
+		t,
+		NewAuthenticationProvider(
+			mockAuthService,
+			mockUserService,
+			mockPermissionService(userModel.RoleCodeAdmin, userModel.PermissionUserImpersonate),
+			mockAuditService(),
+			bearerCookies,
+		),
+		network.MockSuccessMsgHandler("success"),
+		map[string]string{network.AuthorizationHeader: "Bearer token"},
+	)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: impersonation not permitted"`)
+}
+
+func serveWithCookies(
+	provider network.AuthenticationProvider,
+	method string,
+	cookies map[string]string,
+	csrfToken string,
+) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Handle(method, "/", provider.Middleware(), network.MockSuccessMsgHandler("success"))
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(method, "/", nil)
+	for name, value := range cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+	if csrfToken != "" {
+		req.Header.Set(common.CsrfTokenHeader, csrfToken)
+	}
+	engine.ServeHTTP(rr, req)
+	return rr
+}
+
+func mockCookieSession(mockAuthService *auth.MockService, mockUserService *user.MockService) {
+	userId := uuid.New()
+	claims := &model.TokenClaims{RegisteredClaims: jwt.RegisteredClaims{ID: "claimId", Subject: userId.String()}}
+	user := &userModel.User{ID: userId}
+	keystore := &model.Keystore{ID: uuid.New()}
+
+	mockAuthService.On("VerifyToken", "token").Return(claims, nil)
+	mockAuthService.On("ValidateClaims", claims).Return(true)
+	mockUserService.On("FetchUserById", userId).Return(user, nil)
+	mockAuthService.On("FetchKeystore", user, claims.ID).Return(keystore, nil)
+	mockAuthService.On("TouchKeystore", keystore).Return(nil)
+}
+
+func TestAuthenticationProvider_CookieSafeMethod(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	mockUserService := new(user.MockService)
+	mockCookieSession(mockAuthService, mockUserService)
+
+	provider := NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(),
+		common.NewTokenCookies(&config.Env{AuthCookieEnabled: true}))
+
+	rr := serveWithCookies(provider, http.MethodGet, map[string]string{common.AccessTokenCookie: "token"}, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestAuthenticationProvider_CookieMissingCsrf(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	mockUserService := new(user.MockService)
+
+	provider := NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(),
+		common.NewTokenCookies(&config.Env{AuthCookieEnabled: true}))
+
+	rr := serveWithCookies(provider, http.MethodPost, map[string]string{
+		common.AccessTokenCookie: "token",
+		common.CsrfTokenCookie:   "csrf",
+	}, "")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: invalid csrf token"`)
+	mockAuthService.AssertNotCalled(t, "VerifyToken", mock.Anything)
+}
+
+func TestAuthenticationProvider_CookieWithCsrf(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	mockUserService := new(user.MockService)
+	mockCookieSession(mockAuthService, mockUserService)
+
+	provider := NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(),
+		common.NewTokenCookies(&config.Env{AuthCookieEnabled: true}))
+
+	rr := serveWithCookies(provider, http.MethodPost, map[string]string{
+		common.AccessTokenCookie: "token",
+		common.CsrfTokenCookie:   "csrf",
+	}, "csrf")
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestAuthenticationProvider_CookieModeDisabled(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	mockUserService := new(user.MockService)
+
+	rr := serveWithCookies(
+		NewAuthenticationProvider(mockAuthService, mockUserService, new(permission.MockService), mockAuditService(), bearerCookies),
+		http.MethodGet,
+		map[string]string{common.AccessTokenCookie: "token"},
+		"",
+	)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: missing Authorization"`)
+}
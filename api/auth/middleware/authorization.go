@@ -1,6 +1,12 @@
 package middleware
 
 import (
+	"slices"
+	"strings"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit"
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/permission"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
 	"github.com/afteracademy/goserve-example-api-server-postgres/common"
 	"github.com/afteracademy/goserve/v2/network"
@@ -9,41 +15,102 @@ import (
 
 type authorizationProvider struct {
 	common.ContextPayload
+	permissionService permission.Service
+	auditService      audit.Service
+	mfaRequiredRoles  []model.RoleCode
 }
 
-func NewAuthorizationProvider() network.AuthorizationProvider {
+// NewAuthorizationProvider grants the permissions through the roles of the user,
+// the mfaRequiredRoles grant nothing to users without mfa enabled
+func NewAuthorizationProvider(
+	permissionService permission.Service,
+	auditService audit.Service,
+	mfaRequiredRoles ...model.RoleCode,
+) network.AuthorizationProvider {
 	return &authorizationProvider{
-		ContextPayload: common.NewContextPayload(),
+		ContextPayload:    common.NewContextPayload(),
+		permissionService: permissionService,
+		auditService:      auditService,
+		mfaRequiredRoles:  mfaRequiredRoles,
 	}
 }
 
-func (m *authorizationProvider) Middleware(roleNames ...string) gin.HandlerFunc {
+// Middleware lets the request through when any role of the user holds one of the permissions
+func (m *authorizationProvider) Middleware(permissionCodes ...string) gin.HandlerFunc {
+	codes := make([]model.PermissionCode, len(permissionCodes))
+	for i, code := range permissionCodes {
+		codes[i] = model.PermissionCode(code)
+	}
+
 	return func(ctx *gin.Context) {
-		if len(roleNames) == 0 {
-			network.SendForbiddenError(ctx, "permission denied: role missing", nil)
+		if len(codes) == 0 {
+			m.deny(ctx, nil, permissionCodes, "permission denied: permission missing")
 			return
 		}
 
 		user := m.MustGetUser(ctx)
 
-		hasRole := false
-		for _, code := range roleNames {
-			for _, role := range user.Roles {
-				if role.Code == model.RoleCode(code) {
-					hasRole = true
-					break
-				}
-			}
-			if hasRole {
-				break
-			}
+		granted, mfaRequired := rolesGrant(m.permissionService, m.mfaRequiredRoles, user, codes...)
+
+		if !granted && mfaRequired {
+			m.deny(ctx, user, permissionCodes, "permission denied: enable mfa to use this role")
+			return
 		}
 
-		if !hasRole {
-			network.SendForbiddenError(ctx, "permission denied: does not have suffient role", nil)
+		if !granted {
+			m.deny(ctx, user, permissionCodes, "permission denied: does not have sufficient permission")
 			return
 		}
 
 		ctx.Next()
 	}
 }
+
+// deny audits the refused request before the forbidden response, the user is nil
+// when the route declared no permission
+func (m *authorizationProvider) deny(ctx *gin.Context, user *model.User, permissionCodes []string, message string) {
+	event := &authModel.AuditEvent{
+		Action:     authModel.AuditActionAccessDenied,
+		Outcome:    authModel.AuditOutcomeDenied,
+		TargetType: authModel.AuditTargetRoute,
+		TargetID:   ctx.Request.Method + " " + ctx.FullPath(),
+		IP:         ctx.ClientIP(),
+		UserAgent:  ctx.Request.UserAgent(),
+		Metadata: map[string]string{
+			"reason":      message,
+			"permissions": strings.Join(permissionCodes, ","),
+		},
+	}
+
+	if user != nil {
+		event.ActorID = &user.ID
+		if actor, ok := m.GetActor(ctx); ok {
+			event.ActorID = &actor.ID
+			event.Metadata["impersonating"] = user.ID.String()
+		}
+	}
+
+	m.auditService.Record(event)
+	network.SendForbiddenError(ctx, message, nil)
+}
+
+// rolesGrant reports whether any role of the user holds one of the permissions, mfaRequired
+// is set when only the roles that grant nothing to users without mfa hold them
+func rolesGrant(
+	permissionService permission.Service,
+	mfaRequiredRoles []model.RoleCode,
+	user *model.User,
+	codes ...model.PermissionCode,
+) (granted bool, mfaRequired bool) {
+	for _, role := range user.Roles {
+		if !permissionService.HasAnyPermission(role.Code, codes...) {
+			continue
+		}
+		if !user.MfaEnabled && slices.Contains(mfaRequiredRoles, role.Code) {
+			mfaRequired = true
+			continue
+		}
+		return true, false
+	}
+	return false, mfaRequired
+}
@@ -4,15 +4,32 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit"
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/permission"
 	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
 	"github.com/afteracademy/goserve-example-api-server-postgres/common"
 	"github.com/afteracademy/goserve/v2/network"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
-func TestAuthorizationProvider_NoRole(t *testing.T) {
+func mockPermissionService(role userModel.RoleCode, code userModel.PermissionCode) *permission.MockService {
+	service := new(permission.MockService)
+	service.On("HasAnyPermission", role, []userModel.PermissionCode{code}).Return(true)
+	service.On("HasAnyPermission", mock.Anything, mock.Anything).Return(false)
+	return service
+}
+
+func mockAuditService() *audit.MockService {
+	service := new(audit.MockService)
+	service.On("Record", mock.Anything).Return()
+	return service
+}
+
+func TestAuthorizationProvider_NoPermission(t *testing.T) {
 	mockAuthProvider := new(network.MockAuthenticationProvider)
 	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
 		ctx.Next()
@@ -20,16 +37,16 @@ func TestAuthorizationProvider_NoRole(t *testing.T) {
 
 	rr := network.MockTestAuthorizationProvider(t, "",
 		mockAuthProvider,
-		NewAuthorizationProvider(),
+		NewAuthorizationProvider(new(permission.MockService), mockAuditService()),
 		network.MockSuccessMsgHandler("success"),
 		nil,
 	)
 
 	assert.Equal(t, http.StatusForbidden, rr.Code)
-	assert.Contains(t, rr.Body.String(), `"message":"permission denied: role missing"`)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: permission missing"`)
 }
 
-func TestAuthorizationProvider_WrongRole(t *testing.T) {
+func TestAuthorizationProvider_WrongPermission(t *testing.T) {
 	role := &userModel.Role{ID: uuid.New(), Code: "CORRECT_ROLE"}
 	user := &userModel.User{ID: uuid.New(), Roles: []*userModel.Role{role}}
 
@@ -40,15 +57,15 @@ func TestAuthorizationProvider_WrongRole(t *testing.T) {
 		ctx.Next()
 	}))
 
-	rr := network.MockTestAuthorizationProvider(t, "WRONG_ROLE",
+	rr := network.MockTestAuthorizationProvider(t, "wrong.permission",
 		mockAuthProvider,
-		NewAuthorizationProvider(),
+		NewAuthorizationProvider(mockPermissionService(role.Code, "correct.permission"), mockAuditService()),
 		network.MockSuccessMsgHandler("success"),
 		nil,
 	)
 
 	assert.Equal(t, http.StatusForbidden, rr.Code)
-	assert.Contains(t, rr.Body.String(), `"message":"permission denied: does not have suffient role"`)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: does not have sufficient permission"`)
 }
 
 func TestAuthorizationProvider_Success(t *testing.T) {
@@ -63,9 +80,9 @@ func TestAuthorizationProvider_Success(t *testing.T) {
 		ctx.Next()
 	}))
 
-	rr := network.MockTestAuthorizationProvider(t, "CORRECT_ROLE",
+	rr := network.MockTestAuthorizationProvider(t, "correct.permission",
 		mockAuthProvider,
-		NewAuthorizationProvider(),
+		NewAuthorizationProvider(mockPermissionService(role.Code, "correct.permission"), mockAuditService()),
 		network.MockSuccessMsgHandler("success"),
 		nil,
 	)
@@ -73,3 +90,80 @@ func TestAuthorizationProvider_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rr.Code)
 	assert.Contains(t, rr.Body.String(), `"message":"success"`)
 }
+
+func TestAuthorizationProvider_MfaRequired(t *testing.T) {
+	role := &userModel.Role{ID: uuid.New(), Code: "CORRECT_ROLE"}
+	user := &userModel.User{ID: uuid.New(), Roles: []*userModel.Role{role}}
+
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		payload := common.NewContextPayload()
+		payload.SetUser(ctx, user)
+		ctx.Next()
+	}))
+
+	rr := network.MockTestAuthorizationProvider(t, "correct.permission",
+		mockAuthProvider,
+		NewAuthorizationProvider(mockPermissionService(role.Code, "correct.permission"), mockAuditService(), "CORRECT_ROLE"),
+		network.MockSuccessMsgHandler("success"),
+		nil,
+	)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: enable mfa to use this role"`)
+}
+
+func TestAuthorizationProvider_MfaRequiredSuccess(t *testing.T) {
+	role := &userModel.Role{ID: uuid.New(), Code: "CORRECT_ROLE"}
+	user := &userModel.User{ID: uuid.New(), Roles: []*userModel.Role{role}, MfaEnabled: true}
+
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		payload := common.NewContextPayload()
+		payload.SetUser(ctx, user)
+		ctx.Next()
+	}))
+
+	rr := network.MockTestAuthorizationProvider(t, "correct.permission",
+		mockAuthProvider,
+		NewAuthorizationProvider(mockPermissionService(role.Code, "correct.permission"), mockAuditService(), "CORRECT_ROLE"),
+		network.MockSuccessMsgHandler("success"),
+		nil,
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"success"`)
+}
+
+func TestAuthorizationProvider_DenialAuditedWithActor(t *testing.T) {
+	role := &userModel.Role{ID: uuid.New(), Code: "CORRECT_ROLE"}
+	user := &userModel.User{ID: uuid.New(), Roles: []*userModel.Role{role}}
+	actor := &userModel.User{ID: uuid.New()}
+
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		payload := common.NewContextPayload()
+		payload.SetUser(ctx, user)
+		payload.SetActor(ctx, actor)
+		ctx.Next()
+	}))
+
+	auditService := new(audit.MockService)
+	auditService.On("Record", mock.MatchedBy(func(event *authModel.AuditEvent) bool {
+		return event.Action == authModel.AuditActionAccessDenied &&
+			event.Outcome == authModel.AuditOutcomeDenied &&
+			*event.ActorID == actor.ID &&
+			event.Metadata["impersonating"] == user.ID.String() &&
+			event.Metadata["permissions"] == "wrong.permission"
+	})).Return()
+
+	rr := network.MockTestAuthorizationProvider(t, "wrong.permission",
+		mockAuthProvider,
+		NewAuthorizationProvider(mockPermissionService(role.Code, "correct.permission"), auditService),
+		network.MockSuccessMsgHandler("success"),
+		nil,
+	)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	auditService.AssertExpectations(t)
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"time"
+
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
 	"github.com/afteracademy/goserve-example-api-server-postgres/common"
 	"github.com/afteracademy/goserve/v2/network"
@@ -36,6 +38,16 @@ func (m *keyProtection) Handler(ctx *gin.Context) {
 		return
 	}
 
+	if apikey.IsExpired(time.Now()) {
+		network.SendForbiddenError(ctx, "permission denied: x-api-key expired", nil)
+		return
+	}
+
+	if !apikey.AllowsOrigin(ctx.GetHeader("Origin")) {
+		network.SendForbiddenError(ctx, "permission denied: origin not allowed for x-api-key", nil)
+		return
+	}
+
 	m.SetApiKey(ctx, apikey)
 
 	ctx.Next()
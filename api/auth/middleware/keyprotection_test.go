@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
@@ -63,3 +64,37 @@ func TestKeyProtectionMiddleware_CorrectApiKey(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rr.Code)
 	assert.Contains(t, rr.Body.String(), `"message":"success"`)
 }
+
+func TestKeyProtectionMiddleware_ExpiredApiKey(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	key := "expired"
+	expiredAt := time.Now().Add(-time.Minute)
+	mockAuthService.On("FetchApiKey", key).Return(&model.ApiKey{Key: key, ExpiresAt: &expiredAt}, nil)
+
+	rr := network.MockTestRootMiddleware(
+		t,
+		NewKeyProtection(mockAuthService),
+		network.MockSuccessMsgHandler("success"),
+		map[string]string{network.ApiKeyHeader: key},
+	)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: x-api-key expired"`)
+}
+
+func TestKeyProtectionMiddleware_OriginNotAllowed(t *testing.T) {
+	mockAuthService := new(auth.MockService)
+	key := "restricted"
+	mockAuthService.On("FetchApiKey", key).
+		Return(&model.ApiKey{Key: key, AllowedOrigins: []string{"https://app.example.com"}}, nil)
+
+	rr := network.MockTestRootMiddleware(
+		t,
+		NewKeyProtection(mockAuthService),
+		network.MockSuccessMsgHandler("success"),
+		map[string]string{network.ApiKeyHeader: key, "Origin": "https://evil.com"},
+	)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: origin not allowed for x-api-key"`)
+}
@@ -1,7 +1,10 @@
 package auth
 
 import (
+	"time"
+
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	mfaDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
 	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
 	"github.com/golang-jwt/jwt/v5"
@@ -12,32 +15,59 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) SignUpBasic(signUpDto *dto.SignUpBasic) (*dto.UserAuth, error) {
-	args := m.Called(signUpDto)
+func (m *MockService) SignUpBasic(signUpDto *dto.SignUpBasic, clientInfo *dto.ClientInfo) (*dto.UserAuth, error) {
+	args := m.Called(signUpDto, clientInfo)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*dto.UserAuth), args.Error(1)
 }
 
-func (m *MockService) SignInBasic(signInDto *dto.SignInBasic) (*dto.UserAuth, error) {
-	args := m.Called(signInDto)
+func (m *MockService) SignInBasic(signInDto *dto.SignInBasic, clientInfo *dto.ClientInfo) (*dto.UserAuth, *mfaDto.MfaChallenge, error) {
+	args := m.Called(signInDto, clientInfo)
+	return mockSignIn(args)
+}
+
+func (m *MockService) SignInMfa(verifyDto *mfaDto.MfaVerify, clientInfo *dto.ClientInfo) (*dto.UserAuth, error) {
+	args := m.Called(verifyDto, clientInfo)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*dto.UserAuth), args.Error(1)
 }
 
-func (m *MockService) RenewToken(tokenRefreshDto *dto.TokenRefresh, accessToken string) (*dto.Tokens, error) {
-	args := m.Called(tokenRefreshDto, accessToken)
+func (m *MockService) SignInUser(user *userModel.User, clientInfo *dto.ClientInfo) (*dto.UserAuth, *mfaDto.MfaChallenge, error) {
+	args := m.Called(user, clientInfo)
+	return mockSignIn(args)
+}
+
+func mockSignIn(args mock.Arguments) (*dto.UserAuth, *mfaDto.MfaChallenge, error) {
+	var userAuth *dto.UserAuth
+	if args.Get(0) != nil {
+		userAuth = args.Get(0).(*dto.UserAuth)
+	}
+	var challenge *mfaDto.MfaChallenge
+	if args.Get(1) != nil {
+		challenge = args.Get(1).(*mfaDto.MfaChallenge)
+	}
+	return userAuth, challenge, args.Error(2)
+}
+
+func (m *MockService) RenewToken(tokenRefreshDto *dto.TokenRefresh, accessToken string, clientInfo *dto.ClientInfo) (*dto.Tokens, error) {
+	args := m.Called(tokenRefreshDto, accessToken, clientInfo)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*dto.Tokens), args.Error(1)
 }
 
-func (m *MockService) SignOut(keystore *model.Keystore) error {
-	args := m.Called(keystore)
+func (m *MockService) SignOut(keystore *model.Keystore, clientInfo *dto.ClientInfo) error {
+	args := m.Called(keystore, clientInfo)
+	return args.Error(0)
+}
+
+func (m *MockService) SignOutAll(user *userModel.User) error {
+	args := m.Called(user)
 	return args.Error(0)
 }
 
@@ -46,8 +76,8 @@ func (m *MockService) IsEmailRegisted(email string) bool {
 	return args.Bool(0)
 }
 
-func (m *MockService) GenerateToken(user *userModel.User) (string, string, error) {
-	args := m.Called(user)
+func (m *MockService) GenerateToken(user *userModel.User, clientInfo *dto.ClientInfo) (string, string, error) {
+	args := m.Called(user, clientInfo)
 	return args.String(0), args.String(1), args.Error(2)
 }
 
@@ -59,20 +89,30 @@ func (m *MockService) FetchKeystore(client *userModel.User, primaryKey string) (
 	return args.Get(0).(*model.Keystore), args.Error(1)
 }
 
-func (m *MockService) VerifyToken(tokenStr string) (*jwt.RegisteredClaims, error) {
+func (m *MockService) TouchKeystore(keystore *model.Keystore) error {
+	args := m.Called(keystore)
+	return args.Error(0)
+}
+
+func (m *MockService) GenerateImpersonationToken(actor *userModel.User, keystore *model.Keystore, user *userModel.User) (string, time.Time, error) {
+	args := m.Called(actor, keystore, user)
+	return args.String(0), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *MockService) VerifyToken(tokenStr string) (*model.TokenClaims, error) {
 	args := m.Called(tokenStr)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*jwt.RegisteredClaims), args.Error(1)
+	return args.Get(0).(*model.TokenClaims), args.Error(1)
 }
 
-func (m *MockService) DecodeToken(tokenStr string) (*jwt.RegisteredClaims, error) {
+func (m *MockService) DecodeToken(tokenStr string) (*model.TokenClaims, error) {
 	args := m.Called(tokenStr)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*jwt.RegisteredClaims), args.Error(1)
+	return args.Get(0).(*model.TokenClaims), args.Error(1)
 }
 
 func (m *MockService) SignToken(claims jwt.RegisteredClaims) (string, error) {
@@ -80,7 +120,7 @@ func (m *MockService) SignToken(claims jwt.RegisteredClaims) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockService) ValidateClaims(claims *jwt.RegisteredClaims) bool {
+func (m *MockService) ValidateClaims(claims *model.TokenClaims) bool {
 	args := m.Called(claims)
 	return args.Bool(0)
 }
@@ -93,6 +133,19 @@ func (m *MockService) FetchApiKey(key string) (*model.ApiKey, error) {
 	return args.Get(0).(*model.ApiKey), args.Error(1)
 }
 
+func (m *MockService) FetchAccessToken(token string) (*model.AccessToken, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AccessToken), args.Error(1)
+}
+
+func (m *MockService) TouchAccessToken(accessToken *model.AccessToken) error {
+	args := m.Called(accessToken)
+	return args.Error(0)
+}
+
 func (m *MockService) CreateApiKey(key string, version int, permissions []model.Permission, comments []string) (*model.ApiKey, error) {
 	args := m.Called(key, version, permissions, comments)
 	if args.Get(0) == nil {
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const AccessTokenTableName = "access_tokens"
+
+// AccessTokenPrefix marks personal access tokens so that they are told apart from the jwt access tokens
+const AccessTokenPrefix = "gspat_"
+
+// AccessTokenDisplayLength is the number of leading characters of a token kept in clear
+const AccessTokenDisplayLength = len(AccessTokenPrefix) + 6
+
+type Scope string
+
+const (
+	// ScopeBlogRead allows reading the blogs of the author and editor workflows
+	ScopeBlogRead Scope = "blog:read"
+	// ScopeBlogWrite allows writing, submitting and publishing blogs
+	ScopeBlogWrite Scope = "blog:write"
+	// ScopeProfileRead allows reading the private profile of the token owner
+	ScopeProfileRead Scope = "profile:read"
+)
+
+// impliedScopes lists the scopes granted along with a scope
+var impliedScopes = map[Scope][]Scope{
+	ScopeBlogWrite: {ScopeBlogRead},
+}
+
+// AccessToken is a personal access token, a long lived credential of a user for scripts and CI,
+// it only works on the routes that accept one of its scopes
+type AccessToken struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Name        string
+	Token       string // raw token, only known when the token is created
+	TokenHash   string
+	TokenPrefix string
+	Scopes      []Scope
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+func AccessTokenDisplayPrefix(token string) string {
+	if len(token) <= AccessTokenDisplayLength {
+		return token
+	}
+	return token[:AccessTokenDisplayLength]
+}
+
+func (t *AccessToken) HasScope(scope Scope) bool {
+	for _, granted := range t.Scopes {
+		if granted == scope {
+			return true
+		}
+		for _, implied := range impliedScopes[granted] {
+			if implied == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t *AccessToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
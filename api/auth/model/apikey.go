@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,19 +9,82 @@ import (
 
 const ApiKeyTableName = "api_keys"
 
+// ApiKeyPrefixLength is the number of leading characters of a key kept in clear
+// so that admins can recognise a key without the key itself being stored
+const ApiKeyPrefixLength = 6
+
 type Permission string
 
 const (
+	// ReadPermission allows the public read only endpoints
+	ReadPermission Permission = "READ"
+	// AuthorPermission allows the author and editor clients to manage blogs
+	AuthorPermission Permission = "AUTHOR"
+	// GeneralPermission allows every endpoint of the app clients
 	GeneralPermission Permission = "GENERAL"
+	// AdminPermission allows the admin tooling as well
+	AdminPermission Permission = "ADMIN"
 )
 
+// impliedPermissions lists the permissions granted along with a permission
+var impliedPermissions = map[Permission][]Permission{
+	AdminPermission:   {GeneralPermission, AuthorPermission, ReadPermission},
+	GeneralPermission: {AuthorPermission, ReadPermission},
+	AuthorPermission:  {ReadPermission},
+}
+
 type ApiKey struct {
-	ID          uuid.UUID
-	Key         string
-	Version     int
-	Permissions []Permission
-	Comments    []string
-	Status      bool
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID             uuid.UUID
+	Key            string // raw key, only known when the key is created
+	KeyHash        string
+	KeyPrefix      string
+	Version        int
+	Permissions    []Permission
+	Comments       []string
+	ExpiresAt      *time.Time
+	AllowedOrigins []string // empty allows every origin
+	Status         bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func ApiKeyPrefix(key string) string {
+	if len(key) <= ApiKeyPrefixLength {
+		return key
+	}
+	return key[:ApiKeyPrefixLength]
+}
+
+func (k *ApiKey) HasPermission(permission Permission) bool {
+	for _, granted := range k.Permissions {
+		if granted == permission {
+			return true
+		}
+		for _, implied := range impliedPermissions[granted] {
+			if implied == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (k *ApiKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// AllowsOrigin checks the Origin header sent by browsers, requests without it are allowed
+// since non browser clients do not send it
+func (k *ApiKey) AllowsOrigin(origin string) bool {
+	if len(k.AllowedOrigins) == 0 || origin == "" {
+		return true
+	}
+
+	origin = strings.TrimSuffix(strings.ToLower(origin), "/")
+	for _, allowed := range k.AllowedOrigins {
+		if allowed == "*" || strings.TrimSuffix(strings.ToLower(allowed), "/") == origin {
+			return true
+		}
+	}
+	return false
 }
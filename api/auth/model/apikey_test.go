@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiKey_HasPermission(t *testing.T) {
+	read := &ApiKey{Permissions: []Permission{ReadPermission}}
+	assert.True(t, read.HasPermission(ReadPermission))
+	assert.False(t, read.HasPermission(AuthorPermission))
+	assert.False(t, read.HasPermission(GeneralPermission))
+
+	general := &ApiKey{Permissions: []Permission{GeneralPermission}}
+	assert.True(t, general.HasPermission(ReadPermission))
+	assert.True(t, general.HasPermission(AuthorPermission))
+	assert.True(t, general.HasPermission(GeneralPermission))
+	assert.False(t, general.HasPermission(AdminPermission))
+
+	admin := &ApiKey{Permissions: []Permission{AdminPermission}}
+	assert.True(t, admin.HasPermission(GeneralPermission))
+	assert.True(t, admin.HasPermission(AdminPermission))
+
+	none := &ApiKey{}
+	assert.False(t, none.HasPermission(ReadPermission))
+}
+
+func TestApiKey_IsExpired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	assert.False(t, (&ApiKey{}).IsExpired(now))
+	assert.True(t, (&ApiKey{ExpiresAt: &past}).IsExpired(now))
+	assert.False(t, (&ApiKey{ExpiresAt: &future}).IsExpired(now))
+}
+
+func TestApiKey_AllowsOrigin(t *testing.T) {
+	open := &ApiKey{}
+	assert.True(t, open.AllowsOrigin("https://any.com"))
+
+	restricted := &ApiKey{AllowedOrigins: []string{"https://app.example.com/"}}
+	assert.True(t, restricted.AllowsOrigin(""))
+	assert.True(t, restricted.AllowsOrigin("https://app.example.com"))
+	assert.True(t, restricted.AllowsOrigin("HTTPS://APP.EXAMPLE.COM"))
+	assert.False(t, restricted.AllowsOrigin("https://evil.com"))
+
+	wildcard := &ApiKey{AllowedOrigins: []string{"*"}}
+	assert.True(t, wildcard.AllowsOrigin("https://evil.com"))
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const AuditEventTableName = "audit_events"
+
+type AuditAction string
+
+const (
+	AuditActionSignUp               AuditAction = "auth.signup"
+	AuditActionSignIn               AuditAction = "auth.signin"
+	AuditActionSignInMfa            AuditAction = "auth.signin.mfa"
+	AuditActionTokenRefresh         AuditAction = "auth.token.refresh"
+	AuditActionSignOut              AuditAction = "auth.signout"
+	AuditActionAccessDenied         AuditAction = "authorization.denied"
+	AuditActionImpersonationStart   AuditAction = "impersonation.start"
+	AuditActionImpersonationRequest AuditAction = "impersonation.request"
+	AuditActionInviteCreate         AuditAction = "invite.create"
+	AuditActionInviteRevoke         AuditAction = "invite.revoke"
+	AuditActionInviteAccept         AuditAction = "invite.accept"
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "SUCCESS"
+	AuditOutcomeFailure AuditOutcome = "FAILURE"
+	AuditOutcomeDenied  AuditOutcome = "DENIED"
+	// the credentials were right and a mfa challenge was sent instead of the tokens
+	AuditOutcomeChallenged AuditOutcome = "CHALLENGED"
+)
+
+// AuditTarget types name what TargetID refers to
+const (
+	AuditTargetUser    = "user"
+	AuditTargetEmail   = "email"
+	AuditTargetSession = "session"
+	AuditTargetRoute   = "route"
+	AuditTargetInvite  = "invite"
+)
+
+// AuditEvent is a security relevant event, the actor is unknown for failed sign ins and it is
+// the admin for the requests made while impersonating
+type AuditEvent struct {
+	ID         int64
+	Action     AuditAction
+	Outcome    AuditOutcome
+	ActorID    *uuid.UUID
+	TargetType string
+	TargetID   string
+	IP         string
+	UserAgent  string
+	Metadata   map[string]string
+	CreatedAt  time.Time
+}
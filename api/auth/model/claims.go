@@ -0,0 +1,19 @@
+package model
+
+import "github.com/golang-jwt/jwt/v5"
+
+// ActorClaim is the act claim of rfc 8693, it names the user acting on behalf of the subject
+type ActorClaim struct {
+	Subject string `json:"sub"`
+}
+
+// TokenClaims are the claims of the jwt tokens, an impersonation token carries the actor
+// while its subject is the impersonated user
+type TokenClaims struct {
+	jwt.RegisteredClaims
+	Actor *ActorClaim `json:"act,omitempty"`
+}
+
+func (c *TokenClaims) IsImpersonation() bool {
+	return c.Actor != nil
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/google/uuid"
+)
+
+const InviteTableName = "invites"
+
+type InviteStatus string
+
+const (
+	InviteStatusPending  InviteStatus = "PENDING"
+	InviteStatusAccepted InviteStatus = "ACCEPTED"
+	InviteStatusRevoked  InviteStatus = "REVOKED"
+	InviteStatusExpired  InviteStatus = "EXPIRED"
+)
+
+// Invite grants its roles to the user signing up or signing in with the invited email,
+// only the hash of the mailed token is stored
+type Invite struct {
+	ID         uuid.UUID
+	Email      string
+	Roles      []userModel.RoleCode
+	TokenHash  string
+	InvitedBy  *uuid.UUID
+	AcceptedBy *uuid.UUID
+	ExpiresAt  time.Time
+	AcceptedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+func (i *Invite) Status() InviteStatus {
+	switch {
+	case i.AcceptedAt != nil:
+		return InviteStatusAccepted
+	case i.RevokedAt != nil:
+		return InviteStatusRevoked
+	case !i.ExpiresAt.After(time.Now()):
+		return InviteStatusExpired
+	default:
+		return InviteStatusPending
+	}
+}
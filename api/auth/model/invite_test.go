@@ -0,0 +1,20 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvite_Status(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	assert.Equal(t, InviteStatusPending, (&Invite{ExpiresAt: future}).Status())
+	assert.Equal(t, InviteStatusExpired, (&Invite{ExpiresAt: past}).Status())
+	assert.Equal(t, InviteStatusRevoked, (&Invite{ExpiresAt: future, RevokedAt: &now}).Status())
+	// an accepted invite stays accepted after its expiry
+	assert.Equal(t, InviteStatusAccepted, (&Invite{ExpiresAt: past, AcceptedAt: &now}).Status())
+}
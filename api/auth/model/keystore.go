@@ -13,6 +13,12 @@ type Keystore struct {
 	UserID       uuid.UUID
 	PrimaryKey   string
 	SecondaryKey string
+	FamilyID     uuid.UUID
+	ParentID     *uuid.UUID
+	RotatedAt    *time.Time
+	UserAgent    *string
+	IP           *string
+	LastUsedAt   time.Time
 	Status       bool
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const TokenReuseEventTableName = "token_reuse_events"
+
+type TokenReuseEvent struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	FamilyID   uuid.UUID
+	KeystoreID uuid.UUID
+	CreatedAt  time.Time
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const UserMfaTableName = "user_mfa"
+const UserRecoveryCodeTableName = "user_recovery_codes"
+
+// UserMfa holds the totp secret, it is pending until confirmed with a first code
+type UserMfa struct {
+	UserID       uuid.UUID
+	Secret       string
+	LastUsedStep int64
+	ConfirmedAt  *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type UserRecoveryCode struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	CodeHash  string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
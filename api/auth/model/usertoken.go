@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const UserTokenTableName = "user_tokens"
+
+type TokenPurpose string
+
+const (
+	TokenPurposeEmailVerification TokenPurpose = "EMAIL_VERIFICATION"
+	TokenPurposePasswordReset     TokenPurpose = "PASSWORD_RESET"
+	TokenPurposeMagicLink         TokenPurpose = "MAGIC_LINK"
+)
+
+type UserToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Purpose   TokenPurpose
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,92 @@
+package oidc
+
+import (
+	"errors"
+
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/oidc/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+	cookies common.TokenCookies
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+	cookies common.TokenCookies,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/auth/oidc", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+		cookies:        cookies,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(common.KeyPermission(model.GeneralPermission))
+	group.GET("/providers", c.getProvidersHandler)
+	group.POST("/:provider/authorize", c.authorizeHandler)
+	group.POST("/:provider/signin", c.signInHandler)
+}
+
+func (c *controller) getProvidersHandler(ctx *gin.Context) {
+	network.SendSuccessDataResponse(ctx, "success", c.service.GetProviders())
+}
+
+func (c *controller) authorizeHandler(ctx *gin.Context) {
+	param, err := network.ReqParams[dto.OidcProvider](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	data, err := c.service.Authorize(param.Provider)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", data)
+}
+
+func (c *controller) signInHandler(ctx *gin.Context) {
+	param, err := network.ReqParams[dto.OidcProvider](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	body, err := network.ReqBody[dto.OidcSignIn](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	data, challenge, err := c.service.SignIn(param.Provider, body, authDto.NewClientInfo(ctx.Request.UserAgent(), ctx.ClientIP()))
+	if err != nil {
+		var conflictErr *ConflictError
+		if errors.As(err, &conflictErr) {
+			SendConflictError(ctx, conflictErr)
+			return
+		}
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	if challenge != nil {
+		network.SendSuccessDataResponse(ctx, "mfa required", challenge)
+		return
+	}
+
+	common.SendUserAuth(ctx, c.cookies, data)
+}
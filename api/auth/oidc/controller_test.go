@@ -0,0 +1,96 @@
+package oidc
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	mfaDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/oidc/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOidcController_GetProviders(t *testing.T) {
+	service := new(MockService)
+	service.On("GetProviders").Return(dto.NewOidcProviders([]string{"google"}))
+
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "GET", "/auth/oidc/providers", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"providers":["google"]`)
+}
+
+func TestOidcController_AuthorizeNotFound(t *testing.T) {
+	service := new(MockService)
+	service.On("Authorize", "unknown").Return(nil, network.NewNotFoundError("oidc provider not found", nil))
+
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "POST", "/auth/oidc/unknown/authorize", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"oidc provider not found"`)
+}
+
+func TestOidcController_AuthorizeSuccess(t *testing.T) {
+	service := new(MockService)
+	authorization := dto.NewOidcAuthorization("https://accounts.google.com/o/oauth2/v2/auth?state=abc", "abc", time.Now().Add(10*time.Minute))
+	service.On("Authorize", "google").Return(authorization, nil)
+
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "POST", "/auth/oidc/google/authorize", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"state":"abc"`)
+}
+
+func TestOidcController_SignInBadRequest(t *testing.T) {
+	service := new(MockService)
+
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "POST", "/auth/oidc/google/signin", `{"code":"code"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "SignIn", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOidcController_SignInMfaRequired(t *testing.T) {
+	service := new(MockService)
+	challenge := mfaDto.NewMfaChallenge("challenge-token", time.Now().Add(5*time.Minute))
+	service.On("SignIn", "google", &dto.OidcSignIn{Code: "code", State: "state"}, mock.Anything).Return(nil, challenge, nil)
+
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "POST", "/auth/oidc/google/signin", `{"code":"code","state":"state"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"mfa required"`)
+	assert.Contains(t, rr.Body.String(), `"challengeToken":"challenge-token"`)
+}
+
+func TestOidcController_SignInInvalidState(t *testing.T) {
+	service := new(MockService)
+	service.On("SignIn", "google", mock.Anything, mock.Anything).
+		Return(nil, nil, network.NewUnauthorizedError("invalid or expired oidc state", nil))
+
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "POST", "/auth/oidc/google/signin", `{"code":"code","state":"state"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"invalid or expired oidc state"`)
+}
+
+func TestOidcController_SignInConflict(t *testing.T) {
+	service := new(MockService)
+	service.On("SignIn", "google", mock.Anything, mock.Anything).
+		Return(nil, nil, &ConflictError{Message: "the identity is being linked by another sign in, try again"})
+
+	c := NewController(new(network.MockAuthenticationProvider), new(network.MockAuthorizationProvider), service, common.NewTokenCookies(&config.Env{}))
+
+	rr := network.MockTestController(t, "POST", "/auth/oidc/google/signin", `{"code":"code","state":"state"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"the identity is being linked by another sign in, try again"`)
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+// OidcAuthorization is where the client sends the user, the provider returns
+// the code and the state to the configured redirect url
+type OidcAuthorization struct {
+	AuthorizationURL string    `json:"authorizationUrl" validate:"required,url"`
+	State            string    `json:"state" validate:"required"`
+	ExpiresAt        time.Time `json:"expiresAt" validate:"required"`
+}
+
+func NewOidcAuthorization(authorizationURL string, state string, expiresAt time.Time) *OidcAuthorization {
+	return &OidcAuthorization{
+		AuthorizationURL: authorizationURL,
+		State:            state,
+		ExpiresAt:        expiresAt,
+	}
+}
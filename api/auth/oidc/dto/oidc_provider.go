@@ -0,0 +1,5 @@
+package dto
+
+type OidcProvider struct {
+	Provider string `uri:"provider" validate:"required,lowercase,max=50"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+type OidcProviders struct {
+	Providers []string `json:"providers"`
+}
+
+func NewOidcProviders(providers []string) *OidcProviders {
+	return &OidcProviders{
+		Providers: providers,
+	}
+}
@@ -0,0 +1,6 @@
+package dto
+
+type OidcSignIn struct {
+	Code  string `json:"code" validate:"required,max=2000"`
+	State string `json:"state" validate:"required,max=200"`
+}
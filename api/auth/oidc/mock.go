@@ -0,0 +1,42 @@
+package oidc
+
+import (
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	mfaDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/oidc/dto"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) GetProviders() *dto.OidcProviders {
+	args := m.Called()
+	return args.Get(0).(*dto.OidcProviders)
+}
+
+func (m *MockService) Authorize(providerName string) (*dto.OidcAuthorization, error) {
+	args := m.Called(providerName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.OidcAuthorization), args.Error(1)
+}
+
+func (m *MockService) SignIn(
+	providerName string,
+	signInDto *dto.OidcSignIn,
+	clientInfo *authDto.ClientInfo,
+) (*authDto.UserAuth, *mfaDto.MfaChallenge, error) {
+	args := m.Called(providerName, signInDto, clientInfo)
+	var data *authDto.UserAuth
+	if args.Get(0) != nil {
+		data = args.Get(0).(*authDto.UserAuth)
+	}
+	var challenge *mfaDto.MfaChallenge
+	if args.Get(1) != nil {
+		challenge = args.Get(1).(*mfaDto.MfaChallenge)
+	}
+	return data, challenge, args.Error(2)
+}
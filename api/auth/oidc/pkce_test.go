@@ -0,0 +1,23 @@
+package oidc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeChallengeS256(t *testing.T) {
+	// RFC 7636 appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", CodeChallengeS256(verifier))
+}
+
+func TestGenerateCodeVerifier(t *testing.T) {
+	verifier, err := GenerateCodeVerifier()
+	assert.NoError(t, err)
+	assert.Len(t, verifier, 43)
+
+	other, err := GenerateCodeVerifier()
+	assert.NoError(t, err)
+	assert.NotEqual(t, verifier, other)
+}
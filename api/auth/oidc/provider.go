@@ -0,0 +1,292 @@
+package oidc
+
+import (
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/jwks"
+	jwksDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/jwks/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// the provider keys are fetched again for an unknown kid at most this often
+const keysRefreshInterval = time.Minute
+
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	AuthorizedParty string `json:"azp,omitempty"`
+	Nonce           string `json:"nonce,omitempty"`
+	Email           string `json:"email,omitempty"`
+	EmailVerified   bool   `json:"email_verified,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Picture         string `json:"picture,omitempty"`
+}
+
+// Provider is an external oidc identity provider using the authorization code flow with pkce,
+// its metadata and signing keys are discovered lazily and kept in memory
+type Provider interface {
+	Name() string
+	AuthorizationURL(state string, nonce string, codeChallenge string) (string, error)
+	Exchange(code string, codeVerifier string) (string, error)
+	VerifyIDToken(rawIDToken string, nonce string) (*IDTokenClaims, error)
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type provider struct {
+	config     config.OidcProvider
+	httpClient *http.Client
+	mu         sync.Mutex
+	metadata   *metadata
+	keys       map[string]*rsa.PublicKey
+	keysAt     time.Time
+}
+
+func NewProvider(cfg config.OidcProvider) Provider {
+	return &provider{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *provider) Name() string {
+	return p.config.Name
+}
+
+func (p *provider) AuthorizationURL(state string, nonce string, codeChallenge string) (string, error) {
+	meta, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+
+	scopes := p.config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return meta.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems the authorization code and returns the raw id token
+func (p *provider) Exchange(code string, codeVerifier string) (string, error) {
+	meta, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if p.config.ClientSecret != "" {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+
+	res, err := p.httpClient.PostForm(meta.TokenEndpoint, form)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var body tokenResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("oidc %s token response: %w", p.config.Name, err)
+	}
+
+	if res.StatusCode != http.StatusOK || body.Error != "" {
+		return "", fmt.Errorf("oidc %s token request failed with %d: %s %s", p.config.Name, res.StatusCode, body.Error, body.ErrorDescription)
+	}
+
+	if body.IDToken == "" {
+		return "", fmt.Errorf("oidc %s token response without id_token", p.config.Name)
+	}
+
+	return body.IDToken, nil
+}
+
+func (p *provider) VerifyIDToken(rawIDToken string, nonce string) (*IDTokenClaims, error) {
+	meta, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &IDTokenClaims{}
+	_, err = jwt.ParseWithClaims(
+		rawIDToken,
+		claims,
+		p.verificationKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("id token without subject")
+	}
+
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID {
+		return nil, errors.New("id token issued to another party")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("id token nonce mismatch")
+	}
+
+	return claims, nil
+}
+
+func (p *provider) verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, err := p.publicKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// publicKey looks up the signing key, an unknown kid refreshes the keys since the provider may have rotated them
+func (p *provider) publicKey(kid string) (*rsa.PublicKey, error) {
+	meta, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.findKey(kid); ok {
+		return key, nil
+	}
+
+	if p.keys != nil && time.Since(p.keysAt) < keysRefreshInterval {
+		return nil, jwt.ErrTokenUnverifiable
+	}
+
+	keys, err := p.fetchKeys(meta.JwksURI)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+	p.keysAt = time.Now()
+
+	if key, ok := p.findKey(kid); ok {
+		return key, nil
+	}
+	return nil, jwt.ErrTokenUnverifiable
+}
+
+// findKey accepts a token without kid only when the provider publishes a single key
+func (p *provider) findKey(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" {
+		if len(p.keys) == 1 {
+			for _, key := range p.keys {
+				return key, true
+			}
+		}
+		return nil, false
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *provider) fetchKeys(jwksURI string) (map[string]*rsa.PublicKey, error) {
+	var set jwksDto.Jwks
+	if err := p.getJSON(jwksURI, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk == nil || jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwks.DecodePublicKey(jwk.N, jwk.E)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func (p *provider) discover() (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var meta metadata
+	wellKnown := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(wellKnown, &meta); err != nil {
+		return nil, err
+	}
+
+	if meta.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("oidc %s discovery issuer %s does not match %s", p.config.Name, meta.Issuer, p.config.Issuer)
+	}
+
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JwksURI == "" {
+		return nil, fmt.Errorf("oidc %s discovery document is incomplete", p.config.Name)
+	}
+
+	p.metadata = &meta
+	return p.metadata, nil
+}
+
+func (p *provider) getJSON(url string, dest any) error {
+	res, err := p.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc %s request to %s failed with %d", p.config.Name, url, res.StatusCode)
+	}
+
+	return json.NewDecoder(res.Body).Decode(dest)
+}
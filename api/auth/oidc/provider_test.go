@@ -0,0 +1,256 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	jwksDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/jwks/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const fakeClientID = "goserve-client"
+const fakeRedirectURL = "https://goserve.afteracademy.com/oidc/fake/callback"
+
+type fakeGrant struct {
+	codeChallenge string
+	nonce         string
+}
+
+// fakeOidcProvider is a local oidc provider with discovery, jwks and a token endpoint checking pkce
+type fakeOidcProvider struct {
+	t        *testing.T
+	server   *httptest.Server
+	mu       sync.Mutex
+	key      *rsa.PrivateKey
+	kid      string
+	audience string
+	subject  string
+	email    string
+	grants   map[string]fakeGrant
+}
+
+func newFakeOidcProvider(t *testing.T) *fakeOidcProvider {
+	f := &fakeOidcProvider{
+		t:        t,
+		audience: fakeClientID,
+		subject:  "fake-subject",
+		email:    "user@goserve.afteracademy.com",
+		grants:   make(map[string]fakeGrant),
+	}
+	f.rotateKey("key-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 f.server.URL,
+			"authorization_endpoint": f.server.URL + "/authorize",
+			"token_endpoint":         f.server.URL + "/token",
+			"jwks_uri":               f.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		n := base64.RawURLEncoding.EncodeToString(f.key.PublicKey.N.Bytes())
+		e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(f.key.PublicKey.E)).Bytes())
+		json.NewEncoder(w).Encode(&jwksDto.Jwks{Keys: []*jwksDto.Jwk{jwksDto.NewRS256Jwk(f.kid, n, e)}})
+	})
+	mux.HandleFunc("/token", f.tokenHandler)
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+func (f *fakeOidcProvider) config() config.OidcProvider {
+	return config.OidcProvider{
+		Name:         "fake",
+		Issuer:       f.server.URL,
+		ClientID:     fakeClientID,
+		ClientSecret: "secret",
+		RedirectURL:  fakeRedirectURL,
+	}
+}
+
+func (f *fakeOidcProvider) rotateKey(kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(f.t, err)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.key = key
+	f.kid = kid
+}
+
+// authorize plays the user consenting at the provider and returns the code sent to the redirect url
+func (f *fakeOidcProvider) authorize(authorizationURL string) string {
+	parsed, err := url.Parse(authorizationURL)
+	assert.NoError(f.t, err)
+	query := parsed.Query()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	code := "code-" + query.Get("state")
+	f.grants[code] = fakeGrant{
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+	}
+	return code
+}
+
+func (f *fakeOidcProvider) tokenHandler(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	grant, ok := f.grants[r.PostFormValue("code")]
+	delete(f.grants, r.PostFormValue("code"))
+
+	if !ok ||
+		r.PostFormValue("grant_type") != "authorization_code" ||
+		r.PostFormValue("client_id") != fakeClientID ||
+		r.PostFormValue("redirect_uri") != fakeRedirectURL ||
+		CodeChallengeS256(r.PostFormValue("code_verifier")) != grant.codeChallenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := IDTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    f.server.URL,
+			Subject:   f.subject,
+			Audience:  []string{f.audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Nonce:         grant.nonce,
+		Email:         f.email,
+		EmailVerified: true,
+		Name:          "Fake User",
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = f.kid
+	signed, err := token.SignedString(f.key)
+	assert.NoError(f.t, err)
+
+	json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+}
+
+func TestProvider_AuthorizationCodeFlow(t *testing.T) {
+	fake := newFakeOidcProvider(t)
+	p := NewProvider(fake.config())
+
+	codeVerifier, err := GenerateCodeVerifier()
+	assert.NoError(t, err)
+
+	authorizationURL, err := p.AuthorizationURL("state", "nonce", CodeChallengeS256(codeVerifier))
+	assert.NoError(t, err)
+
+	parsed, err := url.Parse(authorizationURL)
+	assert.NoError(t, err)
+	assert.Equal(t, fake.server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, "code", parsed.Query().Get("response_type"))
+	assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+	assert.Equal(t, "openid email profile", parsed.Query().Get("scope"))
+	assert.Equal(t, fakeRedirectURL, parsed.Query().Get("redirect_uri"))
+
+	code := fake.authorize(authorizationURL)
+
+	rawIDToken, err := p.Exchange(code, codeVerifier)
+	assert.NoError(t, err)
+
+	claims, err := p.VerifyIDToken(rawIDToken, "nonce")
+	assert.NoError(t, err)
+	assert.Equal(t, "fake-subject", claims.Subject)
+	assert.Equal(t, "user@goserve.afteracademy.com", claims.Email)
+	assert.True(t, claims.EmailVerified)
+}
+
+func TestProvider_ExchangeWrongCodeVerifier(t *testing.T) {
+	fake := newFakeOidcProvider(t)
+	p := NewProvider(fake.config())
+
+	codeVerifier, _ := GenerateCodeVerifier()
+	authorizationURL, err := p.AuthorizationURL("state", "nonce", CodeChallengeS256(codeVerifier))
+	assert.NoError(t, err)
+	code := fake.authorize(authorizationURL)
+
+	otherVerifier, _ := GenerateCodeVerifier()
+	_, err = p.Exchange(code, otherVerifier)
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestProvider_VerifyNonceMismatch(t *testing.T) {
+	fake := newFakeOidcProvider(t)
+	p := NewProvider(fake.config())
+
+	codeVerifier, _ := GenerateCodeVerifier()
+	authorizationURL, _ := p.AuthorizationURL("state", "nonce", CodeChallengeS256(codeVerifier))
+	rawIDToken, err := p.Exchange(fake.authorize(authorizationURL), codeVerifier)
+	assert.NoError(t, err)
+
+	_, err = p.VerifyIDToken(rawIDToken, "other-nonce")
+	assert.ErrorContains(t, err, "nonce")
+}
+
+func TestProvider_VerifyOtherAudience(t *testing.T) {
+	fake := newFakeOidcProvider(t)
+	fake.audience = "another-client"
+	p := NewProvider(fake.config())
+
+	codeVerifier, _ := GenerateCodeVerifier()
+	authorizationURL, _ := p.AuthorizationURL("state", "nonce", CodeChallengeS256(codeVerifier))
+	rawIDToken, err := p.Exchange(fake.authorize(authorizationURL), codeVerifier)
+	assert.NoError(t, err)
+
+	_, err = p.VerifyIDToken(rawIDToken, "nonce")
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+}
+
+func TestProvider_VerifyAfterKeyRotation(t *testing.T) {
+	fake := newFakeOidcProvider(t)
+	p := NewProvider(fake.config())
+
+	login := func() string {
+		codeVerifier, _ := GenerateCodeVerifier()
+		authorizationURL, _ := p.AuthorizationURL("state", "nonce", CodeChallengeS256(codeVerifier))
+		rawIDToken, err := p.Exchange(fake.authorize(authorizationURL), codeVerifier)
+		assert.NoError(t, err)
+		return rawIDToken
+	}
+
+	_, err := p.VerifyIDToken(login(), "nonce")
+	assert.NoError(t, err)
+
+	fake.rotateKey("key-2")
+	rotated := login()
+
+	// the keys were just fetched, an unknown kid does not hammer the provider
+	_, err = p.VerifyIDToken(rotated, "nonce")
+	assert.Error(t, err)
+
+	p.(*provider).keysAt = time.Now().Add(-keysRefreshInterval)
+	_, err = p.VerifyIDToken(rotated, "nonce")
+	assert.NoError(t, err)
+}
+
+func TestProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	fake := newFakeOidcProvider(t)
+	cfg := fake.config()
+	cfg.Issuer = fake.server.URL + "/"
+	p := NewProvider(cfg)
+
+	_, err := p.AuthorizationURL("state", "nonce", "challenge")
+	assert.ErrorContains(t, err, "does not match")
+}
@@ -0,0 +1,22 @@
+package oidc
+
+import (
+	"net/http"
+
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+// same as the failure code of the network package responses
+const failureResCode network.ResCode = "10001"
+
+// SendConflictError responds with 409 since network.SendMixedError only knows the standard api errors
+func SendConflictError(ctx *gin.Context, err *ConflictError) {
+	network.SendCustomResponse[any](
+		ctx,
+		failureResCode,
+		http.StatusConflict,
+		err.Message,
+		nil,
+	)
+}
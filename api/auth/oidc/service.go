@@ -0,0 +1,381 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	mfaDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/oidc/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve-example-api-server-postgres/utils"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/afteracademy/goserve/v2/redis"
+	"github.com/afteracademy/goserve/v2/utility"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// the postgres error code of a unique constraint violation
+const uniqueViolation = "23505"
+
+// ConflictError is returned when a concurrent sign in claimed the identity or the email first
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+type Service interface {
+	GetProviders() *dto.OidcProviders
+	Authorize(providerName string) (*dto.OidcAuthorization, error)
+	SignIn(providerName string, signInDto *dto.OidcSignIn, clientInfo *authDto.ClientInfo) (*authDto.UserAuth, *mfaDto.MfaChallenge, error)
+}
+
+// oidcState is kept in redis between the authorization and the sign in, it is single use
+type oidcState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"codeVerifier"`
+}
+
+type service struct {
+	db            postgres.Database
+	store         redis.Store
+	authService   auth.Service
+	userService   user.Service
+	providers     map[string]Provider
+	providerNames []string
+	stateValidity time.Duration
+}
+
+func NewService(
+	db postgres.Database,
+	env *config.Env,
+	store redis.Store,
+	authService auth.Service,
+	userService user.Service,
+) Service {
+	providers := make([]Provider, 0, len(env.OidcProviders))
+	for _, cfg := range env.OidcProviders {
+		providers = append(providers, NewProvider(cfg))
+	}
+	return newService(db, store, authService, userService, time.Duration(env.OidcStateValiditySec)*time.Second, providers...)
+}
+
+func newService(
+	db postgres.Database,
+	store redis.Store,
+	authService auth.Service,
+	userService user.Service,
+	stateValidity time.Duration,
+	providers ...Provider,
+) *service {
+	s := &service{
+		db:            db,
+		store:         store,
+		authService:   authService,
+		userService:   userService,
+		providers:     make(map[string]Provider),
+		providerNames: make([]string, 0, len(providers)),
+		stateValidity: stateValidity,
+	}
+	for _, provider := range providers {
+		s.providers[provider.Name()] = provider
+		s.providerNames = append(s.providerNames, provider.Name())
+	}
+	return s
+}
+
+func stateKey(state string) string {
+	return "oidc_state_" + utils.HashToken(state)
+}
+
+func (s *service) GetProviders() *dto.OidcProviders {
+	return dto.NewOidcProviders(s.providerNames)
+}
+
+func (s *service) provider(name string) (Provider, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, network.NewNotFoundError("oidc provider not found", nil)
+	}
+	return provider, nil
+}
+
+func (s *service) Authorize(providerName string) (*dto.OidcAuthorization, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := utility.GenerateRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := utility.GenerateRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	codeVerifier, err := GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	authorizationURL, err := provider.AuthorizationURL(state, nonce, CodeChallengeS256(codeVerifier))
+	if err != nil {
+		log.Printf("oidc provider %s is unavailable: %v", providerName, err)
+		return nil, network.NewInternalServerError("oidc provider is unavailable", err)
+	}
+
+	data, err := json.Marshal(&oidcState{
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	err = s.store.GetInstance().Set(ctx, stateKey(state), data, s.stateValidity).Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewOidcAuthorization(authorizationURL, state, time.Now().Add(s.stateValidity)), nil
+}
+
+// SignIn completes the login at the provider, the user is found by the linked identity,
+// linked by a verified email or created, and then signed in like with a password
+func (s *service) SignIn(
+	providerName string,
+	signInDto *dto.OidcSignIn,
+	clientInfo *authDto.ClientInfo,
+) (*authDto.UserAuth, *mfaDto.MfaChallenge, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	state, err := s.consumeState(signInDto.State)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if state.Provider != providerName {
+		return nil, nil, network.NewUnauthorizedError("invalid or expired oidc state", nil)
+	}
+
+	rawIDToken, err := provider.Exchange(signInDto.Code, state.CodeVerifier)
+	if err != nil {
+		return nil, nil, network.NewUnauthorizedError("oidc code exchange failed", err)
+	}
+
+	claims, err := provider.VerifyIDToken(rawIDToken, state.Nonce)
+	if err != nil {
+		return nil, nil, network.NewUnauthorizedError("invalid oidc id token", err)
+	}
+
+	user, err := s.resolveUser(providerName, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.authService.SignInUser(user, clientInfo)
+}
+
+func (s *service) consumeState(state string) (*oidcState, error) {
+	ctx := context.Background()
+
+	data, err := s.store.GetInstance().GetDel(ctx, stateKey(state)).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, network.NewUnauthorizedError("invalid or expired oidc state", nil)
+		}
+		return nil, err
+	}
+
+	var value oidcState
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, network.NewUnauthorizedError("invalid or expired oidc state", err)
+	}
+
+	return &value, nil
+}
+
+func (s *service) resolveUser(providerName string, claims *IDTokenClaims) (*userModel.User, error) {
+	ctx := context.Background()
+
+	identity, err := s.findIdentity(ctx, providerName, claims.Subject)
+	if err == nil {
+		s.touchIdentity(ctx, identity)
+		user, err := s.userService.FetchUserById(identity.UserID)
+		if err != nil {
+			return nil, network.NewUnauthorizedError("user is not available", err)
+		}
+		return user, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	// an email only proves the account owner when the provider verified it
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, network.NewBadRequestError("a verified email is required from the oidc provider", nil)
+	}
+
+	user, err := s.userService.FetchUserByEmail(claims.Email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s.createUser(providerName, claims)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// an unverified account could have been registered by anyone with this email
+	if !user.Verified {
+		return nil, network.NewForbiddenError("permission denied: verify your email before signing in with "+providerName, nil)
+	}
+
+	err = linkIdentity(ctx, s.db.Pool(), user, providerName, claims)
+	if err != nil {
+		return nil, identityError(err)
+	}
+
+	return user, nil
+}
+
+// createUser creates the user along with its identity, a disabled account keeps its email
+func (s *service) createUser(providerName string, claims *IDTokenClaims) (*userModel.User, error) {
+	exists, err := s.userService.IsEmailExists(claims.Email)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, network.NewForbiddenError("permission denied: account is disabled", nil)
+	}
+
+	role, err := s.userService.FetchRoleByCode(userModel.RoleCodeLearner)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name = strings.Split(claims.Email, "@")[0]
+	}
+
+	var profilePicURL *string
+	if claims.Picture != "" {
+		profilePicURL = &claims.Picture
+	}
+
+	user, err := s.userService.CreateExternalUser(
+		claims.Email, name, profilePicURL, true, []*userModel.Role{role},
+		func(ctx context.Context, tx pgx.Tx, user *userModel.User) error {
+			return linkIdentity(ctx, tx, user, providerName, claims)
+		},
+	)
+	if err != nil {
+		return nil, identityError(err)
+	}
+
+	return user, nil
+}
+
+// identityError reports the unique violations of a concurrent sign in with the same identity
+// or email as a conflict, the retry finds the identity linked by the other sign in
+func identityError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return &ConflictError{Message: "the identity is being linked by another sign in, try again"}
+	}
+	return err
+}
+
+func (s *service) findIdentity(ctx context.Context, providerName string, subject string) (*model.UserIdentity, error) {
+	query := `
+		SELECT
+			id,
+			user_id,
+			provider,
+			subject,
+			email,
+			last_login_at,
+			created_at,
+			updated_at
+		FROM user_identities
+		WHERE provider = $1
+		  AND subject = $2
+	`
+
+	var identity model.UserIdentity
+
+	err := s.db.Pool().QueryRow(ctx, query, providerName, subject).
+		Scan(
+			&identity.ID,
+			&identity.UserID,
+			&identity.Provider,
+			&identity.Subject,
+			&identity.Email,
+			&identity.LastLoginAt,
+			&identity.CreatedAt,
+			&identity.UpdatedAt,
+		)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+func linkIdentity(ctx context.Context, db execer, user *userModel.User, providerName string, claims *IDTokenClaims) error {
+	query := `
+		INSERT INTO user_identities (
+			user_id,
+			provider,
+			subject,
+			email
+		)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := db.Exec(ctx, query, user.ID, providerName, claims.Subject, claims.Email)
+	return err
+}
+
+func (s *service) touchIdentity(ctx context.Context, identity *model.UserIdentity) {
+	query := `
+		UPDATE user_identities
+		SET
+			last_login_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	_, err := s.db.Pool().Exec(ctx, query, identity.ID)
+	if err != nil {
+		log.Printf("oidc identity %s login could not be recorded: %v", identity.ID, err)
+	}
+}
@@ -0,0 +1,22 @@
+package oidc
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityError(t *testing.T) {
+	unique := fmt.Errorf("insert identity: %w", &pgconn.PgError{Code: uniqueViolation})
+	var conflictErr *ConflictError
+	assert.ErrorAs(t, identityError(unique), &conflictErr)
+
+	other := &pgconn.PgError{Code: "23503"}
+	assert.Same(t, error(other), identityError(other))
+
+	plain := errors.New("connection reset")
+	assert.Equal(t, plain, identityError(plain))
+}
@@ -0,0 +1,84 @@
+package password
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/password/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/auth/password", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(common.KeyPermission(model.GeneralPermission))
+	group.POST("/forgot", c.forgotPasswordHandler)
+	group.POST("/reset", c.resetPasswordHandler)
+	group.PUT("/change", c.Authentication(), common.RejectImpersonation(), c.changePasswordHandler)
+}
+
+func (c *controller) forgotPasswordHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.PasswordForgot](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	err = c.service.ForgotPassword(body)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "if the email is registered, a reset link has been sent")
+}
+
+func (c *controller) resetPasswordHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.PasswordReset](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	err = c.service.ResetPassword(body)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "password reset successfully")
+}
+
+func (c *controller) changePasswordHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.PasswordChange](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	err = c.service.ChangePassword(body, user)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "password changed successfully")
+}
@@ -0,0 +1,69 @@
+package password
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/password/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordController_ForgotUnknownEmail(t *testing.T) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	service := new(MockService)
+	service.On("ForgotPassword", &dto.PasswordForgot{Email: "unknown@abc.com"}).Return(nil)
+
+	c := NewController(mockAuthProvider, new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "POST", "/auth/password/forgot", `{"email":"unknown@abc.com"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"if the email is registered, a reset link has been sent"`)
+}
+
+func TestPasswordController_ChangeSamePassword(t *testing.T) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	service := new(MockService)
+
+	c := NewController(mockAuthProvider, new(network.MockAuthorizationProvider), service)
+
+	body := `{"currentPassword":"123456","newPassword":"123456"}`
+	rr := network.MockTestController(t, "PUT", "/auth/password/change", body, common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "ChangePassword")
+}
+
+func TestPasswordController_ChangeSuccess(t *testing.T) {
+	user := &userModel.User{ID: uuid.New()}
+
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, user)
+		ctx.Next()
+	}))
+
+	changeDto := &dto.PasswordChange{CurrentPassword: "123456", NewPassword: "654321"}
+
+	service := new(MockService)
+	service.On("ChangePassword", changeDto, user).Return(nil)
+
+	c := NewController(mockAuthProvider, new(network.MockAuthorizationProvider), service)
+
+	body := `{"currentPassword":"123456","newPassword":"654321"}`
+	rr := network.MockTestController(t, "PUT", "/auth/password/change", body, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"password changed successfully"`)
+	service.AssertExpectations(t)
+}
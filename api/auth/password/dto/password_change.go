@@ -0,0 +1,6 @@
+package dto
+
+type PasswordChange struct {
+	CurrentPassword string `json:"currentPassword" binding:"required" validate:"required,min=6,max=100"`
+	NewPassword     string `json:"newPassword" binding:"required" validate:"required,min=6,max=100,nefield=CurrentPassword"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type PasswordForgot struct {
+	Email string `json:"email" binding:"required" validate:"required,email"`
+}
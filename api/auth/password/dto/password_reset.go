@@ -0,0 +1,6 @@
+package dto
+
+type PasswordReset struct {
+	Token    string `json:"token" binding:"required" validate:"required"`
+	Password string `json:"password" binding:"required" validate:"required,min=6,max=100"`
+}
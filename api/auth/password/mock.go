@@ -0,0 +1,26 @@
+package password
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/password/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) ForgotPassword(forgotDto *dto.PasswordForgot) error {
+	args := m.Called(forgotDto)
+	return args.Error(0)
+}
+
+func (m *MockService) ResetPassword(resetDto *dto.PasswordReset) error {
+	args := m.Called(resetDto)
+	return args.Error(0)
+}
+
+func (m *MockService) ChangePassword(changeDto *dto.PasswordChange, user *userModel.User) error {
+	args := m.Called(changeDto, user)
+	return args.Error(0)
+}
@@ -0,0 +1,162 @@
+package password
+
+import (
+	"log"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/hasher"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/lockout"
+	lockoutDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/lockout/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/password/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/verification"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve-example-api-server-postgres/mailer"
+	"github.com/afteracademy/goserve/v2/network"
+)
+
+type Service interface {
+	ForgotPassword(forgotDto *dto.PasswordForgot) error
+	ResetPassword(resetDto *dto.PasswordReset) error
+	ChangePassword(changeDto *dto.PasswordChange, user *userModel.User) error
+}
+
+type service struct {
+	authService         auth.Service
+	userService         user.Service
+	verificationService verification.Service
+	lockoutService      lockout.Service
+	mailer              mailer.Sender
+	hasher              hasher.Hasher
+	passwordPolicy      hasher.Policy
+	// reset
+	resetUrl       string
+	resetValidity  time.Duration
+	resendCooldown time.Duration
+}
+
+func NewService(
+	env *config.Env,
+	authService auth.Service,
+	userService user.Service,
+	verificationService verification.Service,
+	lockoutService lockout.Service,
+	mailer mailer.Sender,
+	passwordHasher hasher.Hasher,
+	passwordPolicy hasher.Policy,
+) Service {
+	return &service{
+		authService:         authService,
+		userService:         userService,
+		verificationService: verificationService,
+		lockoutService:      lockoutService,
+		mailer:              mailer,
+		hasher:              passwordHasher,
+		passwordPolicy:      passwordPolicy,
+		resetUrl:            env.PasswordResetUrl,
+		resetValidity:       time.Duration(env.PasswordResetValiditySec) * time.Second,
+		resendCooldown:      time.Duration(env.PasswordResetResendSec) * time.Second,
+	}
+}
+
+// ForgotPassword does not report unknown emails so that it can't be used to discover accounts
+func (s *service) ForgotPassword(forgotDto *dto.PasswordForgot) error {
+	user, err := s.userService.FetchUserByEmail(forgotDto.Email)
+	if err != nil {
+		return nil
+	}
+
+	lastSentAt, err := s.verificationService.LastTokenIssuedAt(user, model.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	if lastSentAt != nil && time.Since(*lastSentAt) < s.resendCooldown {
+		return nil
+	}
+
+	token, err := s.verificationService.IssueToken(user, model.TokenPurposePasswordReset, s.resetValidity)
+	if err != nil {
+		return err
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: "Hi " + user.Name + ",\n\n" +
+			"We received a request to reset your password. Open the link below to choose a new one:\n" +
+			s.resetUrl + "?token=" + token + "\n\n" +
+			"The link expires in " + s.resetValidity.String() + ". " +
+			"If you did not request it, you can ignore this email.",
+	}
+
+	return s.mailer.Send(msg)
+}
+
+func (s *service) ResetPassword(resetDto *dto.PasswordReset) error {
+	token, err := s.verificationService.ConsumeToken(resetDto.Token, model.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userService.FetchUserById(token.UserID)
+	if err != nil {
+		return network.NewBadRequestError("invalid or expired token", err)
+	}
+
+	return s.updatePassword(user, resetDto.Password)
+}
+
+func (s *service) ChangePassword(changeDto *dto.PasswordChange, user *userModel.User) error {
+	// the auth context user is loaded without the password hash
+	existing, err := s.userService.FetchUserByEmail(user.Email)
+	if err != nil {
+		return network.NewNotFoundError("user does not exists", err)
+	}
+
+	if existing.Password == nil {
+		return network.NewBadRequestError("password is not set for this account", nil)
+	}
+
+	ok, _, err := s.hasher.Verify(changeDto.CurrentPassword, *existing.Password)
+	if !ok {
+		return network.NewUnauthorizedError("wrong password", err)
+	}
+
+	return s.updatePassword(existing, changeDto.NewPassword)
+}
+
+func (s *service) updatePassword(user *userModel.User, password string) error {
+	err := s.passwordPolicy.Check(password, user.Email, user.Name)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	err = s.userService.UpdateUserPassword(user.ID, hashed)
+	if err != nil {
+		return err
+	}
+
+	// every token issued with the old password must stop working
+	err = s.authService.SignOutAll(user)
+	if err != nil {
+		log.Printf("sessions for %s could not be revoked after password update: %v", user.ID, err)
+		return err
+	}
+
+	// the failures were made against the old password, the owner can sign in with the new one
+	err = s.lockoutService.ClearLockout(&lockoutDto.LockoutClear{Email: user.Email})
+	if err != nil {
+		log.Printf("lockout for %s could not be cleared after password update: %v", user.ID, err)
+	}
+
+	return nil
+}
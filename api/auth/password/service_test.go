@@ -0,0 +1,46 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/hasher"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/lockout"
+	lockoutDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/lockout/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/password/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/verification"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPasswordService_ResetPasswordClearsLockout(t *testing.T) {
+	authService := new(auth.MockService)
+	userService := new(user.MockService)
+	verificationService := new(verification.MockService)
+	lockoutService := new(lockout.MockService)
+
+	u := &userModel.User{ID: uuid.New(), Email: "locked@abc.com", Name: "locked"}
+	token := &model.UserToken{UserID: u.ID}
+
+	verificationService.On("ConsumeToken", "token", model.TokenPurposePasswordReset).Return(token, nil)
+	userService.On("FetchUserById", u.ID).Return(u, nil)
+	userService.On("UpdateUserPassword", u.ID, mock.Anything).Return(nil)
+	authService.On("SignOutAll", u).Return(nil)
+	lockoutService.On("ClearLockout", &lockoutDto.LockoutClear{Email: u.Email}).Return(nil)
+
+	env := &config.Env{BcryptCost: 4, PasswordHashAlgorithm: hasher.AlgorithmBcrypt}
+	s := NewService(
+		env, authService, userService, verificationService, lockoutService, nil,
+		hasher.NewHasher(env), hasher.NewPolicyFromList(8, nil),
+	)
+
+	err := s.ResetPassword(&dto.PasswordReset{Token: "token", Password: "blue-Ocean7"})
+	assert.NoError(t, err)
+	lockoutService.AssertExpectations(t)
+	authService.AssertExpectations(t)
+}
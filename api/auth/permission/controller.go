@@ -0,0 +1,58 @@
+package permission
+
+import (
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/admin/permissions", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(
+		common.KeyPermission(authModel.AdminPermission),
+		c.Authentication(),
+		common.RejectImpersonation(),
+		c.Authorization(string(userModel.PermissionPermissionManage)),
+	)
+	group.GET("", c.getRolePermissionsHandler)
+	group.POST("/reload", c.reloadHandler)
+}
+
+func (c *controller) getRolePermissionsHandler(ctx *gin.Context) {
+	rolePermissions, err := c.service.GetRolePermissions()
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", &rolePermissions)
+}
+
+func (c *controller) reloadHandler(ctx *gin.Context) {
+	err := c.service.Reload()
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "permissions reloaded successfully")
+}
@@ -0,0 +1,68 @@
+package permission
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/permission/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func mockProviders() (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider) {
+	authProvider := new(network.MockAuthenticationProvider)
+	authProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+	authorizeProvider := new(network.MockAuthorizationProvider)
+	authorizeProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+	return authProvider, authorizeProvider
+}
+
+func TestPermissionController_GetRolePermissions(t *testing.T) {
+	service := new(MockService)
+	service.On("GetRolePermissions").Return([]*dto.RolePermissions{
+		dto.NewRolePermissions(userModel.RoleCodeEditor, []userModel.PermissionCode{
+			userModel.PermissionBlogPublish,
+			userModel.PermissionBlogWrite,
+		}),
+	}, nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/admin/permissions", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"permissions":["blog.publish","blog.write"]`)
+}
+
+func TestPermissionController_ReloadFailure(t *testing.T) {
+	service := new(MockService)
+	service.On("Reload").Return(errors.New("connection refused"))
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "POST", "/admin/permissions/reload", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestPermissionController_ReloadSuccess(t *testing.T) {
+	service := new(MockService)
+	service.On("Reload").Return(nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "POST", "/admin/permissions/reload", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permissions reloaded successfully"`)
+	service.AssertExpectations(t)
+}
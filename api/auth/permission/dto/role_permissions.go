@@ -0,0 +1,18 @@
+package dto
+
+import (
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+)
+
+// RolePermissions lists the effective permissions of a role, the inherited ones included
+type RolePermissions struct {
+	Role        userModel.RoleCode         `json:"role" validate:"required"`
+	Permissions []userModel.PermissionCode `json:"permissions" validate:"required"`
+}
+
+func NewRolePermissions(role userModel.RoleCode, permissions []userModel.PermissionCode) *RolePermissions {
+	return &RolePermissions{
+		Role:        role,
+		Permissions: permissions,
+	}
+}
@@ -0,0 +1,29 @@
+package permission
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/permission/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) HasAnyPermission(role userModel.RoleCode, codes ...userModel.PermissionCode) bool {
+	args := m.Called(role, codes)
+	return args.Bool(0)
+}
+
+func (m *MockService) GetRolePermissions() ([]*dto.RolePermissions, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.RolePermissions), args.Error(1)
+}
+
+func (m *MockService) Reload() error {
+	args := m.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,252 @@
+package permission
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/permission/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve/v2/postgres"
+)
+
+// Service keeps the permissions of every role, with the inherited roles expanded, in memory
+// for the authorization. The sets are reloaded from the database once they are older than
+// the reload interval or when Reload is called.
+type Service interface {
+	HasAnyPermission(role userModel.RoleCode, codes ...userModel.PermissionCode) bool
+	GetRolePermissions() ([]*dto.RolePermissions, error)
+	Reload() error
+}
+
+type permissionSet map[userModel.PermissionCode]bool
+
+type service struct {
+	db             postgres.Database
+	reloadInterval time.Duration
+	mu             sync.RWMutex
+	sets           map[userModel.RoleCode]permissionSet
+	loadedAt       time.Time
+	reloading      sync.Mutex
+}
+
+func NewService(db postgres.Database, env *config.Env) Service {
+	return &service{
+		db:             db,
+		reloadInterval: time.Duration(env.PermissionReloadSec) * time.Second,
+	}
+}
+
+func (s *service) HasAnyPermission(role userModel.RoleCode, codes ...userModel.PermissionCode) bool {
+	set := s.permissions()[role]
+	for _, code := range codes {
+		if set[code] {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *service) GetRolePermissions() ([]*dto.RolePermissions, error) {
+	sets := s.permissions()
+	if sets == nil {
+		if err := s.Reload(); err != nil {
+			return nil, err
+		}
+		sets = s.permissions()
+	}
+
+	dtos := make([]*dto.RolePermissions, 0, len(sets))
+	for role, set := range sets {
+		codes := make([]userModel.PermissionCode, 0, len(set))
+		for code := range set {
+			codes = append(codes, code)
+		}
+		slices.Sort(codes)
+		dtos = append(dtos, dto.NewRolePermissions(role, codes))
+	}
+	slices.SortFunc(dtos, func(a, b *dto.RolePermissions) int {
+		if a.Role < b.Role {
+			return -1
+		}
+		if a.Role > b.Role {
+			return 1
+		}
+		return 0
+	})
+
+	return dtos, nil
+}
+
+// permissions returns the current sets, stale sets are reloaded by a single caller
+// while the others keep using them
+func (s *service) permissions() map[userModel.RoleCode]permissionSet {
+	s.mu.RLock()
+	sets, loadedAt := s.sets, s.loadedAt
+	s.mu.RUnlock()
+
+	if sets != nil && time.Since(loadedAt) < s.reloadInterval {
+		return sets
+	}
+
+	if sets == nil {
+		s.reloading.Lock()
+	} else if !s.reloading.TryLock() {
+		return sets
+	}
+	defer s.reloading.Unlock()
+
+	s.mu.RLock()
+	fresh := s.sets != nil && time.Since(s.loadedAt) < s.reloadInterval
+	s.mu.RUnlock()
+
+	if !fresh {
+		if err := s.load(); err != nil {
+			log.Printf("role permissions could not be reloaded: %v", err)
+			// retried after the interval, the old sets keep working meanwhile
+			s.mu.Lock()
+			s.loadedAt = time.Now()
+			s.mu.Unlock()
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sets
+}
+
+func (s *service) Reload() error {
+	s.reloading.Lock()
+	defer s.reloading.Unlock()
+	return s.load()
+}
+
+func (s *service) load() error {
+	ctx := context.Background()
+
+	grants, err := s.findGrants(ctx)
+	if err != nil {
+		return err
+	}
+
+	inherits, err := s.findInheritance(ctx)
+	if err != nil {
+		return err
+	}
+
+	sets := expand(grants, inherits)
+
+	s.mu.Lock()
+	s.sets = sets
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *service) findGrants(ctx context.Context) (map[userModel.RoleCode][]userModel.PermissionCode, error) {
+	query := `
+		SELECT
+			r.code,
+			p.code
+		FROM roles r
+		LEFT JOIN role_permissions rp
+			ON rp.role_id = r.id
+		LEFT JOIN permissions p
+			ON p.id = rp.permission_id
+		WHERE r.status = TRUE
+	`
+
+	rows, err := s.db.Pool().Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := make(map[userModel.RoleCode][]userModel.PermissionCode)
+
+	for rows.Next() {
+		var role userModel.RoleCode
+		var code *userModel.PermissionCode
+		if err := rows.Scan(&role, &code); err != nil {
+			return nil, err
+		}
+		// roles without own permissions are kept for the inherited ones
+		codes := grants[role]
+		if code != nil {
+			codes = append(codes, *code)
+		}
+		grants[role] = codes
+	}
+
+	return grants, rows.Err()
+}
+
+func (s *service) findInheritance(ctx context.Context) (map[userModel.RoleCode][]userModel.RoleCode, error) {
+	query := `
+		SELECT
+			r.code,
+			i.code
+		FROM role_inheritance ri
+		INNER JOIN roles r
+			ON r.id = ri.role_id
+		INNER JOIN roles i
+			ON i.id = ri.inherited_role_id
+		WHERE r.status = TRUE
+		  AND i.status = TRUE
+	`
+
+	rows, err := s.db.Pool().Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inherits := make(map[userModel.RoleCode][]userModel.RoleCode)
+
+	for rows.Next() {
+		var role, inherited userModel.RoleCode
+		if err := rows.Scan(&role, &inherited); err != nil {
+			return nil, err
+		}
+		inherits[role] = append(inherits[role], inherited)
+	}
+
+	return inherits, rows.Err()
+}
+
+// expand adds the permissions of the inherited roles to every role, cycles in the
+// inheritance are tolerated since a role is visited once
+func expand(
+	grants map[userModel.RoleCode][]userModel.PermissionCode,
+	inherits map[userModel.RoleCode][]userModel.RoleCode,
+) map[userModel.RoleCode]permissionSet {
+	sets := make(map[userModel.RoleCode]permissionSet, len(grants))
+
+	for role := range grants {
+		set := make(permissionSet)
+		visited := map[userModel.RoleCode]bool{}
+		pending := []userModel.RoleCode{role}
+
+		for len(pending) > 0 {
+			current := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			if visited[current] {
+				continue
+			}
+			visited[current] = true
+
+			for _, code := range grants[current] {
+				set[code] = true
+			}
+			pending = append(pending, inherits[current]...)
+		}
+
+		sets[role] = set
+	}
+
+	return sets
+}
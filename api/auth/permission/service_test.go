@@ -0,0 +1,51 @@
+package permission
+
+import (
+	"testing"
+
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpand_Inheritance(t *testing.T) {
+	grants := map[userModel.RoleCode][]userModel.PermissionCode{
+		userModel.RoleCodeLearner: nil,
+		userModel.RoleCodeAuthor:  {userModel.PermissionBlogWrite},
+		userModel.RoleCodeEditor:  {userModel.PermissionBlogPublish},
+		userModel.RoleCodeAdmin:   {userModel.PermissionApiKeyManage},
+	}
+	inherits := map[userModel.RoleCode][]userModel.RoleCode{
+		userModel.RoleCodeAdmin:  {userModel.RoleCodeEditor},
+		userModel.RoleCodeEditor: {userModel.RoleCodeAuthor},
+	}
+
+	sets := expand(grants, inherits)
+
+	assert.Empty(t, sets[userModel.RoleCodeLearner])
+	assert.Equal(t, permissionSet{userModel.PermissionBlogWrite: true}, sets[userModel.RoleCodeAuthor])
+	assert.Equal(t, permissionSet{
+		userModel.PermissionBlogWrite:   true,
+		userModel.PermissionBlogPublish: true,
+	}, sets[userModel.RoleCodeEditor])
+	assert.Equal(t, permissionSet{
+		userModel.PermissionBlogWrite:    true,
+		userModel.PermissionBlogPublish:  true,
+		userModel.PermissionApiKeyManage: true,
+	}, sets[userModel.RoleCodeAdmin])
+}
+
+func TestExpand_Cycle(t *testing.T) {
+	grants := map[userModel.RoleCode][]userModel.PermissionCode{
+		"A": {"a.read"},
+		"B": {"b.read"},
+	}
+	inherits := map[userModel.RoleCode][]userModel.RoleCode{
+		"A": {"B"},
+		"B": {"A"},
+	}
+
+	sets := expand(grants, inherits)
+
+	assert.Equal(t, permissionSet{"a.read": true, "b.read": true}, sets["A"])
+	assert.Equal(t, permissionSet{"a.read": true, "b.read": true}, sets["B"])
+}
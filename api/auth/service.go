@@ -2,11 +2,19 @@ package auth
 
 import (
 	"context"
-	"crypto/rsa"
+	"log"
 	"time"
 
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/cache"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/hasher"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/jwks"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/lockout"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa"
+	mfaDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa/dto"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/verification"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
 	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
 	"github.com/afteracademy/goserve-example-api-server-postgres/config"
@@ -16,22 +24,29 @@ import (
 	"github.com/afteracademy/goserve/v2/utility"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/jackc/pgx/v5"
 )
 
 type Service interface {
-	SignUpBasic(signUpDto *dto.SignUpBasic) (*dto.UserAuth, error)
-	SignInBasic(signInDto *dto.SignInBasic) (*dto.UserAuth, error)
-	RenewToken(tokenRefreshDto *dto.TokenRefresh, accessToken string) (*dto.Tokens, error)
-	SignOut(keystore *model.Keystore) error
+	SignUpBasic(signUpDto *dto.SignUpBasic, clientInfo *dto.ClientInfo) (*dto.UserAuth, error)
+	SignInBasic(signInDto *dto.SignInBasic, clientInfo *dto.ClientInfo) (*dto.UserAuth, *mfaDto.MfaChallenge, error)
+	SignInMfa(verifyDto *mfaDto.MfaVerify, clientInfo *dto.ClientInfo) (*dto.UserAuth, error)
+	SignInUser(user *userModel.User, clientInfo *dto.ClientInfo) (*dto.UserAuth, *mfaDto.MfaChallenge, error)
+	RenewToken(tokenRefreshDto *dto.TokenRefresh, accessToken string, clientInfo *dto.ClientInfo) (*dto.Tokens, error)
+	SignOut(keystore *model.Keystore, clientInfo *dto.ClientInfo) error
+	SignOutAll(user *userModel.User) error
 	IsEmailRegisted(email string) bool
-	GenerateToken(user *userModel.User) (string, string, error)
+	GenerateToken(user *userModel.User, clientInfo *dto.ClientInfo) (string, string, error)
 	FetchKeystore(client *userModel.User, primaryKey string) (*model.Keystore, error)
-	VerifyToken(tokenStr string) (*jwt.RegisteredClaims, error)
-	DecodeToken(tokenStr string) (*jwt.RegisteredClaims, error)
+	TouchKeystore(keystore *model.Keystore) error
+	GenerateImpersonationToken(actor *userModel.User, keystore *model.Keystore, user *userModel.User) (string, time.Time, error)
+	VerifyToken(tokenStr string) (*model.TokenClaims, error)
+	DecodeToken(tokenStr string) (*model.TokenClaims, error)
 	SignToken(claims jwt.RegisteredClaims) (string, error)
-	ValidateClaims(claims *jwt.RegisteredClaims) bool
+	ValidateClaims(claims *model.TokenClaims) bool
 	FetchApiKey(key string) (*model.ApiKey, error)
+	FetchAccessToken(token string) (*model.AccessToken, error)
+	TouchAccessToken(accessToken *model.AccessToken) error
 
 	/*--------only for tests----------*/
 	CreateApiKey(key string, version int, permissions []model.Permission, comments []string) (*model.ApiKey, error)
@@ -40,56 +55,69 @@ type Service interface {
 }
 
 type service struct {
-	db          postgres.Database
-	userService user.Service
+	db                  postgres.Database
+	userService         user.Service
+	verificationService verification.Service
+	lockoutService      lockout.Service
+	mfaService          mfa.Service
+	authCache           cache.Service
+	auditService        audit.Service
+	hasher              hasher.Hasher
+	passwordPolicy      hasher.Policy
+	// compared against when the user is unknown so that the response time reveals nothing
+	dummyPasswordHash string
 	// token
-	rsaPrivateKey        *rsa.PrivateKey
-	rsaPublicKey         *rsa.PublicKey
+	keyRing              jwks.KeyRing
 	accessTokenValidity  time.Duration
 	refreshTokenValidity time.Duration
 	tokenIssuer          string
 	tokenAudience        string
+	// impersonation
+	impersonationTokenValidity time.Duration
 }
 
 func NewService(
 	db postgres.Database,
 	env *config.Env,
+	keyRing jwks.KeyRing,
 	userService user.Service,
+	verificationService verification.Service,
+	lockoutService lockout.Service,
+	mfaService mfa.Service,
+	authCache cache.Service,
+	auditService audit.Service,
+	passwordHasher hasher.Hasher,
+	passwordPolicy hasher.Policy,
 ) Service {
-	privatePem, err := utils.LoadPEMFileInto(env.RSAPrivateKeyPath)
-	if err != nil {
-		panic(err)
-	}
-	rsaPrivateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePem)
-	if err != nil {
-		panic(err)
-	}
-
-	publicPem, err := utils.LoadPEMFileInto(env.RSAPublicKeyPath)
-	if err != nil {
-		panic(err)
-	}
-
-	rsaPublicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPem)
+	dummyPasswordHash, err := passwordHasher.Hash("dummy-password")
 	if err != nil {
 		panic(err)
 	}
 
 	return &service{
-		userService: userService,
-		db:          db,
+		userService:         userService,
+		verificationService: verificationService,
+		lockoutService:      lockoutService,
+		mfaService:          mfaService,
+		authCache:           authCache,
+		auditService:        auditService,
+		hasher:              passwordHasher,
+		passwordPolicy:      passwordPolicy,
+		dummyPasswordHash:   dummyPasswordHash,
+		db:                  db,
 		// token key
-		rsaPrivateKey: rsaPrivateKey,
-		rsaPublicKey:  rsaPublicKey,
+		keyRing: keyRing,
 		// token claim
 		accessTokenValidity:  time.Duration(env.AccessTokenValiditySec),
 		refreshTokenValidity: time.Duration(env.RefreshTokenValiditySec),
 		tokenIssuer:          env.TokenIssuer,
 		tokenAudience:        env.TokenAudience,
+		// impersonation
+		impersonationTokenValidity: time.Duration(env.ImpersonationTokenValiditySec),
 	}
 }
 
-func (s *service) SignUpBasic(signUpDto *dto.SignUpBasic) (*dto.UserAuth, error) {
+func (s *service) SignUpBasic(signUpDto *dto.SignUpBasic, clientInfo *dto.ClientInfo) (*dto.UserAuth, error) {
 	exists := s.IsEmailRegisted(signUpDto.Email)
 	if exists {
 		return nil, network.NewBadRequestError("user already registered", nil)
@@ -102,55 +130,235 @@ func (s *service) SignUpBasic(signUpDto *dto.SignUpBasic) (*dto.UserAuth, error)
 	roles := make([]*userModel.Role, 1)
 	roles[0] = role
 
-	hashed, err := bcrypt.GenerateFromPassword([]byte(signUpDto.Password), 5)
+	err = s.passwordPolicy.Check(signUpDto.Password, signUpDto.Email, signUpDto.Name)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := s.userService.CreateUser(signUpDto.Email, string(hashed), signUpDto.Name, signUpDto.ProfilePicUrl, roles)
+	hashed, err := s.hasher.Hash(signUpDto.Password)
 	if err != nil {
 		return nil, err
 	}
 
-	accessToken, refreshToken, err := s.GenerateToken(user)
+	user, err := s.userService.CreateUser(signUpDto.Email, hashed, signUpDto.Name, signUpDto.ProfilePicUrl, roles)
 	if err != nil {
 		return nil, err
 	}
 
+	// signup should not fail for mail delivery, the user can ask for a resend
+	err = s.verificationService.SendEmailVerification(user)
+	if err != nil {
+		log.Printf("verification email for %s could not be sent: %v", user.Email, err)
+	}
+
+	accessToken, refreshToken, err := s.GenerateToken(user, clientInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(userEvent(model.AuditActionSignUp, model.AuditOutcomeSuccess, user), clientInfo)
+
 	tokens := dto.NewTokens(accessToken, refreshToken)
 	return dto.NewUserAuth(user, tokens), nil
 }
 
-func (s *service) SignInBasic(signInDto *dto.SignInBasic) (*dto.UserAuth, error) {
+// SignInBasic responds the same for an unknown email and a wrong password,
+// failures are counted per email and ip to lock out password guessing
+func (s *service) SignInBasic(signInDto *dto.SignInBasic, clientInfo *dto.ClientInfo) (*dto.UserAuth, *mfaDto.MfaChallenge, error) {
+	var ip string
+	if clientInfo != nil {
+		ip = clientInfo.IP
+	}
+
+	err := s.lockoutService.Check(signInDto.Email, ip)
+	if err != nil {
+		s.audit(emailEvent(model.AuditActionSignIn, signInDto.Email, "locked"), clientInfo)
+		return nil, nil, err
+	}
+
+	passwordHash := s.dummyPasswordHash
 	user, err := s.userService.FetchUserByEmail(signInDto.Email)
+	if err == nil && user.Password != nil {
+		passwordHash = *user.Password
+	}
+
+	ok, rehash, err := s.hasher.Verify(signInDto.Password, passwordHash)
+	if !ok || user == nil || user.Password == nil {
+		lockoutErr := s.lockoutService.RegisterFailure(signInDto.Email, ip)
+		if lockoutErr != nil {
+			log.Printf("failed sign in for %s could not be counted: %v", signInDto.Email, lockoutErr)
+		}
+		s.audit(emailEvent(model.AuditActionSignIn, signInDto.Email, "invalid credentials"), clientInfo)
+		return nil, nil, network.NewUnauthorizedError("invalid credentials", err)
+	}
+
+	err = s.lockoutService.RegisterSuccess(signInDto.Email)
 	if err != nil {
-		return nil, network.NewNotFoundError("user not registerd", err)
+		log.Printf("sign in failures for %s could not be cleared: %v", signInDto.Email, err)
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(signInDto.Password))
+	if rehash {
+		s.rehashPassword(user, signInDto.Password)
+	}
+
+	return s.SignInUser(user, clientInfo)
+}
+
+// rehashPassword replaces a hash of an outdated algorithm or cost while the password is known,
+// the sign in goes on with the old hash when it fails
+func (s *service) rehashPassword(user *userModel.User, password string) {
+	hashed, err := s.hasher.Hash(password)
+	if err == nil {
+		err = s.userService.UpdateUserPassword(user.ID, hashed)
+	}
+	if err != nil {
+		log.Printf("password hash for %s could not be upgraded: %v", user.ID, err)
+	}
+}
+
+// SignInUser completes a sign in of an authenticated user, users with mfa
+// get a challenge to answer on SignInMfa instead of the tokens
+func (s *service) SignInUser(user *userModel.User, clientInfo *dto.ClientInfo) (*dto.UserAuth, *mfaDto.MfaChallenge, error) {
+	if user.MfaEnabled {
+		challenge, err := s.mfaService.CreateChallenge(user)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.audit(userEvent(model.AuditActionSignIn, model.AuditOutcomeChallenged, user), clientInfo)
+		return nil, challenge, nil
+	}
+
+	accessToken, refreshToken, err := s.GenerateToken(user, clientInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.audit(userEvent(model.AuditActionSignIn, model.AuditOutcomeSuccess, user), clientInfo)
+
+	tokens := dto.NewTokens(accessToken, refreshToken)
+	return dto.NewUserAuth(user, tokens), nil, nil
+}
+
+func (s *service) SignInMfa(verifyDto *mfaDto.MfaVerify, clientInfo *dto.ClientInfo) (*dto.UserAuth, error) {
+	userId, err := s.mfaService.VerifyChallenge(verifyDto)
+	if err != nil {
+		s.audit(failureEvent(model.AuditActionSignInMfa, err), clientInfo)
+		return nil, err
+	}
+
+	user, err := s.userService.FetchUserById(userId)
 	if err != nil {
-		return nil, network.NewUnauthorizedError("wrong password", err)
+		return nil, network.NewUnauthorizedError("invalid or expired mfa challenge", err)
 	}
 
-	accessToken, refreshToken, err := s.GenerateToken(user)
+	accessToken, refreshToken, err := s.GenerateToken(user, clientInfo)
 	if err != nil {
 		return nil, err
 	}
 
+	s.audit(userEvent(model.AuditActionSignInMfa, model.AuditOutcomeSuccess, user), clientInfo)
+
 	tokens := dto.NewTokens(accessToken, refreshToken)
 	return dto.NewUserAuth(user, tokens), nil
 }
 
-func (s *service) SignOut(keystore *model.Keystore) error {
+// SignOut removes the whole token family so that older refresh tokens of the session die too
+func (s *service) SignOut(keystore *model.Keystore, clientInfo *dto.ClientInfo) error {
+	err := s.signOutFamily(keystore)
+	if err != nil {
+		return err
+	}
+
+	s.audit(&model.AuditEvent{
+		Action:     model.AuditActionSignOut,
+		Outcome:    model.AuditOutcomeSuccess,
+		ActorID:    &keystore.UserID,
+		TargetType: model.AuditTargetSession,
+		TargetID:   keystore.FamilyID.String(),
+	}, clientInfo)
+	return nil
+}
+
+func (s *service) signOutFamily(keystore *model.Keystore) error {
 	ctx := context.Background()
 
 	query := `
 		DELETE FROM keystore
-		WHERE id = $1
+		WHERE family_id = $1
+		RETURNING p_key
 	`
 
-	_, err := s.db.Pool().Exec(ctx, query, keystore.ID)
-	return err
+	return s.deleteKeystores(ctx, keystore.UserID, query, keystore.FamilyID)
+}
+
+func (s *service) SignOutAll(user *userModel.User) error {
+	ctx := context.Background()
+
+	query := `
+		DELETE FROM keystore
+		WHERE user_id = $1
+		RETURNING p_key
+	`
+
+	return s.deleteKeystores(ctx, user.ID, query, user.ID)
+}
+
+// deleteKeystores runs a delete returning the p_key of the removed keystores
+// and clears them from the auth cache
+func (s *service) deleteKeystores(ctx context.Context, userId uuid.UUID, query string, args ...any) error {
+	rows, err := s.db.Pool().Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	primaryKeys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+
+	s.authCache.ClearKeystores(userId, primaryKeys...)
+	return nil
+}
+
+// audit records the event with the client of the request, the audit never fails the request
+func (s *service) audit(event *model.AuditEvent, clientInfo *dto.ClientInfo) {
+	if clientInfo != nil {
+		event.IP = clientInfo.IP
+		event.UserAgent = clientInfo.UserAgent
+	}
+	s.auditService.Record(event)
+}
+
+func userEvent(action model.AuditAction, outcome model.AuditOutcome, user *userModel.User) *model.AuditEvent {
+	return &model.AuditEvent{
+		Action:     action,
+		Outcome:    outcome,
+		ActorID:    &user.ID,
+		TargetType: model.AuditTargetUser,
+		TargetID:   user.ID.String(),
+	}
+}
+
+// emailEvent is a failed sign in, the actor is not known
+func emailEvent(action model.AuditAction, email string, reason string) *model.AuditEvent {
+	return &model.AuditEvent{
+		Action:     action,
+		Outcome:    model.AuditOutcomeFailure,
+		TargetType: model.AuditTargetEmail,
+		TargetID:   email,
+		Metadata:   map[string]string{"reason": reason},
+	}
+}
+
+func failureEvent(action model.AuditAction, err error) *model.AuditEvent {
+	event := &model.AuditEvent{
+		Action:  action,
+		Outcome: model.AuditOutcomeFailure,
+	}
+	if err != nil {
+		event.Metadata = map[string]string{"reason": err.Error()}
+	}
+	return event
 }
 
 func (s *service) IsEmailRegisted(email string) bool {
@@ -158,73 +366,160 @@ func (s *service) IsEmailRegisted(email string) bool {
 	return exists
 }
 
-func (s *service) RenewToken(tokenRefreshDto *dto.TokenRefresh, accessToken string) (*dto.Tokens, error) {
+func (s *service) RenewToken(tokenRefreshDto *dto.TokenRefresh, accessToken string, clientInfo *dto.ClientInfo) (*dto.Tokens, error) {
+	tokens, user, err := s.renewToken(tokenRefreshDto, accessToken, clientInfo)
+
+	event := failureEvent(model.AuditActionTokenRefresh, err)
+	if err == nil {
+		event.Outcome = model.AuditOutcomeSuccess
+	}
+	if user != nil {
+		event.ActorID = &user.ID
+		event.TargetType = model.AuditTargetUser
+		event.TargetID = user.ID.String()
+	}
+	s.audit(event, clientInfo)
+
+	return tokens, err
+}
+
+// renewToken returns the user once the refresh token owner is known, for the audit of the failures
+func (s *service) renewToken(tokenRefreshDto *dto.TokenRefresh, accessToken string, clientInfo *dto.ClientInfo) (*dto.Tokens, *userModel.User, error) {
 	ctx := context.Background()
 
 	accessClaims, err := s.DecodeToken(accessToken)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	valid := s.ValidateClaims(accessClaims)
 	if !valid {
-		return nil, network.NewUnauthorizedError("permission denied: invalid access claims", nil)
+		return nil, nil, network.NewUnauthorizedError("permission denied: invalid access claims", nil)
+	}
+
+	if accessClaims.IsImpersonation() {
+		return nil, nil, network.NewUnauthorizedError("permission denied: impersonation can not be renewed", nil)
 	}
 
 	refreshClaims, err := s.VerifyToken(tokenRefreshDto.RefreshToken)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	valid = s.ValidateClaims(refreshClaims)
 	if !valid {
-		return nil, network.NewUnauthorizedError("permission denied: invalid refresh claims", nil)
+		return nil, nil, network.NewUnauthorizedError("permission denied: invalid refresh claims", nil)
 	}
 
 	if accessClaims.Subject != refreshClaims.Subject {
-		return nil, network.NewUnauthorizedError("permission denied: access and refresh claims mismatch", nil)
+		return nil, nil, network.NewUnauthorizedError("permission denied: access and refresh claims mismatch", nil)
 	}
 
 	userId, _ := uuid.Parse(refreshClaims.Subject)
 	user, err := s.userService.FetchUserById(userId)
 	if err != nil {
-		return nil, network.NewUnauthorizedError("permission denied: invalid refresh claims subject", nil)
+		return nil, nil, network.NewUnauthorizedError("permission denied: invalid refresh claims subject", nil)
 	}
 
 	keystore, err := s.FindRefreshKeystore(ctx, user, accessClaims.ID, refreshClaims.ID)
 	if err != nil {
-		return nil, network.NewUnauthorizedError("permission denied: claims ids", nil)
+		return nil, user, network.NewUnauthorizedError("permission denied: claims ids", nil)
+	}
+
+	tokens, err := s.rotateRefreshKeystore(ctx, user, keystore, clientInfo)
+	return tokens, user, err
+}
+
+// rotateRefreshKeystore replaces the keystore of a used refresh token with a child in its family.
+// The rotation and the child are committed together so that a failure keeps the session alive,
+// a keystore that is already rotated means its refresh token is replayed and the family is revoked.
+func (s *service) rotateRefreshKeystore(
+	ctx context.Context,
+	user *userModel.User,
+	keystore *model.Keystore,
+	clientInfo *dto.ClientInfo,
+) (*dto.Tokens, error) {
+	// a rotated keystore means this refresh token was already used once
+	if !keystore.Status {
+		s.revokeKeystoreFamily(ctx, keystore)
+		return nil, network.NewUnauthorizedError("permission denied: refresh token reuse detected", nil)
+	}
+
+	primaryKey, secondaryKey, err := newKeystoreKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := s.signTokens(user, primaryKey, secondaryKey)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback(ctx)
 
-	err = s.SignOut(keystore)
+	rotated, err := s.rotateKeystore(ctx, tx, keystore)
 	if err != nil {
-		return nil, nil
+		return nil, err
+	}
+
+	// lost the race against another request presenting the same refresh token
+	if !rotated {
+		tx.Rollback(ctx)
+		s.revokeKeystoreFamily(ctx, keystore)
+		return nil, network.NewUnauthorizedError("permission denied: refresh token reuse detected", nil)
 	}
 
-	accessToken, refreshToken, err := s.GenerateToken(user)
+	_, err = s.createKeystore(ctx, tx, user, primaryKey, secondaryKey, keystore, clientInfo)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	// the cached keystore stays valid until its rotation is committed
+	s.authCache.ClearKeystores(keystore.UserID, keystore.PrimaryKey)
+	s.cleanupRotatedKeystores(ctx, keystore)
+
 	return dto.NewTokens(accessToken, refreshToken), nil
 }
 
-func (s *service) GenerateToken(user *userModel.User) (string, string, error) {
+// GenerateToken starts a new token family
+func (s *service) GenerateToken(user *userModel.User, clientInfo *dto.ClientInfo) (string, string, error) {
 	ctx := context.Background()
-	primaryKey, err := utility.GenerateRandomString(32)
+
+	primaryKey, secondaryKey, err := newKeystoreKeys()
 	if err != nil {
 		return "", "", err
 	}
-	secondaryKey, err := utility.GenerateRandomString(32)
+
+	_, err = s.createKeystore(ctx, s.db.Pool(), user, primaryKey, secondaryKey, nil, clientInfo)
 	if err != nil {
 		return "", "", err
 	}
 
-	_, err = s.CreateKeystore(ctx, user, primaryKey, secondaryKey)
+	return s.signTokens(user, primaryKey, secondaryKey)
+}
+
+func newKeystoreKeys() (string, string, error) {
+	primaryKey, err := utility.GenerateRandomString(32)
 	if err != nil {
 		return "", "", err
 	}
+	secondaryKey, err := utility.GenerateRandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	return primaryKey, secondaryKey, nil
+}
 
+// signTokens signs the access token with the primary key and the refresh token with the secondary key
+func (s *service) signTokens(user *userModel.User, primaryKey string, secondaryKey string) (string, string, error) {
 	now := jwt.NewNumericDate(time.Now())
 
 	accessTokenClaims := jwt.RegisteredClaims{
@@ -260,44 +555,117 @@ func (s *service) GenerateToken(user *userModel.User) (string, string, error) {
 	return accessToken, refreshToken, nil
 }
 
+// GenerateImpersonationToken signs a short lived access token for the user with the actor in the
+// act claim, its id is the primary key of the actor's session so that it ends with that session
+func (s *service) GenerateImpersonationToken(
+	actor *userModel.User,
+	keystore *model.Keystore,
+	user *userModel.User,
+) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.impersonationTokenValidity * time.Second)
+
+	claims := model.TokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.tokenIssuer,
+			Subject:   user.ID.String(),
+			Audience:  []string{s.tokenAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			ID:        keystore.PrimaryKey,
+		},
+		Actor: &model.ActorClaim{Subject: actor.ID.String()},
+	}
+
+	token, err := s.signClaims(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
 func (s *service) GenerateKeystore(
 	client *userModel.User,
 	primaryKey string,
 	secondaryKey string,
 ) (*model.Keystore, error) {
-	return s.CreateKeystore(context.Background(), client, primaryKey, secondaryKey)
+	return s.createKeystore(context.Background(), s.db.Pool(), client, primaryKey, secondaryKey, nil, nil)
 }
 
-func (s *service) CreateKeystore(
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func (s *service) createKeystore(
 	ctx context.Context,
+	q querier,
 	client *userModel.User,
 	primaryKey string,
 	secondaryKey string,
+	parent *model.Keystore,
+	clientInfo *dto.ClientInfo,
 ) (*model.Keystore, error) {
 
 	var ks = model.Keystore{}
 
+	// the first keystore of a family is its root, its id is the family id
+	id := uuid.New()
+	familyID := id
+	var parentID *uuid.UUID
+	if parent != nil {
+		familyID = parent.FamilyID
+		parentID = &parent.ID
+	}
+
+	var userAgent, ip *string
+	if clientInfo != nil {
+		userAgent = &clientInfo.UserAgent
+		ip = &clientInfo.IP
+	}
+
 	query := `
 		INSERT INTO keystore (
+			id,
 			user_id,
 			p_key,
-			s_key
+			s_key,
+			family_id,
+			parent_id,
+			user_agent,
+			ip
 		)
-		VALUES ($1, $2, $3)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING
 			id,
+			family_id,
+			parent_id,
+			user_agent,
+			ip,
+			last_used_at,
 			created_at,
 			updated_at
 	`
 
-	err := s.db.Pool().QueryRow(
+	err := q.QueryRow(
 		ctx,
 		query,
+		id,
 		client.ID,
 		primaryKey,
 		secondaryKey,
+		familyID,
+		parentID,
+		userAgent,
+		ip,
 	).Scan(
 		&ks.ID,
+		&ks.FamilyID,
+		&ks.ParentID,
+		&ks.UserAgent,
+		&ks.IP,
+		&ks.LastUsedAt,
 		&ks.CreatedAt,
 		&ks.UpdatedAt,
 	)
@@ -309,10 +677,15 @@ func (s *service) CreateKeystore(
 	return &ks, nil
 }
 
+// FetchKeystore is read by the authentication on every request, so it goes through the auth cache
 func (s *service) FetchKeystore(
 	client *userModel.User,
 	primaryKey string,
 ) (*model.Keystore, error) {
+	if keystore, ok := s.authCache.GetKeystore(client.ID, primaryKey); ok {
+		return keystore, nil
+	}
+
 	ctx := context.Background()
 	query := `
 		SELECT
@@ -320,6 +693,12 @@ func (s *service) FetchKeystore(
 			user_id,
 			p_key,
 			s_key,
+			family_id,
+			parent_id,
+			rotated_at,
+			user_agent,
+			ip,
+			last_used_at,
 			status,
 			created_at,
 			updated_at
@@ -337,6 +716,12 @@ func (s *service) FetchKeystore(
 			&ks.UserID,
 			&ks.PrimaryKey,
 			&ks.SecondaryKey,
+			&ks.FamilyID,
+			&ks.ParentID,
+			&ks.RotatedAt,
+			&ks.UserAgent,
+			&ks.IP,
+			&ks.LastUsedAt,
 			&ks.Status,
 			&ks.CreatedAt,
 			&ks.UpdatedAt,
@@ -346,9 +731,36 @@ func (s *service) FetchKeystore(
 		return nil, err
 	}
 
+	s.authCache.SetKeystore(&ks)
 	return &ks, nil
 }
 
+// TouchKeystore records the session activity, writes are skipped
+// when the keystore was used within the last minute
+func (s *service) TouchKeystore(keystore *model.Keystore) error {
+	if time.Since(keystore.LastUsedAt) < time.Minute {
+		return nil
+	}
+
+	ctx := context.Background()
+
+	query := `
+		UPDATE keystore
+		SET last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	_, err := s.db.Pool().Exec(ctx, query, keystore.ID)
+	if err != nil {
+		return err
+	}
+
+	// the cached copy carries the new last use so that the next requests skip the write again
+	keystore.LastUsedAt = time.Now()
+	s.authCache.SetKeystore(keystore)
+	return nil
+}
+
 func (s *service) FindRefreshKeystore(
 	ctx context.Context,
 	client *userModel.User,
@@ -362,6 +774,12 @@ func (s *service) FindRefreshKeystore(
 			user_id,
 			p_key,
 			s_key,
+			family_id,
+			parent_id,
+			rotated_at,
+			user_agent,
+			ip,
+			last_used_at,
 			status,
 			created_at,
 			updated_at
@@ -369,7 +787,6 @@ func (s *service) FindRefreshKeystore(
 		WHERE user_id = $1
 		  AND p_key = $2
 		  AND s_key = $3
-		  AND status = TRUE
 	`
 
 	var ks model.Keystore
@@ -385,6 +802,12 @@ func (s *service) FindRefreshKeystore(
 		&ks.UserID,
 		&ks.PrimaryKey,
 		&ks.SecondaryKey,
+		&ks.FamilyID,
+		&ks.ParentID,
+		&ks.RotatedAt,
+		&ks.UserAgent,
+		&ks.IP,
+		&ks.LastUsedAt,
 		&ks.Status,
 		&ks.CreatedAt,
 		&ks.UpdatedAt,
@@ -397,25 +820,95 @@ func (s *service) FindRefreshKeystore(
 	return &ks, nil
 }
 
+// rotateKeystore retires the keystore but keeps it in the family to detect a reuse later,
+// it returns false if the keystore was already rotated by a concurrent request
+func (s *service) rotateKeystore(
+	ctx context.Context,
+	tx pgx.Tx,
+	keystore *model.Keystore,
+) (bool, error) {
+	query := `
+		UPDATE keystore
+		SET
+			status = FALSE,
+			rotated_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		  AND status = TRUE
+	`
+
+	tag, err := tx.Exec(ctx, query, keystore.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// cleanupRotatedKeystores removes the rotated keystores once their refresh tokens expired,
+// the family root is kept since it records when the session started
+func (s *service) cleanupRotatedKeystores(ctx context.Context, keystore *model.Keystore) {
+	query := `
+		DELETE FROM keystore
+		WHERE family_id = $1
+		  AND id <> family_id
+		  AND status = FALSE
+		  AND rotated_at < $2
+	`
+
+	_, err := s.db.Pool().Exec(ctx, query, keystore.FamilyID, time.Now().Add(-s.refreshTokenValidity*time.Second))
+	if err != nil {
+		log.Printf("rotated keystores cleanup failed for family %s: %v", keystore.FamilyID, err)
+	}
+}
+
+// revokeKeystoreFamily treats the replay as token theft, every token of the family is revoked
+func (s *service) revokeKeystoreFamily(ctx context.Context, keystore *model.Keystore) {
+	log.Printf("refresh token reuse detected for user %s in family %s", keystore.UserID, keystore.FamilyID)
+
+	eventQuery := `
+		INSERT INTO token_reuse_events (
+			user_id,
+			family_id,
+			keystore_id
+		)
+		VALUES ($1, $2, $3)
+	`
+
+	_, err := s.db.Pool().Exec(ctx, eventQuery, keystore.UserID, keystore.FamilyID, keystore.ID)
+	if err != nil {
+		log.Printf("token reuse event could not be recorded for family %s: %v", keystore.FamilyID, err)
+	}
+
+	err = s.signOutFamily(keystore)
+	if err != nil {
+		log.Printf("token family %s could not be revoked: %v", keystore.FamilyID, err)
+	}
+}
+
 func (s *service) SignToken(claims jwt.RegisteredClaims) (string, error) {
+	return s.signClaims(claims)
+}
+
+func (s *service) signClaims(claims jwt.Claims) (string, error) {
+	kid, signingKey := s.keyRing.SigningKey()
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	signed, err := token.SignedString(s.rsaPrivateKey)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(signingKey)
 	if err != nil {
 		return "", err
 	}
 	return signed, nil
 }
 
-func (s *service) VerifyToken(tokenStr string) (*jwt.RegisteredClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(tkn *jwt.Token) (any, error) {
-		return s.rsaPublicKey, nil
-	})
+func (s *service) VerifyToken(tokenStr string) (*model.TokenClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &model.TokenClaims{}, s.verificationKey)
 	if err != nil {
 		return nil, err
 	}
 
 	if token.Valid {
-		if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok {
+		if claims, ok := token.Claims.(*model.TokenClaims); ok {
 			return claims, nil
 		}
 	}
@@ -423,22 +916,40 @@ func (s *service) VerifyToken(tokenStr string) (*jwt.RegisteredClaims, error) {
 	return nil, jwt.ErrTokenMalformed
 }
 
-func (s *service) DecodeToken(tokenStr string) (*jwt.RegisteredClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(tkn *jwt.Token) (any, error) {
-		return s.rsaPublicKey, nil
-	})
+func (s *service) DecodeToken(tokenStr string) (*model.TokenClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &model.TokenClaims{}, s.verificationKey)
 	if token == nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok {
+	if claims, ok := token.Claims.(*model.TokenClaims); ok {
 		return claims, nil
 	}
 
 	return nil, jwt.ErrTokenMalformed
 }
 
-func (s *service) ValidateClaims(claims *jwt.RegisteredClaims) bool {
+// verificationKey selects the public key by the kid header, tokens signed
+// before kids were introduced are checked against every known key
+func (s *service) verificationKey(tkn *jwt.Token) (any, error) {
+	kid, ok := tkn.Header["kid"].(string)
+	if !ok || kid == "" {
+		keySet := jwt.VerificationKeySet{}
+		for _, key := range s.keyRing.PublicKeys() {
+			keySet.Keys = append(keySet.Keys, key.PublicKey)
+		}
+		return keySet, nil
+	}
+
+	publicKey, found := s.keyRing.PublicKey(kid)
+	if !found {
+		return nil, jwt.ErrTokenUnverifiable
+	}
+	return publicKey, nil
+}
+
+// ValidateClaims also checks the actor of an impersonation token, a user can not act on behalf of itself
+func (s *service) ValidateClaims(claims *model.TokenClaims) bool {
 	invalid := claims.Issuer != s.tokenIssuer ||
 		claims.Subject == "" ||
 		len(claims.Audience) == 0 ||
@@ -451,38 +962,62 @@ func (s *service) ValidateClaims(claims *jwt.RegisteredClaims) bool {
 		return false
 	}
 
-	err := uuid.Validate(claims.Subject)
-	return err == nil
+	if err := uuid.Validate(claims.Subject); err != nil {
+		return false
+	}
+
+	if claims.IsImpersonation() {
+		if claims.Actor.Subject == claims.Subject {
+			return false
+		}
+		if err := uuid.Validate(claims.Actor.Subject); err != nil {
+			return false
+		}
+	}
+
+	return true
 }
 
+// FetchApiKey is read by the key protection on every request, so it goes through the auth cache
 func (s *service) FetchApiKey(
 	key string,
 ) (*model.ApiKey, error) {
+	keyHash := utils.HashToken(key)
+	if apiKey, ok := s.authCache.GetApiKey(keyHash); ok {
+		return apiKey, nil
+	}
+
 	ctx := context.Background()
 	query := `
 		SELECT
 			id,
-			key,
+			key_hash,
+			key_prefix,
 			permissions,
 			comments,
 			version,
+			expires_at,
+			allowed_origins,
 			status,
 			created_at,
 			updated_at
 		FROM api_keys
-		WHERE key = $1
+		WHERE key_hash = $1
 		  AND status = TRUE
 	`
 
 	var apiKey model.ApiKey
 
-	err := s.db.Pool().QueryRow(ctx, query, key).
+	err := s.db.Pool().QueryRow(ctx, query, keyHash).
 		Scan(
 			&apiKey.ID,
-			&apiKey.Key,
+			&apiKey.KeyHash,
+			&apiKey.KeyPrefix,
 			&apiKey.Permissions,
 			&apiKey.Comments,
 			&apiKey.Version,
+			&apiKey.ExpiresAt,
+			&apiKey.AllowedOrigins,
 			&apiKey.Status,
 			&apiKey.CreatedAt,
 			&apiKey.UpdatedAt,
@@ -492,9 +1027,85 @@ func (s *service) FetchApiKey(
 		return nil, err
 	}
 
+	s.authCache.SetApiKey(&apiKey)
 	return &apiKey, nil
 }
 
+// FetchAccessToken finds a personal access token for the authentication, it goes through the auth cache
+// like the api keys and the caller checks the expiry
+func (s *service) FetchAccessToken(token string) (*model.AccessToken, error) {
+	tokenHash := utils.HashToken(token)
+	if accessToken, ok := s.authCache.GetAccessToken(tokenHash); ok {
+		return accessToken, nil
+	}
+
+	ctx := context.Background()
+	query := `
+		SELECT
+			id,
+			user_id,
+			name,
+			token_hash,
+			token_prefix,
+			scopes,
+			expires_at,
+			last_used_at,
+			created_at,
+			updated_at
+		FROM access_tokens
+		WHERE token_hash = $1
+	`
+
+	var accessToken model.AccessToken
+
+	err := s.db.Pool().QueryRow(ctx, query, tokenHash).
+		Scan(
+			&accessToken.ID,
+			&accessToken.UserID,
+			&accessToken.Name,
+			&accessToken.TokenHash,
+			&accessToken.TokenPrefix,
+			&accessToken.Scopes,
+			&accessToken.ExpiresAt,
+			&accessToken.LastUsedAt,
+			&accessToken.CreatedAt,
+			&accessToken.UpdatedAt,
+		)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.authCache.SetAccessToken(&accessToken)
+	return &accessToken, nil
+}
+
+// TouchAccessToken records the token use, writes are skipped
+// when the token was used within the last minute
+func (s *service) TouchAccessToken(accessToken *model.AccessToken) error {
+	if accessToken.LastUsedAt != nil && time.Since(*accessToken.LastUsedAt) < time.Minute {
+		return nil
+	}
+
+	ctx := context.Background()
+
+	query := `
+		UPDATE access_tokens
+		SET last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	_, err := s.db.Pool().Exec(ctx, query, accessToken.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	accessToken.LastUsedAt = &now
+	s.authCache.SetAccessToken(accessToken)
+	return nil
+}
+
 func (s *service) CreateApiKey(
 	key string,
 	version int,
@@ -506,18 +1117,22 @@ func (s *service) CreateApiKey(
 
 	query := `
 		INSERT INTO api_keys (
-			key,
+			key_hash,
+			key_prefix,
 			permissions,
 			comments,
 			version
 		)
-		VALUES ($1, $2, $3, $4)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING
 			id,
-			key,
+			key_hash,
+			key_prefix,
 			permissions,
 			comments,
 			version,
+			expires_at,
+			allowed_origins,
 			status,
 			created_at,
 			updated_at
@@ -526,16 +1141,20 @@ func (s *service) CreateApiKey(
 	err := s.db.Pool().QueryRow(
 		ctx,
 		query,
-		key,
+		utils.HashToken(key),
+		model.ApiKeyPrefix(key),
 		permissions,
 		comments,
 		version,
 	).Scan(
 		&apiKey.ID,
-		&apiKey.Key,
+		&apiKey.KeyHash,
+		&apiKey.KeyPrefix,
 		&apiKey.Permissions,
 		&apiKey.Comments,
 		&apiKey.Version,
+		&apiKey.ExpiresAt,
+		&apiKey.AllowedOrigins,
 		&apiKey.Status,
 		&apiKey.CreatedAt,
 		&apiKey.UpdatedAt,
@@ -545,6 +1164,7 @@ func (s *service) CreateApiKey(
 		return nil, err
 	}
 
+	apiKey.Key = key
 	return &apiKey, nil
 }
 
@@ -562,5 +1182,6 @@ func (s *service) DeleteApiKey(
 		return false, err
 	}
 
+	s.authCache.ClearApiKeys(apiKey.KeyHash)
 	return tag.RowsAffected() > 0, nil
 }
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/hasher"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/jwks"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTokenService(keyRing jwks.KeyRing) Service {
+	env := &config.Env{TokenIssuer: "issuer", TokenAudience: "audience"}
+	return NewService(nil, env, keyRing, nil, nil, nil, nil, nil, nil, hasher.NewHasher(env), hasher.NewPolicy(env))
+}
+
+func testClaims() jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		Issuer:    "issuer",
+		Subject:   "subject",
+		Audience:  []string{"audience"},
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		ID:        "id",
+	}
+}
+
+func TestAuthService_VerifyTokenAfterKeyRotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	oldService := newTokenService(jwks.NewKeyRingFromKeys(oldKey))
+	token, err := oldService.SignToken(testClaims())
+	assert.NoError(t, err)
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, jwks.Thumbprint(&oldKey.PublicKey), parsed.Header["kid"])
+
+	rotated := newTokenService(jwks.NewKeyRingFromKeys(newKey, &oldKey.PublicKey))
+	claims, err := rotated.VerifyToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "id", claims.ID)
+
+	// once the old key is retired from the ring its tokens are rejected
+	dropped := newTokenService(jwks.NewKeyRingFromKeys(newKey))
+	_, err = dropped.VerifyToken(token)
+	assert.Error(t, err)
+}
+
+func TestAuthService_VerifyTokenWithoutKid(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	// tokens issued before kid headers existed
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, testClaims()).SignedString(oldKey)
+	assert.NoError(t, err)
+
+	rotated := newTokenService(jwks.NewKeyRingFromKeys(newKey, &oldKey.PublicKey))
+	claims, err := rotated.VerifyToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "subject", claims.Subject)
+}
+
+func TestAuthService_ImpersonationTokenCarriesActor(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	env := &config.Env{TokenIssuer: "issuer", TokenAudience: "audience", ImpersonationTokenValiditySec: 900}
+	s := NewService(nil, env, jwks.NewKeyRingFromKeys(key), nil, nil, nil, nil, nil, nil, hasher.NewHasher(env), hasher.NewPolicy(env))
+
+	actor := &userModel.User{ID: uuid.New()}
+	user := &userModel.User{ID: uuid.New()}
+	keystore := &model.Keystore{PrimaryKey: "actor-session"}
+
+	token, expiresAt, err := s.GenerateImpersonationToken(actor, keystore, user)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(900*time.Second), expiresAt, time.Minute)
+
+	claims, err := s.VerifyToken(token)
+	assert.NoError(t, err)
+	assert.True(t, claims.IsImpersonation())
+	assert.Equal(t, actor.ID.String(), claims.Actor.Subject)
+	assert.Equal(t, user.ID.String(), claims.Subject)
+	assert.Equal(t, "actor-session", claims.ID)
+	assert.True(t, s.ValidateClaims(claims))
+
+	// a user acting on behalf of itself is not a valid impersonation
+	claims.Actor.Subject = claims.Subject
+	assert.False(t, s.ValidateClaims(claims))
+}
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	coredto "github.com/afteracademy/goserve/v2/dto"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/auth/sessions", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(common.KeyPermission(model.GeneralPermission), c.Authentication(), common.RejectImpersonation())
+	group.GET("", c.getSessionsHandler)
+	group.GET("/:id", c.getSessionHandler)
+	group.DELETE("/:id", c.revokeSessionHandler)
+	group.DELETE("", c.revokeOtherSessionsHandler)
+}
+
+func (c *controller) getSessionsHandler(ctx *gin.Context) {
+	user := c.MustGetUser(ctx)
+	keystore := c.MustGetKeystore(ctx)
+
+	sessions, err := c.service.GetSessions(user, keystore)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", &sessions)
+}
+
+func (c *controller) getSessionHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+	keystore := c.MustGetKeystore(ctx)
+
+	session, err := c.service.GetSession(uuidParam.ID, user, keystore)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", session)
+}
+
+func (c *controller) revokeSessionHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	err = c.service.RevokeSession(uuidParam.ID, user)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "session revoked successfully")
+}
+
+func (c *controller) revokeOtherSessionsHandler(ctx *gin.Context) {
+	user := c.MustGetUser(ctx)
+	keystore := c.MustGetKeystore(ctx)
+
+	err := c.service.RevokeOtherSessions(user, keystore)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "other sessions revoked successfully")
+}
@@ -0,0 +1,87 @@
+package session
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/session/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockAuthentication(user *userModel.User, keystore *model.Keystore) *network.MockAuthenticationProvider {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		payload := common.NewContextPayload()
+		payload.SetUser(ctx, user)
+		payload.SetKeystore(ctx, keystore)
+		ctx.Next()
+	}))
+	return mockAuthProvider
+}
+
+func TestSessionController_GetSessionsSuccess(t *testing.T) {
+	user := &userModel.User{ID: uuid.New()}
+	keystore := &model.Keystore{ID: uuid.New(), FamilyID: uuid.New()}
+
+	sessions := []*dto.SessionInfo{{ID: keystore.FamilyID, Current: true}}
+
+	service := new(MockService)
+	service.On("GetSessions", user, keystore).Return(sessions, nil)
+
+	c := NewController(mockAuthentication(user, keystore), new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "GET", "/auth/sessions", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"id":"`+keystore.FamilyID.String()+`"`)
+	assert.Contains(t, rr.Body.String(), `"current":true`)
+	service.AssertExpectations(t)
+}
+
+func TestSessionController_RevokeSessionBadRequest(t *testing.T) {
+	user := &userModel.User{ID: uuid.New()}
+	keystore := &model.Keystore{ID: uuid.New(), FamilyID: uuid.New()}
+
+	service := new(MockService)
+
+	c := NewController(mockAuthentication(user, keystore), new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "DELETE", "/auth/sessions/abc", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "RevokeSession")
+}
+
+func TestSessionController_RevokeSessionNotFound(t *testing.T) {
+	user := &userModel.User{ID: uuid.New()}
+	keystore := &model.Keystore{ID: uuid.New(), FamilyID: uuid.New()}
+	id := uuid.New()
+
+	service := new(MockService)
+	service.On("RevokeSession", id, user).Return(network.NewNotFoundError("session not found", nil))
+
+	c := NewController(mockAuthentication(user, keystore), new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "DELETE", "/auth/sessions/"+id.String(), "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"session not found"`)
+}
+
+func TestSessionController_RevokeOtherSessionsSuccess(t *testing.T) {
+	user := &userModel.User{ID: uuid.New()}
+	keystore := &model.Keystore{ID: uuid.New(), FamilyID: uuid.New()}
+
+	service := new(MockService)
+	service.On("RevokeOtherSessions", user, keystore).Return(nil)
+
+	c := NewController(mockAuthentication(user, keystore), new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "DELETE", "/auth/sessions", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"other sessions revoked successfully"`)
+	service.AssertExpectations(t)
+}
@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionInfo is a signed in device, its id is the keystore family id
+// which stays the same across token refreshes
+type SessionInfo struct {
+	ID         uuid.UUID `json:"id" validate:"required"`
+	UserAgent  *string   `json:"userAgent,omitempty"`
+	IP         *string   `json:"ip,omitempty"`
+	CreatedAt  time.Time `json:"createdAt" validate:"required"`
+	LastUsedAt time.Time `json:"lastUsedAt" validate:"required"`
+	Current    bool      `json:"current"`
+}
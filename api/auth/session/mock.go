@@ -0,0 +1,39 @@
+package session
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/session/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) GetSessions(user *userModel.User, current *model.Keystore) ([]*dto.SessionInfo, error) {
+	args := m.Called(user, current)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.SessionInfo), args.Error(1)
+}
+
+func (m *MockService) GetSession(id uuid.UUID, user *userModel.User, current *model.Keystore) (*dto.SessionInfo, error) {
+	args := m.Called(id, user, current)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.SessionInfo), args.Error(1)
+}
+
+func (m *MockService) RevokeSession(id uuid.UUID, user *userModel.User) error {
+	args := m.Called(id, user)
+	return args.Error(0)
+}
+
+func (m *MockService) RevokeOtherSessions(user *userModel.User, current *model.Keystore) error {
+	args := m.Called(user, current)
+	return args.Error(0)
+}
@@ -0,0 +1,170 @@
+package session
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/cache"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/session/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Service interface {
+	GetSessions(user *userModel.User, current *model.Keystore) ([]*dto.SessionInfo, error)
+	GetSession(id uuid.UUID, user *userModel.User, current *model.Keystore) (*dto.SessionInfo, error)
+	RevokeSession(id uuid.UUID, user *userModel.User) error
+	RevokeOtherSessions(user *userModel.User, current *model.Keystore) error
+}
+
+type service struct {
+	db        postgres.Database
+	authCache cache.Service
+}
+
+func NewService(db postgres.Database, authCache cache.Service) Service {
+	return &service{
+		db:        db,
+		authCache: authCache,
+	}
+}
+
+// the live keystore of a family carries the device data,
+// the family root tells when the session was started, without the root
+// the live keystore is the oldest one known of the session
+const sessionSelect = `
+	SELECT
+		k.family_id,
+		k.user_agent,
+		k.ip,
+		COALESCE(r.created_at, k.created_at),
+		k.last_used_at
+	FROM keystore k
+	LEFT JOIN keystore r ON r.id = k.family_id
+`
+
+func (s *service) GetSessions(user *userModel.User, current *model.Keystore) ([]*dto.SessionInfo, error) {
+	ctx := context.Background()
+
+	query := sessionSelect + `
+		WHERE k.user_id = $1
+		  AND k.status = TRUE
+		ORDER BY k.last_used_at DESC
+	`
+
+	rows, err := s.db.Pool().Query(ctx, query, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]*dto.SessionInfo, 0)
+
+	for rows.Next() {
+		var session dto.SessionInfo
+		err := rows.Scan(
+			&session.ID,
+			&session.UserAgent,
+			&session.IP,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		session.Current = session.ID == current.FamilyID
+		sessions = append(sessions, &session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (s *service) GetSession(id uuid.UUID, user *userModel.User, current *model.Keystore) (*dto.SessionInfo, error) {
+	ctx := context.Background()
+
+	query := sessionSelect + `
+		WHERE k.family_id = $1
+		  AND k.user_id = $2
+		  AND k.status = TRUE
+	`
+
+	var session dto.SessionInfo
+
+	err := s.db.Pool().QueryRow(ctx, query, id, user.ID).Scan(
+		&session.ID,
+		&session.UserAgent,
+		&session.IP,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, network.NewNotFoundError("session not found", err)
+		}
+		return nil, err
+	}
+
+	session.Current = session.ID == current.FamilyID
+	return &session, nil
+}
+
+func (s *service) RevokeSession(id uuid.UUID, user *userModel.User) error {
+	ctx := context.Background()
+
+	query := `
+		DELETE FROM keystore
+		WHERE family_id = $1
+		  AND user_id = $2
+		RETURNING p_key
+	`
+
+	deleted, err := s.deleteKeystores(ctx, user, query, id, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return network.NewNotFoundError("session not found", nil)
+	}
+
+	return nil
+}
+
+func (s *service) RevokeOtherSessions(user *userModel.User, current *model.Keystore) error {
+	ctx := context.Background()
+
+	query := `
+		DELETE FROM keystore
+		WHERE user_id = $1
+		  AND family_id <> $2
+		RETURNING p_key
+	`
+
+	_, err := s.deleteKeystores(ctx, user, query, user.ID, current.FamilyID)
+	return err
+}
+
+// deleteKeystores runs a delete returning the p_key of the removed keystores,
+// clears them from the auth cache and returns their count
+func (s *service) deleteKeystores(ctx context.Context, user *userModel.User, query string, args ...any) (int, error) {
+	rows, err := s.db.Pool().Query(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	primaryKeys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return 0, err
+	}
+
+	s.authCache.ClearKeystores(user.ID, primaryKeys...)
+	return len(primaryKeys), nil
+}
@@ -0,0 +1,61 @@
+package verification
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/verification/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/auth/verify", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(common.KeyPermission(model.GeneralPermission))
+	group.POST("/email", c.verifyEmailHandler)
+	group.POST("/email/resend", c.Authentication(), c.resendEmailHandler)
+}
+
+func (c *controller) verifyEmailHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.EmailVerify](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	err = c.service.VerifyEmail(body)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "email verified successfully")
+}
+
+func (c *controller) resendEmailHandler(ctx *gin.Context) {
+	user := c.MustGetUser(ctx)
+
+	err := c.service.ResendEmailVerification(user)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "verification email sent")
+}
@@ -0,0 +1,61 @@
+package verification
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/verification/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerificationController_VerifyEmailBadRequest(t *testing.T) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	service := new(MockService)
+
+	c := NewController(mockAuthProvider, new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "POST", "/auth/verify/email", "{}", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"token is required"`)
+}
+
+func TestVerificationController_VerifyEmailInvalidToken(t *testing.T) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	service := new(MockService)
+	service.On("VerifyEmail", &dto.EmailVerify{Token: "token"}).
+		Return(network.NewBadRequestError("invalid or expired token", nil))
+
+	c := NewController(mockAuthProvider, new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "POST", "/auth/verify/email", `{"token":"token"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"invalid or expired token"`)
+}
+
+func TestVerificationController_VerifyEmailSuccess(t *testing.T) {
+	mockAuthProvider := new(network.MockAuthenticationProvider)
+	mockAuthProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	service := new(MockService)
+	service.On("VerifyEmail", &dto.EmailVerify{Token: "token"}).Return(nil)
+
+	c := NewController(mockAuthProvider, new(network.MockAuthorizationProvider), service)
+
+	rr := network.MockTestController(t, "POST", "/auth/verify/email", `{"token":"token"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"email verified successfully"`)
+	service.AssertExpectations(t)
+}
@@ -0,0 +1,5 @@
+package dto
+
+type EmailVerify struct {
+	Token string `json:"token" binding:"required" validate:"required"`
+}
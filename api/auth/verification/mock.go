@@ -0,0 +1,50 @@
+package verification
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/verification/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) IssueToken(user *userModel.User, purpose model.TokenPurpose, validity time.Duration) (string, error) {
+	args := m.Called(user, purpose, validity)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockService) ConsumeToken(token string, purpose model.TokenPurpose) (*model.UserToken, error) {
+	args := m.Called(token, purpose)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserToken), args.Error(1)
+}
+
+func (m *MockService) LastTokenIssuedAt(user *userModel.User, purpose model.TokenPurpose) (*time.Time, error) {
+	args := m.Called(user, purpose)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockService) SendEmailVerification(user *userModel.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockService) ResendEmailVerification(user *userModel.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockService) VerifyEmail(verifyDto *dto.EmailVerify) error {
+	args := m.Called(verifyDto)
+	return args.Error(0)
+}
@@ -0,0 +1,242 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/verification/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve-example-api-server-postgres/mailer"
+	"github.com/afteracademy/goserve-example-api-server-postgres/utils"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/afteracademy/goserve/v2/utility"
+	"github.com/jackc/pgx/v5"
+)
+
+type Service interface {
+	IssueToken(user *userModel.User, purpose model.TokenPurpose, validity time.Duration) (string, error)
+	ConsumeToken(token string, purpose model.TokenPurpose) (*model.UserToken, error)
+	LastTokenIssuedAt(user *userModel.User, purpose model.TokenPurpose) (*time.Time, error)
+	SendEmailVerification(user *userModel.User) error
+	ResendEmailVerification(user *userModel.User) error
+	VerifyEmail(verifyDto *dto.EmailVerify) error
+}
+
+type service struct {
+	db          postgres.Database
+	userService user.Service
+	mailer      mailer.Sender
+	// verification
+	verificationUrl      string
+	verificationValidity time.Duration
+	resendCooldown       time.Duration
+}
+
+func NewService(
+	db postgres.Database,
+	env *config.Env,
+	userService user.Service,
+	mailer mailer.Sender,
+) Service {
+	return &service{
+		db:                   db,
+		userService:          userService,
+		mailer:               mailer,
+		verificationUrl:      env.EmailVerificationUrl,
+		verificationValidity: time.Duration(env.EmailVerificationValiditySec) * time.Second,
+		resendCooldown:       time.Duration(env.EmailVerificationResendSec) * time.Second,
+	}
+}
+
+// IssueToken replaces any unused token of the same purpose and returns the raw token,
+// only its hash is stored
+func (s *service) IssueToken(
+	user *userModel.User,
+	purpose model.TokenPurpose,
+	validity time.Duration,
+) (string, error) {
+	ctx := context.Background()
+
+	token, err := utility.GenerateRandomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	deleteQuery := `
+		DELETE FROM user_tokens
+		WHERE user_id = $1
+		  AND purpose = $2
+		  AND used_at IS NULL
+	`
+
+	_, err = tx.Exec(ctx, deleteQuery, user.ID, purpose)
+	if err != nil {
+		return "", err
+	}
+
+	insertQuery := `
+		INSERT INTO user_tokens (
+			user_id,
+			purpose,
+			token_hash,
+			expires_at
+		)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err = tx.Exec(
+		ctx,
+		insertQuery,
+		user.ID,
+		purpose,
+		utils.HashToken(token),
+		time.Now().Add(validity),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ConsumeToken marks the token used in the same statement that checks it,
+// so a token can never be redeemed twice
+func (s *service) ConsumeToken(
+	token string,
+	purpose model.TokenPurpose,
+) (*model.UserToken, error) {
+	ctx := context.Background()
+
+	query := `
+		UPDATE user_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1
+		  AND purpose = $2
+		  AND used_at IS NULL
+		  AND expires_at > CURRENT_TIMESTAMP
+		RETURNING
+			id,
+			user_id,
+			purpose,
+			token_hash,
+			expires_at,
+			used_at,
+			created_at
+	`
+
+	var t model.UserToken
+
+	err := s.db.Pool().QueryRow(ctx, query, utils.HashToken(token), purpose).
+		Scan(
+			&t.ID,
+			&t.UserID,
+			&t.Purpose,
+			&t.TokenHash,
+			&t.ExpiresAt,
+			&t.UsedAt,
+			&t.CreatedAt,
+		)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, network.NewBadRequestError("invalid or expired token", nil)
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (s *service) SendEmailVerification(user *userModel.User) error {
+	token, err := s.IssueToken(user, model.TokenPurposeEmailVerification, s.verificationValidity)
+	if err != nil {
+		return err
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: "Hi " + user.Name + ",\n\n" +
+			"Please verify your email by opening the link below:\n" +
+			s.verificationUrl + "?token=" + token + "\n\n" +
+			"The link expires in " + s.verificationValidity.String() + ".",
+	}
+
+	return s.mailer.Send(msg)
+}
+
+func (s *service) ResendEmailVerification(user *userModel.User) error {
+	if user.Verified {
+		return network.NewBadRequestError("email already verified", nil)
+	}
+
+	lastSentAt, err := s.LastTokenIssuedAt(user, model.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	if lastSentAt != nil && time.Since(*lastSentAt) < s.resendCooldown {
+		return network.NewBadRequestError("verification email recently sent, please try again later", nil)
+	}
+
+	return s.SendEmailVerification(user)
+}
+
+func (s *service) VerifyEmail(verifyDto *dto.EmailVerify) error {
+	token, err := s.ConsumeToken(verifyDto.Token, model.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	err = s.userService.MarkUserVerified(token.UserID)
+	if err != nil {
+		log.Printf("email verification failed for user %s: %v", token.UserID, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) LastTokenIssuedAt(
+	user *userModel.User,
+	purpose model.TokenPurpose,
+) (*time.Time, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT created_at
+		FROM user_tokens
+		WHERE user_id = $1
+		  AND purpose = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var createdAt time.Time
+
+	err := s.db.Pool().QueryRow(ctx, query, user.ID, purpose).Scan(&createdAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &createdAt, nil
+}
@@ -1,6 +1,7 @@
 package author
 
 import (
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/dto"
 	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
 	"github.com/afteracademy/goserve-example-api-server-postgres/common"
@@ -28,16 +29,26 @@ func NewController(
 }
 
 func (c *controller) MountRoutes(group *gin.RouterGroup) {
-	group.Use(c.Authentication(), c.Authorization(string(userModel.RoleCodeAuthor)))
-	group.POST("/", c.postBlogHandler)
-	group.PUT("/", c.updateBlogHandler)
-	group.GET("/id/:id", c.getBlogHandler)
-	group.DELETE("/id/:id", c.deleteBlogHandler)
-	group.PUT("/submit/id/:id", c.submitBlogHandler)
-	group.PUT("/withdraw/id/:id", c.withdrawBlogHandler)
-	group.GET("/drafts", c.getDraftsBlogsHandler)
-	group.GET("/submitted", c.getSubmittedBlogsHandler)
-	group.GET("/published", c.getPublishedBlogsHandler)
+	group.Use(common.KeyPermission(authModel.AuthorPermission))
+	read := group.Group("",
+		common.TokenScope(authModel.ScopeBlogRead),
+		c.Authentication(),
+		c.Authorization(string(userModel.PermissionBlogWrite)),
+	)
+	write := group.Group("",
+		common.TokenScope(authModel.ScopeBlogWrite),
+		c.Authentication(),
+		c.Authorization(string(userModel.PermissionBlogWrite)),
+	)
+	write.POST("/", c.postBlogHandler)
+	write.PUT("/", c.updateBlogHandler)
+	read.GET("/id/:id", c.getBlogHandler)
+	write.DELETE("/id/:id", c.deleteBlogHandler)
+	write.PUT("/submit/id/:id", c.submitBlogHandler)
+	write.PUT("/withdraw/id/:id", c.withdrawBlogHandler)
+	read.GET("/drafts", c.getDraftsBlogsHandler)
+	read.GET("/submitted", c.getSubmittedBlogsHandler)
+	read.GET("/published", c.getPublishedBlogsHandler)
 }
 
 func (c *controller) postBlogHandler(ctx *gin.Context) {
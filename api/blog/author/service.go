@@ -8,6 +8,7 @@ import (
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/dto"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/revision"
 	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
 	"github.com/afteracademy/goserve-example-api-server-postgres/utils"
 	coredto "github.com/afteracademy/goserve/v2/dto"
@@ -44,6 +45,10 @@ func (s *service) CreateBlog(
 	author *userModel.User,
 ) (*dto.BlogPrivate, error) {
 
+	if !author.Verified {
+		return nil, network.NewForbiddenError("permission denied: verify your email to create blogs", nil)
+	}
+
 	slug := utils.FormatEndpoint(d.Slug)
 	exists := s.blogService.BlogSlugExists(slug)
 	if exists {
@@ -56,6 +61,12 @@ func (s *service) CreateBlog(
 	ctx := context.Background()
 	var blog model.Blog
 
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 		INSERT INTO blogs (
 			title,
@@ -85,7 +96,7 @@ func (s *service) CreateBlog(
 			status
 	`
 
-	err := s.db.Pool().QueryRow(
+	err = tx.QueryRow(
 		ctx,
 		query,
 		d.Title,
@@ -115,6 +126,15 @@ func (s *service) CreateBlog(
 		return nil, err
 	}
 
+	_, err = revision.Capture(ctx, tx, blog.ID, model.RevisionDraft, author.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
 	return dto.NewBlogPrivate(&blog, author)
 }
 
@@ -225,7 +245,13 @@ func (s *service) UpdateBlog(
 
 	args = append(args, blogID, author.ID)
 
-	tag, err := s.db.Pool().Exec(ctx, updateQuery, args...)
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, updateQuery, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -234,6 +260,18 @@ func (s *service) UpdateBlog(
 		return nil, network.NewNotFoundError("blog not found", nil)
 	}
 
+	// a new revision for the saves of the content, the slug and the image are not versioned
+	if b.Title != nil || b.Description != nil || b.DraftText != nil || b.Tags != nil {
+		_, err = revision.Capture(ctx, tx, blogID, model.RevisionDraft, author.ID, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
 	// Return updated blog
 	return s.GetBlogById(blogID, author)
 }
@@ -0,0 +1,178 @@
+package comment
+
+import (
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/comment/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	coredto "github.com/afteracademy/goserve/v2/dto"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+}
+
+func NewController(
+	authMFunc network.AuthenticationProvider,
+	authorizeMFunc network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/blog/comment", authMFunc, authorizeMFunc),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	read := group.Group("", common.KeyPermission(authModel.ReadPermission))
+	write := group.Group("", common.KeyPermission(authModel.GeneralPermission), c.Authentication())
+	read.GET("/blog/id/:id", c.getCommentsHandler)
+	write.POST("", c.postCommentHandler)
+	write.PUT("/id/:id", c.updateCommentHandler)
+	write.DELETE("/id/:id", c.deleteCommentHandler)
+	// moderation by the blog author and the moderators
+	write.PUT("/id/:id/hide", c.hideCommentHandler)
+	write.PUT("/id/:id/unhide", c.unhideCommentHandler)
+	write.PUT("/id/:id/pin", c.pinCommentHandler)
+	write.PUT("/id/:id/unpin", c.unpinCommentHandler)
+}
+
+func (c *controller) getCommentsHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	search, err := network.ReqQuery[dto.CommentSearch](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	// the key is taken before the comments are read, a change meanwhile moves past it
+	key, keyErr := c.service.CommentsCacheKey(uuidParam.ID, search)
+	if keyErr == nil {
+		comments, err := c.service.GetCommentsDtoCache(key)
+		if err == nil {
+			network.SendSuccessDataResponse(ctx, "success", &comments)
+			return
+		}
+	}
+
+	comments, err := c.service.GetPaginatedComments(uuidParam.ID, search)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", &comments)
+	if keyErr == nil {
+		c.service.SetCommentsDtoCache(key, comments)
+	}
+}
+
+func (c *controller) postCommentHandler(ctx *gin.Context) {
+	body, err := network.ReqBody[dto.CommentCreate](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	comment, err := c.service.CreateComment(body, user)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "comment created successfully", comment)
+}
+
+func (c *controller) updateCommentHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	body, err := network.ReqBody[dto.CommentUpdate](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	comment, err := c.service.UpdateComment(uuidParam.ID, body, user)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "comment updated successfully", comment)
+}
+
+func (c *controller) deleteCommentHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	err = c.service.DeleteComment(uuidParam.ID, user)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "comment deleted successfully")
+}
+
+func (c *controller) hideCommentHandler(ctx *gin.Context) {
+	c.moderate(ctx, c.service.HideComment, true, "comment hidden successfully")
+}
+
+func (c *controller) unhideCommentHandler(ctx *gin.Context) {
+	c.moderate(ctx, c.service.HideComment, false, "comment shown successfully")
+}
+
+func (c *controller) pinCommentHandler(ctx *gin.Context) {
+	c.moderate(ctx, c.service.PinComment, true, "comment pinned successfully")
+}
+
+func (c *controller) unpinCommentHandler(ctx *gin.Context) {
+	c.moderate(ctx, c.service.PinComment, false, "comment unpinned successfully")
+}
+
+func (c *controller) moderate(
+	ctx *gin.Context,
+	action func(id uuid.UUID, user *userModel.User, on bool) error,
+	on bool,
+	msg string,
+) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	err = action(uuidParam.ID, user, on)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, msg)
+}
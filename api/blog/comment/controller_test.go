@@ -0,0 +1,157 @@
+package comment
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/comment/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var learnerUser = &userModel.User{ID: uuid.New(), Email: "learner@abc.com", Name: "learner", Verified: true}
+
+func mockProviders() (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider) {
+	authProvider := new(network.MockAuthenticationProvider)
+	authProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, learnerUser)
+		ctx.Next()
+	}))
+	authorizeProvider := new(network.MockAuthorizationProvider)
+	authorizeProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+	return authProvider, authorizeProvider
+}
+
+func newCommentInfo(blogId uuid.UUID, text string) *dto.CommentInfo {
+	return &dto.CommentInfo{ID: uuid.New(), BlogID: blogId, Text: text, CreatedAt: time.Now()}
+}
+
+func TestCommentController_GetCommentsFromCache(t *testing.T) {
+	service := new(MockService)
+	blogId := uuid.New()
+	search := &dto.CommentSearch{Page: 1, Limit: 10}
+	service.On("CommentsCacheKey", blogId, search).Return("comments_key", nil)
+	service.On("GetCommentsDtoCache", "comments_key").Return([]*dto.CommentInfo{newCommentInfo(blogId, "cached")}, nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/blog/comment/blog/id/"+blogId.String()+"?page=1&limit=10", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"text":"cached"`)
+	service.AssertNotCalled(t, "GetPaginatedComments", mock.Anything, mock.Anything)
+}
+
+func TestCommentController_GetRepliesCacheMiss(t *testing.T) {
+	service := new(MockService)
+	blogId := uuid.New()
+	parentId := uuid.New()
+	search := &dto.CommentSearch{Page: 1, Limit: 10, ParentId: parentId.String(), ParentID: &parentId}
+	replies := []*dto.CommentInfo{newCommentInfo(blogId, "reply")}
+	service.On("CommentsCacheKey", blogId, search).Return("comments_key", nil)
+	service.On("GetCommentsDtoCache", "comments_key").Return(nil, errors.New("miss"))
+	service.On("GetPaginatedComments", blogId, search).Return(replies, nil)
+	service.On("SetCommentsDtoCache", "comments_key", replies).Return(nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	url := "/blog/comment/blog/id/" + blogId.String() + "?page=1&limit=10&parentId=" + parentId.String()
+	rr := network.MockTestController(t, "GET", url, "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"text":"reply"`)
+	service.AssertExpectations(t)
+}
+
+func TestCommentController_GetCommentsWithoutCacheKey(t *testing.T) {
+	service := new(MockService)
+	blogId := uuid.New()
+	search := &dto.CommentSearch{Page: 1, Limit: 10}
+	service.On("CommentsCacheKey", blogId, search).Return("", errors.New("redis is down"))
+	service.On("GetPaginatedComments", blogId, search).Return([]*dto.CommentInfo{newCommentInfo(blogId, "fresh")}, nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/blog/comment/blog/id/"+blogId.String()+"?page=1&limit=10", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"text":"fresh"`)
+	service.AssertNotCalled(t, "GetCommentsDtoCache", mock.Anything)
+	service.AssertNotCalled(t, "SetCommentsDtoCache", mock.Anything, mock.Anything)
+}
+
+func TestCommentController_GetCommentsInvalidParent(t *testing.T) {
+	service := new(MockService)
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/blog/comment/blog/id/"+uuid.NewString()+"?page=1&limit=10&parentId=abc", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "CommentsCacheKey", mock.Anything, mock.Anything)
+}
+
+func TestCommentController_CreateEmptyText(t *testing.T) {
+	service := new(MockService)
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	body := `{"blogId":"` + uuid.NewString() + `","text":""}`
+	rr := network.MockTestController(t, "POST", "/blog/comment", body, common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "CreateComment", mock.Anything, mock.Anything)
+}
+
+func TestCommentController_CreateReply(t *testing.T) {
+	service := new(MockService)
+	blogId := uuid.New()
+	parentId := uuid.New()
+	createDto := &dto.CommentCreate{BlogID: blogId, ParentID: &parentId, Text: "nice post"}
+	service.On("CreateComment", createDto, learnerUser).Return(newCommentInfo(blogId, "nice post"), nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	body := `{"blogId":"` + blogId.String() + `","parentId":"` + parentId.String() + `","text":"nice post"}`
+	rr := network.MockTestController(t, "POST", "/blog/comment", body, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"comment created successfully"`)
+	service.AssertExpectations(t)
+}
+
+func TestCommentController_HideForbidden(t *testing.T) {
+	service := new(MockService)
+	id := uuid.New()
+	service.On("HideComment", id, learnerUser, true).Return(
+		network.NewForbiddenError("permission denied: only the blog author or a moderator can do this", nil),
+	)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "PUT", "/blog/comment/id/"+id.String()+"/hide", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	service.AssertExpectations(t)
+}
+
+func TestCommentController_Unpin(t *testing.T) {
+	service := new(MockService)
+	id := uuid.New()
+	service.On("PinComment", id, learnerUser, false).Return(nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "PUT", "/blog/comment/id/"+id.String()+"/unpin", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"comment unpinned successfully"`)
+	service.AssertExpectations(t)
+}
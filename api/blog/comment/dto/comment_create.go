@@ -0,0 +1,10 @@
+package dto
+
+import "github.com/google/uuid"
+
+// CommentCreate comments on a published blog, or replies to one of its comments with the parentId
+type CommentCreate struct {
+	BlogID   uuid.UUID  `json:"blogId" binding:"required" validate:"required"`
+	ParentID *uuid.UUID `json:"parentId,omitempty" validate:"omitempty"`
+	Text     string     `json:"text" binding:"required" validate:"required,min=1,max=5000"`
+}
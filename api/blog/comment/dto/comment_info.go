@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	userDto "github.com/afteracademy/goserve-example-api-server-postgres/api/user/dto"
+	"github.com/google/uuid"
+)
+
+// CommentInfo of a deleted or hidden comment is still listed while it has replies,
+// only without its text and author so that the thread stays readable
+type CommentInfo struct {
+	ID        uuid.UUID           `json:"id"`
+	BlogID    uuid.UUID           `json:"blogId"`
+	ParentID  *uuid.UUID          `json:"parentId,omitempty"`
+	Author    *userDto.UserPublic `json:"author,omitempty"`
+	Text      string              `json:"text,omitempty"`
+	Depth     int16               `json:"depth"`
+	Replies   int64               `json:"replies"`
+	Pinned    bool                `json:"pinned"`
+	Removed   bool                `json:"removed"`
+	EditedAt  *time.Time          `json:"editedAt,omitempty"`
+	CreatedAt time.Time           `json:"createdAt"`
+}
+
+func NewCommentInfo(comment *model.Comment, author *userDto.UserPublic) *CommentInfo {
+	info := &CommentInfo{
+		ID:        comment.ID,
+		BlogID:    comment.BlogID,
+		ParentID:  comment.ParentID,
+		Depth:     comment.Depth,
+		Replies:   comment.Replies,
+		Pinned:    comment.Pinned,
+		Removed:   !comment.Visible(),
+		CreatedAt: comment.CreatedAt,
+	}
+
+	if !info.Removed {
+		info.Author = author
+		info.Text = comment.Text
+		info.EditedAt = comment.EditedAt
+	}
+
+	return info
+}
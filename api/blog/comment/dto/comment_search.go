@@ -0,0 +1,18 @@
+package dto
+
+import "github.com/google/uuid"
+
+// CommentSearch pages through the comments on a blog, or through the replies of a comment with the parentId
+type CommentSearch struct {
+	Page     int64      `form:"page" binding:"required" validate:"required,min=1,max=1000"`
+	Limit    int64      `form:"limit" binding:"required" validate:"required,min=1,max=100"`
+	ParentId string     `form:"parentId" validate:"omitempty,uuid"`
+	ParentID *uuid.UUID `form:"-" validate:"-"`
+}
+
+func (d *CommentSearch) GetValue() *CommentSearch {
+	if id, err := uuid.Parse(d.ParentId); err == nil {
+		d.ParentID = &id
+	}
+	return d
+}
@@ -0,0 +1,5 @@
+package dto
+
+type CommentUpdate struct {
+	Text string `json:"text" binding:"required" validate:"required,min=1,max=5000"`
+}
@@ -0,0 +1,69 @@
+package comment
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/comment/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) CreateComment(d *dto.CommentCreate, user *userModel.User) (*dto.CommentInfo, error) {
+	args := m.Called(d, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CommentInfo), args.Error(1)
+}
+
+func (m *MockService) UpdateComment(id uuid.UUID, d *dto.CommentUpdate, user *userModel.User) (*dto.CommentInfo, error) {
+	args := m.Called(id, d, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CommentInfo), args.Error(1)
+}
+
+func (m *MockService) DeleteComment(id uuid.UUID, user *userModel.User) error {
+	args := m.Called(id, user)
+	return args.Error(0)
+}
+
+func (m *MockService) HideComment(id uuid.UUID, user *userModel.User, hidden bool) error {
+	args := m.Called(id, user, hidden)
+	return args.Error(0)
+}
+
+func (m *MockService) PinComment(id uuid.UUID, user *userModel.User, pinned bool) error {
+	args := m.Called(id, user, pinned)
+	return args.Error(0)
+}
+
+func (m *MockService) GetPaginatedComments(blogId uuid.UUID, d *dto.CommentSearch) ([]*dto.CommentInfo, error) {
+	args := m.Called(blogId, d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.CommentInfo), args.Error(1)
+}
+
+func (m *MockService) CommentsCacheKey(blogId uuid.UUID, d *dto.CommentSearch) (string, error) {
+	args := m.Called(blogId, d)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockService) SetCommentsDtoCache(key string, comments []*dto.CommentInfo) error {
+	args := m.Called(key, comments)
+	return args.Error(0)
+}
+
+func (m *MockService) GetCommentsDtoCache(key string) ([]*dto.CommentInfo, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.CommentInfo), args.Error(1)
+}
@@ -0,0 +1,598 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/permission"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/comment/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	userDto "github.com/afteracademy/goserve-example-api-server-postgres/api/user/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/afteracademy/goserve/v2/redis"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	commentsCacheTTL = 10 * time.Minute
+	// the version outlives every cached page so that a reset can't reach a stale page
+	commentsVersionTTL = 24 * time.Hour
+)
+
+type Service interface {
+	CreateComment(d *dto.CommentCreate, user *userModel.User) (*dto.CommentInfo, error)
+	UpdateComment(id uuid.UUID, d *dto.CommentUpdate, user *userModel.User) (*dto.CommentInfo, error)
+	DeleteComment(id uuid.UUID, user *userModel.User) error
+	HideComment(id uuid.UUID, user *userModel.User, hidden bool) error
+	PinComment(id uuid.UUID, user *userModel.User, pinned bool) error
+	GetPaginatedComments(blogId uuid.UUID, d *dto.CommentSearch) ([]*dto.CommentInfo, error)
+	CommentsCacheKey(blogId uuid.UUID, d *dto.CommentSearch) (string, error)
+	SetCommentsDtoCache(key string, comments []*dto.CommentInfo) error
+	GetCommentsDtoCache(key string) ([]*dto.CommentInfo, error)
+}
+
+type service struct {
+	db                postgres.Database
+	store             redis.Store
+	commentCache      redis.Cache[dto.CommentInfo]
+	permissionService permission.Service
+	mfaRequiredRoles  []userModel.RoleCode
+}
+
+// the mfaRequiredRoles moderate nothing for users without mfa enabled, as in the authorization
+func NewService(
+	db postgres.Database,
+	store redis.Store,
+	permissionService permission.Service,
+	mfaRequiredRoles ...userModel.RoleCode,
+) Service {
+	return &service{
+		db:                db,
+		store:             store,
+		commentCache:      redis.NewCache[dto.CommentInfo](store),
+		permissionService: permissionService,
+		mfaRequiredRoles:  mfaRequiredRoles,
+	}
+}
+
+const commentColumns = `
+	id,
+	blog_id,
+	parent_id,
+	author_id,
+	text,
+	depth,
+	replies,
+	pinned,
+	hidden,
+	status,
+	edited_at,
+	created_at,
+	updated_at
+`
+
+func scanComment(row pgx.Row) (*model.Comment, error) {
+	var c model.Comment
+	err := row.Scan(
+		&c.ID,
+		&c.BlogID,
+		&c.ParentID,
+		&c.AuthorID,
+		&c.Text,
+		&c.Depth,
+		&c.Replies,
+		&c.Pinned,
+		&c.Hidden,
+		&c.Status,
+		&c.EditedAt,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *service) CreateComment(d *dto.CommentCreate, user *userModel.User) (*dto.CommentInfo, error) {
+	if !user.Verified {
+		return nil, network.NewForbiddenError("permission denied: verify your email to comment", nil)
+	}
+
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	blogQuery := `
+		SELECT id
+		FROM blogs
+		WHERE id = $1
+		  AND status = TRUE
+		  AND published = TRUE
+	`
+
+	var blogId uuid.UUID
+	err = tx.QueryRow(ctx, blogQuery, d.BlogID).Scan(&blogId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, network.NewNotFoundError("blog not found", nil)
+		}
+		return nil, err
+	}
+
+	var depth int16
+	if d.ParentID != nil {
+		parent, err := findComment(ctx, tx, *d.ParentID)
+		if err != nil {
+			return nil, err
+		}
+
+		if parent.BlogID != d.BlogID || !parent.Visible() {
+			return nil, network.NewNotFoundError("parent comment not found", nil)
+		}
+
+		if parent.Depth >= model.CommentMaxDepth {
+			return nil, network.NewBadRequestError("replies can't be nested any deeper", nil)
+		}
+
+		depth = parent.Depth + 1
+	}
+
+	insertQuery := `
+		INSERT INTO comments (blog_id, parent_id, author_id, text, depth)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING` + commentColumns
+
+	comment, err := scanComment(tx.QueryRow(ctx, insertQuery, d.BlogID, d.ParentID, user.ID, d.Text, depth))
+	if err != nil {
+		return nil, err
+	}
+
+	err = adjustCounts(ctx, tx, comment, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	s.clearCommentsCache(comment.BlogID)
+	return dto.NewCommentInfo(comment, userDto.NewUserPublic(user)), nil
+}
+
+func (s *service) UpdateComment(id uuid.UUID, d *dto.CommentUpdate, user *userModel.User) (*dto.CommentInfo, error) {
+	ctx := context.Background()
+
+	query := `
+		UPDATE comments
+		SET
+			text = $3,
+			edited_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		  AND author_id = $2
+		  AND status = TRUE
+		  AND hidden = FALSE
+		RETURNING` + commentColumns
+
+	comment, err := scanComment(s.db.Pool().QueryRow(ctx, query, id, user.ID, d.Text))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, network.NewNotFoundError("comment not found for you", nil)
+		}
+		return nil, err
+	}
+
+	s.clearCommentsCache(comment.BlogID)
+	return dto.NewCommentInfo(comment, userDto.NewUserPublic(user)), nil
+}
+
+// DeleteComment is allowed to the writer of the comment and to its moderators,
+// the replies stay and the comment is listed without its text while they exist
+func (s *service) DeleteComment(id uuid.UUID, user *userModel.User) error {
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	comment, err := findComment(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if !comment.Status {
+		return network.NewNotFoundError("comment not found", nil)
+	}
+
+	if comment.AuthorID != user.ID {
+		err = s.checkModerator(ctx, comment, user)
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE comments
+		SET
+			status = FALSE,
+			pinned = FALSE,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	_, err = tx.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if comment.Visible() {
+		err = adjustCounts(ctx, tx, comment, -1)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	s.clearCommentsCache(comment.BlogID)
+	return nil
+}
+
+// HideComment takes the comment out of the listing and the counts until it is shown again,
+// a hidden comment is also unpinned
+func (s *service) HideComment(id uuid.UUID, user *userModel.User, hidden bool) error {
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	comment, err := findComment(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if !comment.Status {
+		return network.NewNotFoundError("comment not found", nil)
+	}
+
+	err = s.checkModerator(ctx, comment, user)
+	if err != nil {
+		return err
+	}
+
+	if comment.Hidden == hidden {
+		return nil
+	}
+
+	query := `
+		UPDATE comments
+		SET
+			hidden = $2,
+			pinned = pinned AND NOT $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	_, err = tx.Exec(ctx, query, id, hidden)
+	if err != nil {
+		return err
+	}
+
+	delta := 1
+	if hidden {
+		delta = -1
+	}
+
+	err = adjustCounts(ctx, tx, comment, delta)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	s.clearCommentsCache(comment.BlogID)
+	return nil
+}
+
+// PinComment lists the comment before the others on its blog, only comments on the blog can be pinned
+func (s *service) PinComment(id uuid.UUID, user *userModel.User, pinned bool) error {
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	comment, err := findComment(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if !comment.Visible() {
+		return network.NewNotFoundError("comment not found", nil)
+	}
+
+	if comment.ParentID != nil {
+		return network.NewBadRequestError("replies can't be pinned", nil)
+	}
+
+	err = s.checkModerator(ctx, comment, user)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE comments
+		SET
+			pinned = $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	_, err = tx.Exec(ctx, query, id, pinned)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	s.clearCommentsCache(comment.BlogID)
+	return nil
+}
+
+// GetPaginatedComments lists the comments on a published blog pinned first and newest first,
+// or the replies of a comment oldest first
+func (s *service) GetPaginatedComments(blogId uuid.UUID, d *dto.CommentSearch) ([]*dto.CommentInfo, error) {
+	ctx := context.Background()
+
+	var exists bool
+	err := s.db.Pool().QueryRow(
+		ctx,
+		`
+		SELECT EXISTS (
+			SELECT 1
+			FROM blogs
+			WHERE id = $1
+			  AND status = TRUE
+			  AND published = TRUE
+		)
+		`,
+		blogId,
+	).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, network.NewNotFoundError("blog not found", nil)
+	}
+
+	offset := (d.Page - 1) * d.Limit
+	args := []any{blogId, d.Limit, offset}
+
+	parentFilter, order := "c.parent_id IS NULL", "c.pinned DESC, c.created_at DESC"
+	if d.ParentID != nil {
+		parentFilter, order = "c.parent_id = $4", "c.created_at ASC"
+		args = append(args, *d.ParentID)
+	}
+
+	query := `
+		SELECT
+			c.id,
+			c.blog_id,
+			c.parent_id,
+			c.author_id,
+			c.text,
+			c.depth,
+			c.replies,
+			c.pinned,
+			c.hidden,
+			c.status,
+			c.edited_at,
+			c.created_at,
+			c.updated_at,
+			u.name,
+			u.profile_pic_url
+		FROM comments c
+		INNER JOIN users u ON u.id = c.author_id
+		WHERE c.blog_id = $1
+		  AND ` + parentFilter + `
+		  AND (
+			(c.status = TRUE AND c.hidden = FALSE)
+			-- a removed comment stays as long as it leads to a visible reply
+			OR EXISTS (
+				WITH RECURSIVE descendants AS (
+					SELECT r.id, r.status, r.hidden
+					FROM comments r
+					WHERE r.parent_id = c.id
+					UNION ALL
+					SELECT r.id, r.status, r.hidden
+					FROM comments r
+					INNER JOIN descendants d ON r.parent_id = d.id
+				)
+				SELECT 1
+				FROM descendants
+				WHERE status = TRUE
+				  AND hidden = FALSE
+			)
+		  )
+		ORDER BY ` + order + `
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := s.db.Pool().Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := make([]*dto.CommentInfo, 0)
+
+	for rows.Next() {
+		var c model.Comment
+		var author userDto.UserPublic
+		if err := rows.Scan(
+			&c.ID,
+			&c.BlogID,
+			&c.ParentID,
+			&c.AuthorID,
+			&c.Text,
+			&c.Depth,
+			&c.Replies,
+			&c.Pinned,
+			&c.Hidden,
+			&c.Status,
+			&c.EditedAt,
+			&c.CreatedAt,
+			&c.UpdatedAt,
+			&author.Name,
+			&author.ProfilePicURL,
+		); err != nil {
+			return nil, err
+		}
+
+		author.ID = c.AuthorID
+		comments = append(comments, dto.NewCommentInfo(&c, &author))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+func (s *service) SetCommentsDtoCache(key string, comments []*dto.CommentInfo) error {
+	return s.commentCache.SetJSONList(key, comments, commentsCacheTTL)
+}
+
+func (s *service) GetCommentsDtoCache(key string) ([]*dto.CommentInfo, error) {
+	return s.commentCache.GetJSONList(key)
+}
+
+// CommentsCacheKey holds the comments version of the blog, a change of the comments moves
+// the version so that the pages cached before are no longer read and expire on their own.
+// The key is read once before the comments so that a page read before a change can only be
+// cached under the version it was read at.
+func (s *service) CommentsCacheKey(blogId uuid.UUID, d *dto.CommentSearch) (string, error) {
+	version, err := s.store.GetInstance().Get(context.Background(), commentsVersionKey(blogId)).Int64()
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return "", err
+	}
+
+	parent := "root"
+	if d.ParentID != nil {
+		parent = d.ParentID.String()
+	}
+
+	return fmt.Sprintf("comments_%s_%d_%s_%d_%d", blogId, version, parent, d.Page, d.Limit), nil
+}
+
+func (s *service) clearCommentsCache(blogId uuid.UUID) {
+	ctx := context.Background()
+	key := commentsVersionKey(blogId)
+
+	err := s.store.GetInstance().Incr(ctx, key).Err()
+	if err == nil {
+		err = s.store.GetInstance().Expire(ctx, key, commentsVersionTTL).Err()
+	}
+	if err != nil {
+		log.Printf("comments cache of blog %s could not be cleared: %v", blogId, err)
+	}
+}
+
+func commentsVersionKey(blogId uuid.UUID) string {
+	return "comments_version_" + blogId.String()
+}
+
+// checkModerator allows the author of the blog and the users with the comment moderation permission
+func (s *service) checkModerator(ctx context.Context, comment *model.Comment, user *userModel.User) error {
+	mfaRequired := false
+	for _, role := range user.Roles {
+		if !user.MfaEnabled && slices.Contains(s.mfaRequiredRoles, role.Code) {
+			mfaRequired = true
+			continue
+		}
+		if s.permissionService.HasAnyPermission(role.Code, userModel.PermissionCommentModerate) {
+			return nil
+		}
+	}
+
+	var authorId uuid.UUID
+	err := s.db.Pool().QueryRow(ctx, `SELECT author_id FROM blogs WHERE id = $1`, comment.BlogID).Scan(&authorId)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	if authorId != user.ID {
+		if mfaRequired {
+			return network.NewForbiddenError("permission denied: enable mfa to use this role", nil)
+		}
+		return network.NewForbiddenError("permission denied: only the blog author or a moderator can do this", nil)
+	}
+
+	return nil
+}
+
+// findComment locks the comment row for the rest of the transaction
+func findComment(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*model.Comment, error) {
+	query := `SELECT` + commentColumns + `FROM comments WHERE id = $1 FOR UPDATE`
+
+	comment, err := scanComment(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, network.NewNotFoundError("comment not found", nil)
+		}
+		return nil, err
+	}
+	return comment, nil
+}
+
+// adjustCounts moves the replies count of the parent and the comments count and score of
+// the blog as the comment becomes visible or stops being visible, the rows are locked from the
+// comment up to the blog in every transaction so that they can't deadlock
+func adjustCounts(ctx context.Context, tx pgx.Tx, comment *model.Comment, delta int) error {
+	if comment.ParentID != nil {
+		_, err := tx.Exec(ctx, `UPDATE comments SET replies = replies + $2 WHERE id = $1`, *comment.ParentID, delta)
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE blogs
+		SET
+			comments = comments + $2,
+			score = ` + model.BlogScoreSQL("likes", "comments + $2") + `
+		WHERE id = $1
+	`
+
+	_, err := tx.Exec(ctx, query, comment.BlogID, delta)
+	return err
+}
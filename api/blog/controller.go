@@ -1,6 +1,12 @@
 package blog
 
 import (
+	"log"
+
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/view"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
 	coredto "github.com/afteracademy/goserve/v2/dto"
 	"github.com/afteracademy/goserve/v2/network"
 	"github.com/gin-gonic/gin"
@@ -8,21 +14,34 @@ import (
 
 type controller struct {
 	network.Controller
-	service Service
+	common.ContextPayload
+	service     Service
+	viewService view.Service
+	cookies     common.TokenCookies
 }
 
 func NewController(
 	authMFunc network.AuthenticationProvider,
 	authorizeMFunc network.AuthorizationProvider,
 	service Service,
+	viewService view.Service,
+	cookies common.TokenCookies,
 ) network.Controller {
 	return &controller{
-		Controller: network.NewController("/blog", authMFunc, authorizeMFunc),
-		service:    service,
+		Controller:     network.NewController("/blog", authMFunc, authorizeMFunc),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+		viewService:    viewService,
+		cookies:        cookies,
 	}
 }
 
 func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(
+		common.KeyPermission(authModel.ReadPermission),
+		common.TokenScope(authModel.ScopeBlogRead),
+		common.OptionalAuthentication(c.cookies, c.Authentication()),
+	)
 	group.GET("/id/:id", c.getBlogByIdHandler)
 	group.GET("/slug/:slug", c.getBlogBySlugHandler)
 }
@@ -36,7 +55,7 @@ func (c *controller) getBlogByIdHandler(ctx *gin.Context) {
 
 	blog, err := c.service.GetBlogDtoCacheById(uuidParam.ID)
 	if err == nil {
-		network.SendSuccessDataResponse(ctx, "success", blog)
+		c.sendBlog(ctx, blog)
 		return
 	}
 
@@ -46,7 +65,7 @@ func (c *controller) getBlogByIdHandler(ctx *gin.Context) {
 		return
 	}
 
-	network.SendSuccessDataResponse(ctx, "success", blog)
+	c.sendBlog(ctx, blog)
 	c.service.SetBlogDtoCacheById(blog)
 }
 
@@ -59,7 +78,7 @@ func (c *controller) getBlogBySlugHandler(ctx *gin.Context) {
 
 	blog, err := c.service.GetBlogDtoCacheBySlug(slug.Slug)
 	if err == nil {
-		network.SendSuccessDataResponse(ctx, "success", blog)
+		c.sendBlog(ctx, blog)
 		return
 	}
 
@@ -69,6 +88,33 @@ func (c *controller) getBlogBySlugHandler(ctx *gin.Context) {
 		return
 	}
 
-	network.SendSuccessDataResponse(ctx, "success", blog)
+	c.sendBlog(ctx, blog)
 	c.service.SetBlogDtoCacheBySlug(blog)
 }
+
+// sendBlog adds the like of the signed in user to a copy of the blog, the cached blog is shared by every user.
+// The view is recorded here so that the blogs served from the cache are counted as well.
+func (c *controller) sendBlog(ctx *gin.Context, blog *dto.BlogPublic) {
+	user, ok := c.GetUser(ctx)
+
+	var viewer string
+	if ok {
+		viewer = view.Viewer(&user.ID, "", "")
+	} else {
+		viewer = view.Viewer(nil, ctx.ClientIP(), ctx.Request.UserAgent())
+	}
+	if err := c.viewService.RecordView(blog.ID, viewer); err != nil {
+		log.Printf("view of blog %s could not be recorded: %v", blog.ID, err)
+	}
+
+	if ok {
+		liked, err := c.service.IsBlogLikedByUser(blog.ID, user.ID)
+		if err == nil {
+			personal := *blog
+			personal.LikedByMe = &liked
+			blog = &personal
+		}
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", blog)
+}
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+)
+
+// BlogFlagged is a blog in the moderation queue with its open reports counted by reason
+type BlogFlagged struct {
+	BlogInfo
+	Published      bool                         `json:"published"`
+	Reports        int64                        `json:"reports"`
+	Reasons        map[model.ReportReason]int64 `json:"reasons"`
+	LastReportedAt time.Time                    `json:"lastReportedAt"`
+}
+
+func NewBlogFlagged(blog *model.Blog, reports int64, lastReportedAt time.Time) (*BlogFlagged, error) {
+	info, err := NewBlogInfo(blog)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlogFlagged{
+		BlogInfo:       *info,
+		Published:      blog.Published,
+		Reports:        reports,
+		Reasons:        make(map[model.ReportReason]int64),
+		LastReportedAt: lastReportedAt,
+	}, nil
+}
@@ -19,6 +19,10 @@ type BlogPublic struct {
 	ImgURL      *string         `json:"imgUrl,omitempty" validate:"omitempty,uri,max=200"`
 	Score       *float64        `json:"score,omitempty" validate:"omitempty,min=0,max=1"`
 	Tags        *[]string       `json:"tags,omitempty" validate:"omitempty,dive,uppercase"`
+	Views       int64           `json:"views"`
+	Likes       int64           `json:"likes"`
+	Comments    int64           `json:"comments"`
+	LikedByMe   *bool           `json:"likedByMe,omitempty"`
 	PublishedAt *time.Time      `json:"publishedAt,omitempty"`
 }
 
@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/afteracademy/goserve/v2/utility"
+	"github.com/google/uuid"
+)
+
+type ReportInfo struct {
+	ID         uuid.UUID          `json:"id" validate:"required"`
+	ReporterID uuid.UUID          `json:"reporterId" validate:"required"`
+	Reason     model.ReportReason `json:"reason" validate:"required"`
+	Text       *string            `json:"text,omitempty"`
+	CreatedAt  time.Time          `json:"createdAt" validate:"required"`
+}
+
+func NewReportInfo(report *model.BlogReport) (*ReportInfo, error) {
+	return utility.MapTo[ReportInfo](report)
+}
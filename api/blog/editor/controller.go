@@ -1,6 +1,8 @@
 package editor
 
 import (
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
 	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
 	"github.com/afteracademy/goserve-example-api-server-postgres/common"
 	coredto "github.com/afteracademy/goserve/v2/dto"
@@ -27,12 +29,28 @@ func NewController(
 }
 
 func (c *controller) MountRoutes(group *gin.RouterGroup) {
-	group.Use(c.Authentication(), c.Authorization(string(userModel.RoleCodeEditor)))
-	group.GET("/id/:id", c.getBlogHandler)
-	group.PUT("/publish/id/:id", c.publishBlogHandler)
-	group.PUT("/unpublish/id/:id", c.unpublishBlogHandler)
-	group.GET("/submitted", c.getSubmittedBlogsHandler)
-	group.GET("/published", c.getPublishedBlogsHandler)
+	group.Use(common.KeyPermission(authModel.AuthorPermission))
+	read := group.Group("",
+		common.TokenScope(authModel.ScopeBlogRead),
+		c.Authentication(),
+		c.Authorization(string(userModel.PermissionBlogPublish)),
+	)
+	write := group.Group("",
+		common.TokenScope(authModel.ScopeBlogWrite),
+		c.Authentication(),
+		c.Authorization(string(userModel.PermissionBlogPublish)),
+	)
+	read.GET("/id/:id", c.getBlogHandler)
+	write.PUT("/publish/id/:id", c.publishBlogHandler)
+	write.PUT("/unpublish/id/:id", c.unpublishBlogHandler)
+	read.GET("/submitted", c.getSubmittedBlogsHandler)
+	read.GET("/published", c.getPublishedBlogsHandler)
+	// moderation of the blogs flagged by the reports of the users
+	read.GET("/flagged", c.getFlaggedBlogsHandler)
+	read.GET("/flagged/id/:id", c.getBlogReportsHandler)
+	write.PUT("/flagged/id/:id/dismiss", c.dismissFlagHandler)
+	write.PUT("/flagged/id/:id/unpublish", c.unpublishFlaggedHandler)
+	write.PUT("/flagged/id/:id/deactivate", c.deactivateFlaggedHandler)
 }
 
 func (c *controller) getBlogHandler(ctx *gin.Context) {
@@ -58,7 +76,9 @@ func (c *controller) publishBlogHandler(ctx *gin.Context) {
 		return
 	}
 
-	err = c.service.BlogPublication(uuidParam.ID, true)
+	editor := c.MustGetUser(ctx)
+
+	err = c.service.BlogPublication(uuidParam.ID, true, editor)
 	if err != nil {
 		network.SendMixedError(ctx, err)
 		return
@@ -74,7 +94,9 @@ func (c *controller) unpublishBlogHandler(ctx *gin.Context) {
 		return
 	}
 
-	err = c.service.BlogPublication(uuidParam.ID, false)
+	editor := c.MustGetUser(ctx)
+
+	err = c.service.BlogPublication(uuidParam.ID, false, editor)
 	if err != nil {
 		network.SendMixedError(ctx, err)
 		return
@@ -114,3 +136,71 @@ func (c *controller) getPublishedBlogsHandler(ctx *gin.Context) {
 
 	network.SendSuccessDataResponse(ctx, "success", &blogs)
 }
+
+func (c *controller) getFlaggedBlogsHandler(ctx *gin.Context) {
+	pagination, err := network.ReqQuery[coredto.Pagination](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	blogs, err := c.service.GetPaginatedFlagged(pagination)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", &blogs)
+}
+
+func (c *controller) getBlogReportsHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	pagination, err := network.ReqQuery[coredto.Pagination](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	reports, err := c.service.GetOpenReports(uuidParam.ID, pagination)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", &reports)
+}
+
+func (c *controller) dismissFlagHandler(ctx *gin.Context) {
+	c.resolveFlag(ctx, model.ReportDismissed, "blog reports dismissed successfully")
+}
+
+func (c *controller) unpublishFlaggedHandler(ctx *gin.Context) {
+	c.resolveFlag(ctx, model.ReportUnpublished, "flagged blog unpublished successfully")
+}
+
+func (c *controller) deactivateFlaggedHandler(ctx *gin.Context) {
+	c.resolveFlag(ctx, model.ReportDeactivated, "flagged blog deactivated successfully")
+}
+
+func (c *controller) resolveFlag(ctx *gin.Context, resolution model.ReportResolution, msg string) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	editor := c.MustGetUser(ctx)
+
+	err = c.service.ResolveFlag(uuidParam.ID, resolution, editor)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, msg)
+}
@@ -7,7 +7,9 @@ import (
 
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/dto"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/revision"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
 	coredto "github.com/afteracademy/goserve/v2/dto"
 	"github.com/afteracademy/goserve/v2/network"
 	"github.com/afteracademy/goserve/v2/postgres"
@@ -17,9 +19,12 @@ import (
 
 type Service interface {
 	GetBlogById(id uuid.UUID) (*dto.BlogPrivate, error)
-	BlogPublication(blogId uuid.UUID, publish bool) error
+	BlogPublication(blogId uuid.UUID, publish bool, editor *userModel.User) error
 	GetPaginatedPublished(p *coredto.Pagination) ([]*dto.BlogInfo, error)
 	GetPaginatedSubmitted(p *coredto.Pagination) ([]*dto.BlogInfo, error)
+	GetPaginatedFlagged(p *coredto.Pagination) ([]*dto.BlogFlagged, error)
+	GetOpenReports(blogId uuid.UUID, p *coredto.Pagination) ([]*dto.ReportInfo, error)
+	ResolveFlag(blogId uuid.UUID, resolution model.ReportResolution, editor *userModel.User) error
 }
 
 type service struct {
@@ -37,9 +42,33 @@ func NewService(db postgres.Database, userService user.Service) Service {
 func (s *service) BlogPublication(
 	blogID uuid.UUID,
 	publish bool,
+	editor *userModel.User,
 ) error {
 	ctx := context.Background()
 
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = s.blogPublication(ctx, tx, blogID, publish, editor)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// blogPublication publishes or unpublishes the blog in the transaction of the caller,
+// the blog row stays locked until that transaction ends
+func (s *service) blogPublication(
+	ctx context.Context,
+	tx pgx.Tx,
+	blogID uuid.UUID,
+	publish bool,
+	editor *userModel.User,
+) error {
 	selectQuery := `
 		SELECT
 			published,
@@ -50,6 +79,7 @@ func (s *service) BlogPublication(
 		FROM blogs
 		WHERE id = $1
 		  AND status = TRUE
+		FOR UPDATE
 	`
 
 	var (
@@ -60,7 +90,7 @@ func (s *service) BlogPublication(
 		publishedAt *time.Time
 	)
 
-	err := s.db.Pool().QueryRow(
+	err := tx.QueryRow(
 		ctx,
 		selectQuery,
 		blogID,
@@ -130,7 +160,7 @@ func (s *service) BlogPublication(
 		text = nil
 	}
 
-	tag, err := s.db.Pool().Exec(
+	tag, err := tx.Exec(
 		ctx,
 		updateQuery,
 		!publish,
@@ -148,6 +178,13 @@ func (s *service) BlogPublication(
 		return network.NewNotFoundError("blog not found", nil)
 	}
 
+	if publish {
+		_, err = revision.Capture(ctx, tx, blogID, model.RevisionPublication, editor.ID, nil)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -301,3 +338,255 @@ func (s *service) getPaginated(
 
 	return dtos, nil
 }
+
+// GetPaginatedFlagged is the moderation queue, the blogs with the most open reports come first
+func (s *service) GetPaginatedFlagged(p *coredto.Pagination) ([]*dto.BlogFlagged, error) {
+	ctx := context.Background()
+	offset := (p.Page - 1) * p.Limit
+
+	query := `
+		SELECT
+			b.id,
+			b.title,
+			b.description,
+			b.slug,
+			b.img_url,
+			b.score,
+			b.tags,
+			b.published,
+			COUNT(r.id) AS reports,
+			MAX(r.created_at) AS last_reported_at
+		FROM blogs b
+		INNER JOIN blog_reports r
+			ON r.blog_id = b.id
+		   AND r.resolution IS NULL
+		WHERE b.flagged = TRUE
+		  AND b.status = TRUE
+		GROUP BY b.id
+		ORDER BY reports DESC, last_reported_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := s.db.Pool().Query(ctx, query, p.Limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos := make([]*dto.BlogFlagged, 0)
+	byId := make(map[uuid.UUID]*dto.BlogFlagged)
+	ids := make([]uuid.UUID, 0)
+
+	for rows.Next() {
+		var (
+			b              model.Blog
+			reports        int64
+			lastReportedAt time.Time
+		)
+		if err := rows.Scan(
+			&b.ID,
+			&b.Title,
+			&b.Description,
+			&b.Slug,
+			&b.ImgURL,
+			&b.Score,
+			&b.Tags,
+			&b.Published,
+			&reports,
+			&lastReportedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		d, err := dto.NewBlogFlagged(&b, reports, lastReportedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		dtos = append(dtos, d)
+		byId[b.ID] = d
+		ids = append(ids, b.ID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return dtos, nil
+	}
+
+	reasonQuery := `
+		SELECT blog_id, reason, COUNT(*)
+		FROM blog_reports
+		WHERE blog_id = ANY($1)
+		  AND resolution IS NULL
+		GROUP BY blog_id, reason
+	`
+
+	reasonRows, err := s.db.Pool().Query(ctx, reasonQuery, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer reasonRows.Close()
+
+	for reasonRows.Next() {
+		var (
+			blogId uuid.UUID
+			reason model.ReportReason
+			count  int64
+		)
+		if err := reasonRows.Scan(&blogId, &reason, &count); err != nil {
+			return nil, err
+		}
+		if d, ok := byId[blogId]; ok {
+			d.Reasons[reason] = count
+		}
+	}
+
+	if err := reasonRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dtos, nil
+}
+
+func (s *service) GetOpenReports(blogId uuid.UUID, p *coredto.Pagination) ([]*dto.ReportInfo, error) {
+	ctx := context.Background()
+	offset := (p.Page - 1) * p.Limit
+
+	query := `
+		SELECT
+			id,
+			reporter_id,
+			reason,
+			text,
+			created_at
+		FROM blog_reports
+		WHERE blog_id = $1
+		  AND resolution IS NULL
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := s.db.Pool().Query(ctx, query, blogId, p.Limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos := make([]*dto.ReportInfo, 0)
+
+	for rows.Next() {
+		var r model.BlogReport
+		if err := rows.Scan(
+			&r.ID,
+			&r.ReporterID,
+			&r.Reason,
+			&r.Text,
+			&r.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		d, err := dto.NewReportInfo(&r)
+		if err != nil {
+			return nil, err
+		}
+
+		dtos = append(dtos, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dtos, nil
+}
+
+// ResolveFlag unpublishes or deactivates a flagged blog, or dismisses its reports, and then
+// closes the open reports with the resolution. The blog is published again only through
+// the submission of its author. It all runs under the lock of the blog row, so the reports
+// made meanwhile wait for it and then count towards a new flag.
+func (s *service) ResolveFlag(blogId uuid.UUID, resolution model.ReportResolution, editor *userModel.User) error {
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	selectQuery := `
+		SELECT flagged, published
+		FROM blogs
+		WHERE id = $1
+		  AND status = TRUE
+		FOR UPDATE
+	`
+
+	var flagged, published bool
+
+	err = tx.QueryRow(ctx, selectQuery, blogId).Scan(&flagged, &published)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return network.NewNotFoundError(
+				"blog for id "+blogId.String()+" not found",
+				nil,
+			)
+		}
+		return err
+	}
+
+	if !flagged {
+		return network.NewBadRequestError(
+			"blog for id "+blogId.String()+" is not flagged",
+			nil,
+		)
+	}
+
+	// the blog could already be unpublished by an editor since it was flagged
+	if resolution != model.ReportDismissed && published {
+		err = s.blogPublication(ctx, tx, blogId, false, editor)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE blogs SET flagged = FALSE WHERE id = $1`, blogId)
+	if err != nil {
+		return err
+	}
+
+	if resolution == model.ReportDeactivated {
+		deactivateQuery := `
+			UPDATE blogs
+			SET
+				status = FALSE,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`
+		_, err = tx.Exec(ctx, deactivateQuery, blogId)
+		if err != nil {
+			return err
+		}
+	}
+
+	resolveQuery := `
+		UPDATE blog_reports
+		SET
+			resolution = $2,
+			resolved_by = $3,
+			resolved_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE blog_id = $1
+		  AND resolution IS NULL
+	`
+
+	_, err = tx.Exec(ctx, resolveQuery, blogId, resolution, editor.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
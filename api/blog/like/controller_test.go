@@ -0,0 +1,101 @@
+package like
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/like/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var learnerUser = &userModel.User{ID: uuid.New(), Email: "learner@abc.com", Name: "learner"}
+
+func mockProviders() (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider) {
+	authProvider := new(network.MockAuthenticationProvider)
+	authProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, learnerUser)
+		ctx.Next()
+	}))
+	authorizeProvider := new(network.MockAuthorizationProvider)
+	authorizeProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+	return authProvider, authorizeProvider
+}
+
+func TestLikeController_LikeDefaultReaction(t *testing.T) {
+	service := new(MockService)
+	blogId := uuid.New()
+	reaction := model.ReactionLike
+	service.On("Like", blogId, learnerUser, model.ReactionLike).Return(dto.NewLikeInfo(blogId, 3, &reaction), nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "PUT", "/blog/like/id/"+blogId.String(), "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"likes":3`)
+	assert.Contains(t, rr.Body.String(), `"liked":true`)
+	assert.Contains(t, rr.Body.String(), `"reaction":"LIKE"`)
+	service.AssertExpectations(t)
+}
+
+func TestLikeController_LikeWithReaction(t *testing.T) {
+	service := new(MockService)
+	blogId := uuid.New()
+	reaction := model.ReactionInsightful
+	service.On("Like", blogId, learnerUser, model.ReactionInsightful).Return(dto.NewLikeInfo(blogId, 1, &reaction), nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "PUT", "/blog/like/id/"+blogId.String()+"?reaction=INSIGHTFUL", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"reaction":"INSIGHTFUL"`)
+	service.AssertExpectations(t)
+}
+
+func TestLikeController_LikeUnknownReaction(t *testing.T) {
+	service := new(MockService)
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "PUT", "/blog/like/id/"+uuid.NewString()+"?reaction=ANGRY", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "Like", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLikeController_Unlike(t *testing.T) {
+	service := new(MockService)
+	blogId := uuid.New()
+	service.On("Unlike", blogId, learnerUser).Return(dto.NewLikeInfo(blogId, 0, nil), nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "DELETE", "/blog/like/id/"+blogId.String(), "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"liked":false`)
+	assert.NotContains(t, rr.Body.String(), `"reaction"`)
+	service.AssertExpectations(t)
+}
+
+func TestLikeController_GetReactionsNotFound(t *testing.T) {
+	service := new(MockService)
+	blogId := uuid.New()
+	service.On("GetReactions", blogId).Return(nil, network.NewNotFoundError("blog not found", nil))
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/blog/like/id/"+blogId.String(), "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	service.AssertExpectations(t)
+}
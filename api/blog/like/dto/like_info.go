@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/google/uuid"
+)
+
+// LikeInfo is the state of the blog for the user after a like or an unlike
+type LikeInfo struct {
+	BlogID   uuid.UUID           `json:"blogId"`
+	Likes    int64               `json:"likes"`
+	Liked    bool                `json:"liked"`
+	Reaction *model.ReactionType `json:"reaction,omitempty"`
+}
+
+func NewLikeInfo(blogId uuid.UUID, likes int64, reaction *model.ReactionType) *LikeInfo {
+	return &LikeInfo{
+		BlogID:   blogId,
+		Likes:    likes,
+		Liked:    reaction != nil,
+		Reaction: reaction,
+	}
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+
+// ReactionQuery picks the reaction of a like, a plain like when it is left empty
+type ReactionQuery struct {
+	Reaction model.ReactionType `form:"reaction" validate:"omitempty,oneof=LIKE LOVE INSIGHTFUL CELEBRATE"`
+}
+
+func (d *ReactionQuery) GetValue() *ReactionQuery {
+	if d.Reaction == "" {
+		d.Reaction = model.ReactionLike
+	}
+	return d
+}
@@ -0,0 +1,12 @@
+package dto
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/google/uuid"
+)
+
+type ReactionSummary struct {
+	BlogID    uuid.UUID                    `json:"blogId"`
+	Likes     int64                        `json:"likes"`
+	Reactions map[model.ReactionType]int64 `json:"reactions"`
+}
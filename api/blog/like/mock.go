@@ -0,0 +1,37 @@
+package like
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/like/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) Like(blogId uuid.UUID, user *userModel.User, reaction model.ReactionType) (*dto.LikeInfo, error) {
+	args := m.Called(blogId, user, reaction)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LikeInfo), args.Error(1)
+}
+
+func (m *MockService) Unlike(blogId uuid.UUID, user *userModel.User) (*dto.LikeInfo, error) {
+	args := m.Called(blogId, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LikeInfo), args.Error(1)
+}
+
+func (m *MockService) GetReactions(blogId uuid.UUID) (*dto.ReactionSummary, error) {
+	args := m.Called(blogId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ReactionSummary), args.Error(1)
+}
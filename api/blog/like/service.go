@@ -0,0 +1,234 @@
+package like
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/like/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Service interface {
+	Like(blogId uuid.UUID, user *userModel.User, reaction model.ReactionType) (*dto.LikeInfo, error)
+	Unlike(blogId uuid.UUID, user *userModel.User) (*dto.LikeInfo, error)
+	GetReactions(blogId uuid.UUID) (*dto.ReactionSummary, error)
+}
+
+type service struct {
+	db postgres.Database
+}
+
+func NewService(db postgres.Database) Service {
+	return &service{
+		db: db,
+	}
+}
+
+// Like is idempotent, a user has one reaction on a blog and liking again only changes
+// the reaction. The likes of the blog move in the transaction that adds the reaction,
+// the primary key makes the concurrent likes of a user wait for each other.
+func (s *service) Like(blogId uuid.UUID, user *userModel.User, reaction model.ReactionType) (*dto.LikeInfo, error) {
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = findPublishedBlog(ctx, tx, blogId)
+	if err != nil {
+		return nil, err
+	}
+
+	insertQuery := `
+		INSERT INTO blog_reactions (blog_id, user_id, reaction)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (blog_id, user_id) DO NOTHING
+	`
+
+	tag, err := tx.Exec(ctx, insertQuery, blogId, user.ID, reaction)
+	if err != nil {
+		return nil, err
+	}
+
+	var likes int64
+	if tag.RowsAffected() == 1 {
+		likes, err = adjustLikes(ctx, tx, blogId, 1)
+	} else {
+		likes, err = changeReaction(ctx, tx, blogId, user.ID, reaction)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return dto.NewLikeInfo(blogId, likes, &reaction), nil
+}
+
+// Unlike is idempotent, the likes of the blog only move when a reaction is removed
+func (s *service) Unlike(blogId uuid.UUID, user *userModel.User) (*dto.LikeInfo, error) {
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = findPublishedBlog(ctx, tx, blogId)
+	if err != nil {
+		return nil, err
+	}
+
+	deleteQuery := `
+		DELETE FROM blog_reactions
+		WHERE blog_id = $1
+		  AND user_id = $2
+	`
+
+	tag, err := tx.Exec(ctx, deleteQuery, blogId, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var likes int64
+	if tag.RowsAffected() == 1 {
+		likes, err = adjustLikes(ctx, tx, blogId, -1)
+	} else {
+		err = tx.QueryRow(ctx, `SELECT likes FROM blogs WHERE id = $1`, blogId).Scan(&likes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return dto.NewLikeInfo(blogId, likes, nil), nil
+}
+
+func (s *service) GetReactions(blogId uuid.UUID) (*dto.ReactionSummary, error) {
+	ctx := context.Background()
+
+	summary := &dto.ReactionSummary{
+		BlogID:    blogId,
+		Reactions: make(map[model.ReactionType]int64),
+	}
+
+	query := `
+		SELECT likes
+		FROM blogs
+		WHERE id = $1
+		  AND status = TRUE
+		  AND published = TRUE
+	`
+
+	err := s.db.Pool().QueryRow(ctx, query, blogId).Scan(&summary.Likes)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, network.NewNotFoundError("blog not found", nil)
+		}
+		return nil, err
+	}
+
+	countQuery := `
+		SELECT reaction, COUNT(*)
+		FROM blog_reactions
+		WHERE blog_id = $1
+		GROUP BY reaction
+	`
+
+	rows, err := s.db.Pool().Query(ctx, countQuery, blogId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reaction model.ReactionType
+		var count int64
+		if err := rows.Scan(&reaction, &count); err != nil {
+			return nil, err
+		}
+		summary.Reactions[reaction] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func findPublishedBlog(ctx context.Context, tx pgx.Tx, blogId uuid.UUID) error {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM blogs
+			WHERE id = $1
+			  AND status = TRUE
+			  AND published = TRUE
+		)
+	`
+
+	var exists bool
+	err := tx.QueryRow(ctx, query, blogId).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return network.NewNotFoundError("blog not found", nil)
+	}
+	return nil
+}
+
+// adjustLikes moves the likes and the score of the blog in a single update so that the
+// concurrent likes of different users are all counted
+func adjustLikes(ctx context.Context, tx pgx.Tx, blogId uuid.UUID, delta int) (int64, error) {
+	query := `
+		UPDATE blogs
+		SET
+			likes = likes + $2,
+			score = ` + model.BlogScoreSQL("likes + $2", "comments") + `
+		WHERE id = $1
+		RETURNING likes
+	`
+
+	var likes int64
+	err := tx.QueryRow(ctx, query, blogId, delta).Scan(&likes)
+	return likes, err
+}
+
+func changeReaction(
+	ctx context.Context, tx pgx.Tx, blogId uuid.UUID, userId uuid.UUID, reaction model.ReactionType,
+) (int64, error) {
+	query := `
+		UPDATE blog_reactions
+		SET
+			reaction = $3,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE blog_id = $1
+		  AND user_id = $2
+		  AND reaction <> $3
+	`
+
+	_, err := tx.Exec(ctx, query, blogId, userId, reaction)
+	if err != nil {
+		return 0, err
+	}
+
+	var likes int64
+	err = tx.QueryRow(ctx, `SELECT likes FROM blogs WHERE id = $1`, blogId).Scan(&likes)
+	return likes, err
+}
@@ -31,3 +31,10 @@ type Blog struct {
 	CreatedAt   time.Time  // created_at
 	UpdatedAt   time.Time  // updated_at
 }
+
+// BlogScoreSQL is the sql expression of the score of a blog for its likes and comments,
+// the score grows with the log of the engagement from the default 0.01 up to 1, the
+// blog_reactions_take_back_likes trigger repeats it for the likes of the deleted users
+func BlogScoreSQL(likes string, comments string) string {
+	return "LEAST(1, 0.01 + LN(1 + " + likes + " + 2 * " + comments + ") / LN(10001))"
+}
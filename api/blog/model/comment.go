@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const CommentsTableName = "comments"
+
+// CommentMaxDepth is the deepest level of replies, the comments on a blog are at depth 0
+const CommentMaxDepth = 4
+
+// Comment is soft deleted with status false, a deleted or hidden comment stops counting
+// in the comments of its blog and the replies of its parent
+type Comment struct {
+	ID        uuid.UUID  // id
+	BlogID    uuid.UUID  // blog_id
+	ParentID  *uuid.UUID // parent_id
+	AuthorID  uuid.UUID  // author_id
+	Text      string     // text
+	Depth     int16      // depth
+	Replies   int64      // replies
+	Pinned    bool       // pinned
+	Hidden    bool       // hidden
+	Status    bool       // status
+	EditedAt  *time.Time // edited_at
+	CreatedAt time.Time  // created_at
+	UpdatedAt time.Time  // updated_at
+}
+
+// Visible tells whether the comment is shown and counted
+func (c *Comment) Visible() bool {
+	return c.Status && !c.Hidden
+}
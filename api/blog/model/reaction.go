@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const BlogReactionsTableName = "blog_reactions"
+
+type ReactionType string
+
+const (
+	ReactionLike       ReactionType = "LIKE"
+	ReactionLove       ReactionType = "LOVE"
+	ReactionInsightful ReactionType = "INSIGHTFUL"
+	ReactionCelebrate  ReactionType = "CELEBRATE"
+)
+
+// BlogReaction is the one reaction of a user on a blog, every reaction counts as a like
+type BlogReaction struct {
+	BlogID    uuid.UUID    // blog_id
+	UserID    uuid.UUID    // user_id
+	Reaction  ReactionType // reaction
+	CreatedAt time.Time    // created_at
+	UpdatedAt time.Time    // updated_at
+}
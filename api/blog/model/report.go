@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const BlogReportsTableName = "blog_reports"
+
+type ReportReason string
+
+const (
+	ReportReasonSpam           ReportReason = "SPAM"
+	ReportReasonHarassment     ReportReason = "HARASSMENT"
+	ReportReasonHate           ReportReason = "HATE"
+	ReportReasonMisinformation ReportReason = "MISINFORMATION"
+	ReportReasonCopyright      ReportReason = "COPYRIGHT"
+	ReportReasonOther          ReportReason = "OTHER"
+)
+
+type ReportResolution string
+
+const (
+	ReportDismissed   ReportResolution = "DISMISSED"
+	ReportUnpublished ReportResolution = "UNPUBLISHED"
+	ReportDeactivated ReportResolution = "DEACTIVATED"
+)
+
+// BlogReport is open until an editor resolves it, a user has one open report on a blog
+type BlogReport struct {
+	ID         uuid.UUID         // id
+	BlogID     uuid.UUID         // blog_id
+	ReporterID uuid.UUID         // reporter_id
+	Reason     ReportReason      // reason
+	Text       *string           // text
+	Resolution *ReportResolution // resolution
+	ResolvedBy *uuid.UUID        // resolved_by
+	ResolvedAt *time.Time        // resolved_at
+	CreatedAt  time.Time         // created_at
+	UpdatedAt  time.Time         // updated_at
+}
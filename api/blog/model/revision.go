@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const BlogRevisionsTableName = "blog_revisions"
+
+type RevisionKind string
+
+const (
+	// RevisionDraft is a save of the draft by its author
+	RevisionDraft RevisionKind = "DRAFT"
+	// RevisionPublication is the text published by an editor
+	RevisionPublication RevisionKind = "PUBLICATION"
+	// RevisionRestore is a draft restored from an earlier revision
+	RevisionRestore RevisionKind = "RESTORE"
+)
+
+// BlogRevision is a snapshot of a blog, the versions of a blog count up from 1
+type BlogRevision struct {
+	ID           uuid.UUID    // id
+	BlogID       uuid.UUID    // blog_id
+	Version      int          // version
+	Kind         RevisionKind // kind
+	Title        string       // title
+	Description  string       // description
+	Text         string       // text
+	Tags         []string     // tags
+	RestoredFrom *int         // restored_from
+	CreatedBy    *uuid.UUID   // created_by
+	CreatedAt    time.Time    // created_at
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/report/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var learnerUser = &userModel.User{ID: uuid.New(), Email: "learner@abc.com", Name: "learner"}
+
+func mockProviders() (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider) {
+	authProvider := new(network.MockAuthenticationProvider)
+	authProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, learnerUser)
+		ctx.Next()
+	}))
+	authorizeProvider := new(network.MockAuthorizationProvider)
+	authorizeProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+	return authProvider, authorizeProvider
+}
+
+func TestReportController_ReportBlog(t *testing.T) {
+	service := new(MockService)
+	blogId := uuid.New()
+	service.On("ReportBlog", blogId, mock.MatchedBy(func(d *dto.ReportCreate) bool {
+		return d.Reason == model.ReportReasonSpam && d.Text != nil && *d.Text == "buy now"
+	}), learnerUser).Return(nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	body := `{"reason":"SPAM","text":"buy now"}`
+	rr := network.MockTestController(t, "POST", "/blog/report/id/"+blogId.String(), body, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "blog reported successfully")
+	service.AssertExpectations(t)
+}
+
+func TestReportController_ReportBlogUnknownReason(t *testing.T) {
+	service := new(MockService)
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "POST", "/blog/report/id/"+uuid.NewString(), `{"reason":"BORING"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "ReportBlog", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReportController_ReportOwnBlog(t *testing.T) {
+	service := new(MockService)
+	blogId := uuid.New()
+	service.On("ReportBlog", blogId, mock.Anything, learnerUser).
+		Return(network.NewBadRequestError("you cannot report your own blog", nil))
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "POST", "/blog/report/id/"+blogId.String(), `{"reason":"OTHER"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "your own blog")
+}
@@ -0,0 +1,8 @@
+package dto
+
+import "github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+
+type ReportCreate struct {
+	Reason model.ReportReason `json:"reason" binding:"required" validate:"required,oneof=SPAM HARASSMENT HATE MISINFORMATION COPYRIGHT OTHER"`
+	Text   *string            `json:"text,omitempty" validate:"omitempty,max=2000"`
+}
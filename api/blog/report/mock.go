@@ -0,0 +1,17 @@
+package report
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/report/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) ReportBlog(blogId uuid.UUID, reportCreateDto *dto.ReportCreate, user *userModel.User) error {
+	args := m.Called(blogId, reportCreateDto, user)
+	return args.Error(0)
+}
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/report/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const defaultFlagThreshold = 3
+
+type Service interface {
+	ReportBlog(blogId uuid.UUID, reportCreateDto *dto.ReportCreate, user *userModel.User) error
+}
+
+type service struct {
+	db            postgres.Database
+	flagThreshold int64
+}
+
+func NewService(env *config.Env, db postgres.Database) Service {
+	threshold := int64(env.BlogFlagReportThreshold)
+	if threshold == 0 {
+		threshold = defaultFlagThreshold
+	}
+	return &service{
+		db:            db,
+		flagThreshold: threshold,
+	}
+}
+
+// ReportBlog keeps one open report of a user on a blog, reporting again replaces the reason.
+// The blog is flagged once its open reports reach the threshold, the blog row is locked so
+// that the concurrent reports are all counted.
+func (s *service) ReportBlog(blogId uuid.UUID, reportCreateDto *dto.ReportCreate, user *userModel.User) error {
+	if !user.Verified {
+		return network.NewForbiddenError("permission denied: verify your email to report", nil)
+	}
+
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	selectQuery := `
+		SELECT author_id, flagged
+		FROM blogs
+		WHERE id = $1
+		  AND status = TRUE
+		  AND published = TRUE
+		FOR UPDATE
+	`
+
+	var (
+		authorId uuid.UUID
+		flagged  bool
+	)
+
+	err = tx.QueryRow(ctx, selectQuery, blogId).Scan(&authorId, &flagged)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return network.NewNotFoundError("blog not found", nil)
+		}
+		return err
+	}
+
+	if authorId == user.ID {
+		return network.NewBadRequestError("you cannot report your own blog", nil)
+	}
+
+	insertQuery := `
+		INSERT INTO blog_reports (blog_id, reporter_id, reason, text)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (blog_id, reporter_id) WHERE resolution IS NULL
+		DO UPDATE SET
+			reason = EXCLUDED.reason,
+			text = EXCLUDED.text,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	_, err = tx.Exec(ctx, insertQuery, blogId, user.ID, reportCreateDto.Reason, reportCreateDto.Text)
+	if err != nil {
+		return err
+	}
+
+	if !flagged {
+		flagQuery := `
+			UPDATE blogs
+			SET flagged = TRUE
+			WHERE id = $1
+			  AND (
+				SELECT COUNT(*)
+				FROM blog_reports
+				WHERE blog_id = $1
+				  AND resolution IS NULL
+			  ) >= $2
+		`
+
+		_, err = tx.Exec(ctx, flagQuery, blogId, s.flagThreshold)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
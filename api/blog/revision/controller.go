@@ -0,0 +1,155 @@
+package revision
+
+import (
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/revision/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	coredto "github.com/afteracademy/goserve/v2/dto"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service    Service
+	permission userModel.PermissionCode
+	ownBlogs   bool
+}
+
+// NewAuthorController serves the revisions of the blogs of the author
+func NewAuthorController(
+	authMFunc network.AuthenticationProvider,
+	authorizeMFunc network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/blog/author/revisions", authMFunc, authorizeMFunc),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+		permission:     userModel.PermissionBlogWrite,
+		ownBlogs:       true,
+	}
+}
+
+// NewEditorController serves the revisions of every blog to the editors
+func NewEditorController(
+	authMFunc network.AuthenticationProvider,
+	authorizeMFunc network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/blog/editor/revisions", authMFunc, authorizeMFunc),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+		permission:     userModel.PermissionBlogPublish,
+		ownBlogs:       false,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(common.KeyPermission(authModel.AuthorPermission))
+	read := group.Group("",
+		common.TokenScope(authModel.ScopeBlogRead),
+		c.Authentication(),
+		c.Authorization(string(c.permission)),
+	)
+	write := group.Group("",
+		common.TokenScope(authModel.ScopeBlogWrite),
+		c.Authentication(),
+		c.Authorization(string(c.permission)),
+	)
+	read.GET("/id/:id", c.getRevisionsHandler)
+	read.GET("/id/:id/diff", c.diffRevisionsHandler)
+	read.GET("/id/:id/version/:version", c.getRevisionHandler)
+	write.PUT("/id/:id/version/:version/restore", c.restoreRevisionHandler)
+}
+
+// authorId limits the authors to their own blogs
+func (c *controller) authorId(ctx *gin.Context) *uuid.UUID {
+	if !c.ownBlogs {
+		return nil
+	}
+	user := c.MustGetUser(ctx)
+	return &user.ID
+}
+
+func (c *controller) getRevisionsHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	pagination, err := network.ReqQuery[coredto.Pagination](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	revisions, err := c.service.GetRevisions(uuidParam.ID, c.authorId(ctx), pagination)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", &revisions)
+}
+
+func (c *controller) getRevisionHandler(ctx *gin.Context) {
+	params, err := network.ReqParams[dto.RevisionParams](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	revision, err := c.service.GetRevision(params.ID, params.Version, c.authorId(ctx))
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", revision)
+}
+
+func (c *controller) diffRevisionsHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	query, err := network.ReqQuery[dto.RevisionDiffQuery](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	diff, err := c.service.DiffRevisions(uuidParam.ID, query.From, query.To, c.authorId(ctx))
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", diff)
+}
+
+func (c *controller) restoreRevisionHandler(ctx *gin.Context) {
+	params, err := network.ReqParams[dto.RevisionParams](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user := c.MustGetUser(ctx)
+
+	revision, err := c.service.RestoreRevision(params.ID, params.Version, c.authorId(ctx), user)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "revision restored successfully", revision)
+}
@@ -0,0 +1,107 @@
+package revision
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/revision/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	coredto "github.com/afteracademy/goserve/v2/dto"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var authorUser = &userModel.User{ID: uuid.New(), Email: "author@abc.com", Name: "author"}
+
+func mockProviders() (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider) {
+	authProvider := new(network.MockAuthenticationProvider)
+	authProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, authorUser)
+		ctx.Next()
+	}))
+	authorizeProvider := new(network.MockAuthorizationProvider)
+	authorizeProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+	return authProvider, authorizeProvider
+}
+
+func TestRevisionController_AuthorListsOwnBlog(t *testing.T) {
+	service := new(MockService)
+	blogId := uuid.New()
+	revisions := []*dto.RevisionInfo{{ID: uuid.New(), BlogID: blogId, Version: 2, Title: "second"}}
+	service.On("GetRevisions", blogId, &authorUser.ID, &coredto.Pagination{Page: 1, Limit: 10}).Return(revisions, nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewAuthorController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/blog/author/revisions/id/"+blogId.String()+"?page=1&limit=10", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"version":2`)
+	service.AssertExpectations(t)
+}
+
+func TestRevisionController_EditorDiffsAnyBlog(t *testing.T) {
+	service := new(MockService)
+	blogId := uuid.New()
+	diff := &dto.RevisionDiff{BlogID: blogId, From: 1, To: 3, Diff: "--- v1\n+++ v3\n"}
+	service.On("DiffRevisions", blogId, 1, 3, (*uuid.UUID)(nil)).Return(diff, nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewEditorController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/blog/editor/revisions/id/"+blogId.String()+"/diff?from=1&to=3", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `+++ v3`)
+	service.AssertExpectations(t)
+}
+
+func TestRevisionController_DiffNeedsBothVersions(t *testing.T) {
+	service := new(MockService)
+	authProvider, authorizeProvider := mockProviders()
+	c := NewAuthorController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/blog/author/revisions/id/"+uuid.NewString()+"/diff?from=1", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "DiffRevisions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRevisionController_Restore(t *testing.T) {
+	service := new(MockService)
+	blogId := uuid.New()
+	restoredFrom := 2
+	restored := &dto.RevisionInfo{
+		ID:           uuid.New(),
+		BlogID:       blogId,
+		Version:      5,
+		Kind:         model.RevisionRestore,
+		Title:        "restored",
+		RestoredFrom: &restoredFrom,
+		CreatedAt:    time.Now(),
+	}
+	service.On("RestoreRevision", blogId, 2, &authorUser.ID, authorUser).Return(restored, nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewAuthorController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "PUT", "/blog/author/revisions/id/"+blogId.String()+"/version/2/restore", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"restoredFrom":2`)
+	service.AssertExpectations(t)
+}
+
+func TestRevisionController_InvalidVersion(t *testing.T) {
+	service := new(MockService)
+	authProvider, authorizeProvider := mockProviders()
+	c := NewAuthorController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/blog/author/revisions/id/"+uuid.NewString()+"/version/0", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "GetRevision", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/afteracademy/goserve/v2/utility"
+	"github.com/google/uuid"
+)
+
+type Revision struct {
+	ID           uuid.UUID          `json:"id" validate:"required"`
+	BlogID       uuid.UUID          `json:"blogId" validate:"required"`
+	Version      int                `json:"version" validate:"required"`
+	Kind         model.RevisionKind `json:"kind" validate:"required"`
+	Title        string             `json:"title" validate:"required"`
+	Description  string             `json:"description" validate:"required"`
+	Text         string             `json:"text"`
+	Tags         []string           `json:"tags"`
+	RestoredFrom *int               `json:"restoredFrom,omitempty"`
+	CreatedBy    *uuid.UUID         `json:"createdBy,omitempty"`
+	CreatedAt    time.Time          `json:"createdAt" validate:"required"`
+}
+
+func NewRevision(revision *model.BlogRevision) (*Revision, error) {
+	return utility.MapTo[Revision](revision)
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "github.com/google/uuid"
+
+// RevisionDiff is the unified diff of the text of two revisions
+type RevisionDiff struct {
+	BlogID uuid.UUID `json:"blogId"`
+	From   int       `json:"from"`
+	To     int       `json:"to"`
+	Diff   string    `json:"diff"`
+}
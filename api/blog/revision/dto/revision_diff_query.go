@@ -0,0 +1,7 @@
+package dto
+
+// RevisionDiffQuery picks the two versions to compare, in either order
+type RevisionDiffQuery struct {
+	From int `form:"from" binding:"required" validate:"required,min=1"`
+	To   int `form:"to" binding:"required" validate:"required,min=1"`
+}
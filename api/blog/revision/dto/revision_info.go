@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/afteracademy/goserve/v2/utility"
+	"github.com/google/uuid"
+)
+
+type RevisionInfo struct {
+	ID           uuid.UUID          `json:"id" validate:"required"`
+	BlogID       uuid.UUID          `json:"blogId" validate:"required"`
+	Version      int                `json:"version" validate:"required"`
+	Kind         model.RevisionKind `json:"kind" validate:"required"`
+	Title        string             `json:"title" validate:"required"`
+	RestoredFrom *int               `json:"restoredFrom,omitempty"`
+	CreatedBy    *uuid.UUID         `json:"createdBy,omitempty"`
+	CreatedAt    time.Time          `json:"createdAt" validate:"required"`
+}
+
+func NewRevisionInfo(revision *model.BlogRevision) (*RevisionInfo, error) {
+	return utility.MapTo[RevisionInfo](revision)
+}
@@ -0,0 +1,18 @@
+package dto
+
+import "github.com/google/uuid"
+
+// RevisionParams is the path of a revision of a blog
+type RevisionParams struct {
+	Id      string    `uri:"id" binding:"required" validate:"required,uuid"`
+	Version int       `uri:"version" binding:"required" validate:"required,min=1"`
+	ID      uuid.UUID `uri:"-" validate:"-"`
+}
+
+func (d *RevisionParams) GetValue() *RevisionParams {
+	id, err := uuid.Parse(d.Id)
+	if err == nil {
+		d.ID = id
+	}
+	return d
+}
@@ -0,0 +1,47 @@
+package revision
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/revision/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	coredto "github.com/afteracademy/goserve/v2/dto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) GetRevisions(blogId uuid.UUID, authorId *uuid.UUID, p *coredto.Pagination) ([]*dto.RevisionInfo, error) {
+	args := m.Called(blogId, authorId, p)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.RevisionInfo), args.Error(1)
+}
+
+func (m *MockService) GetRevision(blogId uuid.UUID, version int, authorId *uuid.UUID) (*dto.Revision, error) {
+	args := m.Called(blogId, version, authorId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.Revision), args.Error(1)
+}
+
+func (m *MockService) DiffRevisions(blogId uuid.UUID, from int, to int, authorId *uuid.UUID) (*dto.RevisionDiff, error) {
+	args := m.Called(blogId, from, to, authorId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RevisionDiff), args.Error(1)
+}
+
+func (m *MockService) RestoreRevision(
+	blogId uuid.UUID, version int, authorId *uuid.UUID, user *userModel.User,
+) (*dto.RevisionInfo, error) {
+	args := m.Called(blogId, version, authorId, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RevisionInfo), args.Error(1)
+}
@@ -0,0 +1,350 @@
+package revision
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/revision/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	coredto "github.com/afteracademy/goserve/v2/dto"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const diffContextLines = 3
+
+// Service reads the revisions of the blogs and restores them into the draft. The authorId
+// limits the blogs to the ones of an author, the editors pass nil to reach every blog.
+type Service interface {
+	GetRevisions(blogId uuid.UUID, authorId *uuid.UUID, p *coredto.Pagination) ([]*dto.RevisionInfo, error)
+	GetRevision(blogId uuid.UUID, version int, authorId *uuid.UUID) (*dto.Revision, error)
+	DiffRevisions(blogId uuid.UUID, from int, to int, authorId *uuid.UUID) (*dto.RevisionDiff, error)
+	RestoreRevision(blogId uuid.UUID, version int, authorId *uuid.UUID, user *userModel.User) (*dto.RevisionInfo, error)
+}
+
+type service struct {
+	db postgres.Database
+}
+
+func NewService(db postgres.Database) Service {
+	return &service{
+		db: db,
+	}
+}
+
+// Capture adds the current state of the blog as its next revision and returns its version,
+// the publication takes the published text and the other kinds the draft. It is called in
+// the transaction that updated the blog so that the lock on the blog row orders the versions.
+func Capture(
+	ctx context.Context,
+	tx pgx.Tx,
+	blogId uuid.UUID,
+	kind model.RevisionKind,
+	createdBy uuid.UUID,
+	restoredFrom *int,
+) (int, error) {
+	text := "b.draft_text"
+	if kind == model.RevisionPublication {
+		text = "b.text"
+	}
+
+	query := `
+		INSERT INTO blog_revisions (
+			blog_id,
+			version,
+			kind,
+			title,
+			description,
+			text,
+			tags,
+			restored_from,
+			created_by
+		)
+		SELECT
+			b.id,
+			COALESCE((SELECT MAX(r.version) FROM blog_revisions r WHERE r.blog_id = b.id), 0) + 1,
+			$2,
+			b.title,
+			b.description,
+			COALESCE(` + text + `, ''),
+			b.tags,
+			$3,
+			$4
+		FROM blogs b
+		WHERE b.id = $1
+		RETURNING version
+	`
+
+	var version int
+	err := tx.QueryRow(ctx, query, blogId, kind, restoredFrom, createdBy).Scan(&version)
+	return version, err
+}
+
+func (s *service) GetRevisions(blogId uuid.UUID, authorId *uuid.UUID, p *coredto.Pagination) ([]*dto.RevisionInfo, error) {
+	ctx := context.Background()
+
+	err := findBlog(ctx, s.db.Pool(), blogId, authorId)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (p.Page - 1) * p.Limit
+
+	query := `
+		SELECT
+			id,
+			blog_id,
+			version,
+			kind,
+			title,
+			restored_from,
+			created_by,
+			created_at
+		FROM blog_revisions
+		WHERE blog_id = $1
+		ORDER BY version DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := s.db.Pool().Query(ctx, query, blogId, p.Limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos := make([]*dto.RevisionInfo, 0)
+
+	for rows.Next() {
+		var r model.BlogRevision
+		if err := rows.Scan(
+			&r.ID,
+			&r.BlogID,
+			&r.Version,
+			&r.Kind,
+			&r.Title,
+			&r.RestoredFrom,
+			&r.CreatedBy,
+			&r.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		d, err := dto.NewRevisionInfo(&r)
+		if err != nil {
+			return nil, err
+		}
+
+		dtos = append(dtos, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dtos, nil
+}
+
+func (s *service) GetRevision(blogId uuid.UUID, version int, authorId *uuid.UUID) (*dto.Revision, error) {
+	ctx := context.Background()
+
+	err := findBlog(ctx, s.db.Pool(), blogId, authorId)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := findRevision(ctx, s.db.Pool(), blogId, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewRevision(revision)
+}
+
+func (s *service) DiffRevisions(blogId uuid.UUID, from int, to int, authorId *uuid.UUID) (*dto.RevisionDiff, error) {
+	ctx := context.Background()
+
+	err := findBlog(ctx, s.db.Pool(), blogId, authorId)
+	if err != nil {
+		return nil, err
+	}
+
+	fromRevision, err := findRevision(ctx, s.db.Pool(), blogId, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toRevision, err := findRevision(ctx, s.db.Pool(), blogId, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := UnifiedDiff(fromRevision, toRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RevisionDiff{
+		BlogID: blogId,
+		From:   from,
+		To:     to,
+		Diff:   diff,
+	}, nil
+}
+
+// RestoreRevision copies a revision into the draft as a new revision, the published text
+// and the submission of the blog are left as they are
+func (s *service) RestoreRevision(
+	blogId uuid.UUID, version int, authorId *uuid.UUID, user *userModel.User,
+) (*dto.RevisionInfo, error) {
+	ctx := context.Background()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = findBlog(ctx, tx, blogId, authorId)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := findRevision(ctx, tx, blogId, version)
+	if err != nil {
+		return nil, err
+	}
+
+	updateQuery := `
+		UPDATE blogs
+		SET
+			title = $2,
+			description = $3,
+			draft_text = $4,
+			tags = $5,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		  AND status = TRUE
+		  AND ($6::uuid IS NULL OR author_id = $6)
+	`
+
+	tag, err := tx.Exec(
+		ctx,
+		updateQuery,
+		blogId,
+		revision.Title,
+		revision.Description,
+		revision.Text,
+		revision.Tags,
+		authorId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return nil, network.NewNotFoundError("blog not found", nil)
+	}
+
+	restoredVersion, err := Capture(ctx, tx, blogId, model.RevisionRestore, user.ID, &version)
+	if err != nil {
+		return nil, err
+	}
+
+	restored, err := findRevision(ctx, tx, blogId, restoredVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return dto.NewRevisionInfo(restored)
+}
+
+// UnifiedDiff compares the text of two revisions line by line
+func UnifiedDiff(from *model.BlogRevision, to *model.BlogRevision) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from.Text),
+		B:        difflib.SplitLines(to.Text),
+		FromFile: "v" + strconv.Itoa(from.Version),
+		ToFile:   "v" + strconv.Itoa(to.Version),
+		Context:  diffContextLines,
+	})
+}
+
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func findBlog(ctx context.Context, q querier, blogId uuid.UUID, authorId *uuid.UUID) error {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM blogs
+			WHERE id = $1
+			  AND status = TRUE
+			  AND ($2::uuid IS NULL OR author_id = $2)
+		)
+	`
+
+	var exists bool
+	err := q.QueryRow(ctx, query, blogId, authorId).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return network.NewNotFoundError("blog not found", nil)
+	}
+	return nil
+}
+
+func findRevision(ctx context.Context, q querier, blogId uuid.UUID, version int) (*model.BlogRevision, error) {
+	query := `
+		SELECT
+			id,
+			blog_id,
+			version,
+			kind,
+			title,
+			description,
+			text,
+			tags,
+			restored_from,
+			created_by,
+			created_at
+		FROM blog_revisions
+		WHERE blog_id = $1
+		  AND version = $2
+	`
+
+	var r model.BlogRevision
+
+	err := q.QueryRow(ctx, query, blogId, version).Scan(
+		&r.ID,
+		&r.BlogID,
+		&r.Version,
+		&r.Kind,
+		&r.Title,
+		&r.Description,
+		&r.Text,
+		&r.Tags,
+		&r.RestoredFrom,
+		&r.CreatedBy,
+		&r.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, network.NewNotFoundError("revision "+strconv.Itoa(version)+" not found", nil)
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
@@ -0,0 +1,30 @@
+package revision
+
+import (
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	from := &model.BlogRevision{Version: 1, Text: "intro\nfirst point\noutro\n"}
+	to := &model.BlogRevision{Version: 3, Text: "intro\nsecond point\noutro\n"}
+
+	diff, err := UnifiedDiff(from, to)
+	assert.NoError(t, err)
+	assert.Contains(t, diff, "--- v1\n")
+	assert.Contains(t, diff, "+++ v3\n")
+	assert.Contains(t, diff, "-first point\n")
+	assert.Contains(t, diff, "+second point\n")
+	assert.Contains(t, diff, " intro\n")
+}
+
+func TestUnifiedDiff_SameText(t *testing.T) {
+	from := &model.BlogRevision{Version: 2, Text: "unchanged\n"}
+	to := &model.BlogRevision{Version: 4, Text: "unchanged\n"}
+
+	diff, err := UnifiedDiff(from, to)
+	assert.NoError(t, err)
+	assert.Empty(t, diff)
+}
@@ -23,6 +23,7 @@ type Service interface {
 	BlogSlugExists(slug string) bool
 	GetPublisedBlogById(id uuid.UUID) (*dto.BlogPublic, error)
 	GetPublishedBlogBySlug(slug string) (*dto.BlogPublic, error)
+	IsBlogLikedByUser(blogId uuid.UUID, userId uuid.UUID) (bool, error)
 }
 
 type service struct {
@@ -92,6 +93,9 @@ func (s *service) GetPublisedBlogById(blogID uuid.UUID) (*dto.BlogPublic, error)
 			img_url,
 			score,
 			tags,
+			views,
+			likes,
+			comments,
 			published_at
 		FROM blogs
 		WHERE id = $1
@@ -115,6 +119,9 @@ func (s *service) GetPublisedBlogById(blogID uuid.UUID) (*dto.BlogPublic, error)
 		&b.ImgURL,
 		&b.Score,
 		&b.Tags,
+		&b.Views,
+		&b.Likes,
+		&b.Comments,
 		&b.PublishedAt,
 	)
 
@@ -147,6 +154,9 @@ func (s *service) GetPublishedBlogBySlug(slug string) (*dto.BlogPublic, error) {
 			img_url,
 			score,
 			tags,
+			views,
+			likes,
+			comments,
 			published_at
 		FROM blogs
 		WHERE slug = $1
@@ -170,6 +180,9 @@ func (s *service) GetPublishedBlogBySlug(slug string) (*dto.BlogPublic, error) {
 		&b.ImgURL,
 		&b.Score,
 		&b.Tags,
+		&b.Views,
+		&b.Likes,
+		&b.Comments,
 		&b.PublishedAt,
 	)
 
@@ -187,3 +200,18 @@ func (s *service) GetPublishedBlogBySlug(slug string) (*dto.BlogPublic, error) {
 
 	return dto.NewBlogPublic(&b, author)
 }
+
+func (s *service) IsBlogLikedByUser(blogId uuid.UUID, userId uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM blog_reactions
+			WHERE blog_id = $1
+			  AND user_id = $2
+		)
+	`
+
+	var liked bool
+	err := s.db.Pool().QueryRow(context.Background(), query, blogId, userId).Scan(&liked)
+	return liked, err
+}
@@ -0,0 +1,24 @@
+package view
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) RecordView(blogId uuid.UUID, viewer string) error {
+	args := m.Called(blogId, viewer)
+	return args.Error(0)
+}
+
+func (m *MockService) Flush() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockService) Close() {
+	m.Called()
+}
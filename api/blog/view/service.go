@@ -0,0 +1,320 @@
+package view
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/afteracademy/goserve/v2/redis"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	pendingViewsKey     = "blog_views_pending"
+	flushingViewsPrefix = "blog_views_flushing_"
+	flushingViewsTTL    = time.Hour
+	// a flushing key this old is left by a flusher that crashed and not one still running
+	staleFlushingAge     = 5 * time.Minute
+	defaultWindow        = 30 * time.Minute
+	defaultFlushInterval = 30 * time.Second
+)
+
+// recordViewScript counts a viewer once per blog in the window, the viewer is only kept
+// along with the counted view
+var recordViewScript = goredis.NewScript(`
+if not redis.call('SET', KEYS[1], 1, 'NX', 'PX', ARGV[1]) then
+	return 0
+end
+redis.call('HINCRBY', KEYS[2], ARGV[2], 1)
+return 1
+`)
+
+// takePendingScript moves the pending views to the flushing key, the rename of a missing
+// key is an error so the check and the rename run as one step
+var takePendingScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('RENAME', KEYS[1], KEYS[2])
+redis.call('EXPIRE', KEYS[2], ARGV[1])
+return 1
+`)
+
+// restoreViewsScript adds the views of a flushing key back to the pending views and removes it
+var restoreViewsScript = goredis.NewScript(`
+local counts = redis.call('HGETALL', KEYS[1])
+for i = 1, #counts, 2 do
+	redis.call('HINCRBY', KEYS[2], counts[i], counts[i + 1])
+end
+redis.call('DEL', KEYS[1])
+return #counts / 2
+`)
+
+// Service counts the views of the blogs in redis and a single flusher adds the counted
+// views to the blogs in batches. A viewer is counted once per blog within the window.
+type Service interface {
+	RecordView(blogId uuid.UUID, viewer string) error
+	Flush() error
+	Close()
+}
+
+type service struct {
+	db       postgres.Database
+	store    redis.Store
+	window   time.Duration
+	interval time.Duration
+	flush    func() error
+	stop     chan struct{}
+	done     chan struct{}
+	closing  sync.Once
+}
+
+func NewService(env *config.Env, db postgres.Database, store redis.Store) Service {
+	s := &service{
+		db:       db,
+		store:    store,
+		window:   time.Duration(env.BlogViewWindowSec) * time.Second,
+		interval: time.Duration(env.BlogViewFlushSec) * time.Second,
+	}
+	if s.window == 0 {
+		s.window = defaultWindow
+	}
+	if s.interval == 0 {
+		s.interval = defaultFlushInterval
+	}
+	s.flush = s.Flush
+	s.start()
+	return s
+}
+
+// Viewer identifies the signed in user by the id and an anonymous viewer by the ip and
+// the user agent, the anonymous identity is hashed so that the ips are not kept in redis
+func Viewer(userId *uuid.UUID, ip string, userAgent string) string {
+	if userId != nil {
+		return "user_" + userId.String()
+	}
+	sum := sha256.Sum256([]byte(ip + "|" + userAgent))
+	return "anon_" + hex.EncodeToString(sum[:16])
+}
+
+func viewerKey(blogId uuid.UUID, viewer string) string {
+	return "blog_view_" + blogId.String() + "_" + viewer
+}
+
+func (s *service) start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run()
+}
+
+func (s *service) RecordView(blogId uuid.UUID, viewer string) error {
+	ctx := context.Background()
+	client := s.store.GetInstance()
+
+	return recordViewScript.Run(
+		ctx, client, []string{viewerKey(blogId, viewer), pendingViewsKey}, s.window.Milliseconds(), blogId.String(),
+	).Err()
+}
+
+// Flush moves the pending views to a key of its own so that the views recorded meanwhile
+// wait for the next flush, the views go back to the pending views when the update fails.
+// The views of the flushing keys left by a crashed flusher are taken back first.
+func (s *service) Flush() error {
+	ctx := context.Background()
+	client := s.store.GetInstance()
+
+	err := s.restoreStaleFlushing(ctx)
+	if err != nil {
+		return err
+	}
+
+	flushId := uuid.New()
+	flushingKey := flushingViewsPrefix + flushId.String()
+	taken, err := takePendingScript.Run(
+		ctx, client, []string{pendingViewsKey, flushingKey}, int64(flushingViewsTTL/time.Second),
+	).Int()
+	// nothing pending or another instance took the pending views
+	if err != nil || taken == 0 {
+		return err
+	}
+
+	counts, err := client.HGetAll(ctx, flushingKey).Result()
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uuid.UUID, 0, len(counts))
+	views := make([]int64, 0, len(counts))
+	for field, value := range counts {
+		id, err := uuid.Parse(field)
+		if err != nil {
+			continue
+		}
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || count <= 0 {
+			continue
+		}
+		ids = append(ids, id)
+		views = append(views, count)
+	}
+
+	applied, err := s.addViews(ctx, flushId, ids, views)
+	if err != nil {
+		if rerr := restoreViewsScript.Run(ctx, client, []string{flushingKey, pendingViewsKey}).Err(); rerr != nil {
+			log.Printf("%d blog view counts could not be restored: %v", len(ids), rerr)
+		}
+		return err
+	}
+
+	// a flush that took too long was claimed and its views restored by another instance
+	if !applied {
+		return nil
+	}
+
+	return client.Del(ctx, flushingKey).Err()
+}
+
+// restoreStaleFlushing merges the flushing keys of the flushers that did not finish back into
+// the pending views, the keys of the flushes still running elsewhere are younger and left alone
+func (s *service) restoreStaleFlushing(ctx context.Context) error {
+	client := s.store.GetInstance()
+
+	iter := client.Scan(ctx, 0, flushingViewsPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+
+		flushId, err := uuid.Parse(strings.TrimPrefix(key, flushingViewsPrefix))
+		if err != nil {
+			continue
+		}
+
+		ttl, err := client.TTL(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if ttl > flushingViewsTTL-staleFlushingAge {
+			continue
+		}
+
+		// the flush that took the key is still running when this waits on its claim
+		claimed, err := s.claimFlush(ctx, s.db.Pool(), flushId)
+		if err != nil {
+			return err
+		}
+
+		// the views of the key were already added, only the delete of the key failed
+		if !claimed {
+			if err := client.Del(ctx, key).Err(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		restored, err := restoreViewsScript.Run(ctx, client, []string{key, pendingViewsKey}).Int()
+		if err != nil {
+			return err
+		}
+		if restored > 0 {
+			log.Printf("%d blog view counts restored from %s", restored, key)
+		}
+	}
+
+	return iter.Err()
+}
+
+// Close stops the flusher and returns once the pending views are flushed
+func (s *service) Close() {
+	s.closing.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+}
+
+func (s *service) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	flush := func() {
+		if err := s.flush(); err != nil {
+			log.Printf("blog views could not be flushed: %v", err)
+		}
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			flush()
+		case <-s.stop:
+			flush()
+			return
+		}
+	}
+}
+
+// addViews adds the views of a flush once, it returns false when the flush was already
+// claimed by its own earlier attempt or by the restore of a stale flushing key
+func (s *service) addViews(ctx context.Context, flushId uuid.UUID, ids []uuid.UUID, views []int64) (bool, error) {
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	claimed, err := s.claimFlush(ctx, tx, flushId)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	if len(ids) > 0 {
+		query := `
+			UPDATE blogs AS b
+			SET views = b.views + v.views
+			FROM UNNEST($1::uuid[], $2::bigint[]) AS v(id, views)
+			WHERE b.id = v.id
+		`
+
+		_, err = tx.Exec(ctx, query, ids, views)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	// a flushing key expires with its ttl, so its flush can't be seen again after that
+	_, err = tx.Exec(ctx, `DELETE FROM blog_view_flushes WHERE created_at < $1`, time.Now().Add(-2*flushingViewsTTL))
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// claimFlush records the flushing key, the views of a key are applied or restored only by
+// whoever records it first. A concurrent claim waits for the first one to commit or roll back.
+func (s *service) claimFlush(ctx context.Context, db execer, flushId uuid.UUID) (bool, error) {
+	query := `
+		INSERT INTO blog_view_flushes (id)
+		VALUES ($1)
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	tag, err := db.Exec(ctx, query, flushId)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,51 @@
+package view
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestService(interval time.Duration, flush func() error) *service {
+	s := &service{interval: interval, flush: flush}
+	s.start()
+	return s
+}
+
+func TestViewer(t *testing.T) {
+	userId := uuid.New()
+	assert.Equal(t, "user_"+userId.String(), Viewer(&userId, "10.0.0.1", "firefox"))
+
+	anon := Viewer(nil, "10.0.0.1", "firefox")
+	assert.Equal(t, anon, Viewer(nil, "10.0.0.1", "firefox"))
+	assert.NotEqual(t, anon, Viewer(nil, "10.0.0.2", "firefox"))
+	assert.NotEqual(t, anon, Viewer(nil, "10.0.0.1", "chrome"))
+	assert.NotContains(t, anon, "10.0.0.1")
+}
+
+func TestViewService_FlushesPeriodically(t *testing.T) {
+	var calls atomic.Int32
+	s := newTestService(10*time.Millisecond, func() error {
+		calls.Add(1)
+		return nil
+	})
+	defer s.Close()
+
+	assert.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, 5*time.Millisecond)
+}
+
+func TestViewService_CloseFlushesOnce(t *testing.T) {
+	var calls atomic.Int32
+	s := newTestService(time.Hour, func() error {
+		calls.Add(1)
+		return errors.New("database is down")
+	})
+	s.Close()
+	s.Close()
+
+	assert.Equal(t, int32(1), calls.Load())
+}
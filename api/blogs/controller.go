@@ -1,7 +1,9 @@
 package blogs
 
 import (
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/blogs/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
 	coredto "github.com/afteracademy/goserve/v2/dto"
 	"github.com/afteracademy/goserve/v2/network"
 	"github.com/gin-gonic/gin"
@@ -24,6 +26,7 @@ func NewController(
 }
 
 func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(common.KeyPermission(authModel.ReadPermission))
 	group.GET("/latest", c.getLatestBlogsHandler)
 	group.GET("/tag/:tag", c.getTaggedBlogsHandler)
 	group.GET("/similar/id/:id", c.getSimilarBlogsHandler)
@@ -3,6 +3,7 @@ package blogs
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
@@ -40,9 +41,48 @@ func (s *service) SetSimilarBlogsDtoCache(blogId uuid.UUID, blogs []*dto.BlogIte
 	return s.itemBlogCache.SetJSONList(key, blogs, 6*time.Hour)
 }
 
+// GetSimilarBlogsDtoCache drops the blogs flagged after the list was cached,
+// a flagged blog is hidden from the listings until an editor resolves its reports
 func (s *service) GetSimilarBlogsDtoCache(blogId uuid.UUID) ([]*dto.BlogItem, error) {
 	key := "similar_blogs_" + blogId.String()
-	return s.itemBlogCache.GetJSONList(key)
+	blogs, err := s.itemBlogCache.GetJSONList(key)
+	if err != nil || len(blogs) == 0 {
+		return blogs, err
+	}
+
+	ids := make([]uuid.UUID, len(blogs))
+	for i, b := range blogs {
+		ids[i] = b.ID
+	}
+
+	query := `
+		SELECT id
+		FROM blogs
+		WHERE flagged = TRUE
+		  AND id = ANY($1)
+	`
+
+	rows, err := s.db.Pool().Query(context.Background(), query, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	flagged, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(flagged) == 0 {
+		return blogs, nil
+	}
+
+	visible := make([]*dto.BlogItem, 0, len(blogs))
+	for _, b := range blogs {
+		if !slices.Contains(flagged, b.ID) {
+			visible = append(visible, b)
+		}
+	}
+	return visible, nil
 }
 
 func (s *service) GetPaginatedLatestBlogs(p *coredto.Pagination) ([]*dto.BlogItem, error) {
@@ -59,6 +99,7 @@ func (s *service) GetPaginatedLatestBlogs(p *coredto.Pagination) ([]*dto.BlogIte
 		FROM blogs
 		WHERE status = TRUE
 		  AND published = TRUE
+		  AND flagged = FALSE
 		ORDER BY published_at DESC, score DESC
 		LIMIT $1 OFFSET $2
 	`
@@ -79,6 +120,7 @@ func (s *service) GetPaginatedTaggedBlogs(tag string, p *coredto.Pagination) ([]
 		FROM blogs
 		WHERE status = TRUE
 		  AND published = TRUE
+		  AND flagged = FALSE
 			AND $1 = ANY(tags)
 		ORDER BY published_at DESC, score DESC
 		LIMIT $2 OFFSET $3
@@ -168,6 +210,7 @@ func (s *service) GetSimilarBlogs(
 		WHERE to_tsvector('english', title) @@ plainto_tsquery('english', $1)
 		  AND published = TRUE
 		  AND status = TRUE
+		  AND flagged = FALSE
 		  AND id <> $2
 		ORDER BY
 			similarity DESC,
@@ -233,6 +276,7 @@ func (s *service) GetPublicPaginated(p *coredto.Pagination) ([]*dto.BlogItem, er
 		FROM blogs
 		WHERE status = TRUE
 		  AND submitted = TRUE
+		  AND flagged = FALSE
 		ORDER BY published_at DESC, score DESC
 		LIMIT $1 OFFSET $2
 	`
@@ -1,7 +1,9 @@
 package contact
 
 import (
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/contact/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
 	"github.com/afteracademy/goserve/v2/network"
 	"github.com/afteracademy/goserve/v2/utility"
 	"github.com/gin-gonic/gin"
@@ -24,6 +26,7 @@ func NewController(
 }
 
 func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(common.KeyPermission(authModel.GeneralPermission))
 	group.POST("/", c.createMessageHandler)
 }
 
@@ -0,0 +1,178 @@
+package admin
+
+import (
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/admin/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	coredto "github.com/afteracademy/goserve/v2/dto"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type controller struct {
+	network.Controller
+	common.ContextPayload
+	service Service
+}
+
+func NewController(
+	authProvider network.AuthenticationProvider,
+	authorizeProvider network.AuthorizationProvider,
+	service Service,
+) network.Controller {
+	return &controller{
+		Controller:     network.NewController("/admin/users", authProvider, authorizeProvider),
+		ContextPayload: common.NewContextPayload(),
+		service:        service,
+	}
+}
+
+func (c *controller) MountRoutes(group *gin.RouterGroup) {
+	group.Use(
+		common.KeyPermission(authModel.AdminPermission),
+		c.Authentication(),
+		common.RejectImpersonation(),
+		c.Authorization(string(userModel.PermissionUserManage)),
+	)
+	group.GET("", c.searchUsersHandler)
+	group.GET("/roles", c.getRolesHandler)
+	group.GET("/id/:id", c.getUserHandler)
+	group.POST("/id/:id/roles", c.grantRoleHandler)
+	group.DELETE("/id/:id/roles/:role", c.revokeRoleHandler)
+	group.PUT("/id/:id/disable", c.disableUserHandler)
+	group.PUT("/id/:id/enable", c.enableUserHandler)
+	group.DELETE("/id/:id/sessions", c.signOutUserHandler)
+}
+
+func (c *controller) searchUsersHandler(ctx *gin.Context) {
+	search, err := network.ReqQuery[dto.UserSearch](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	users, err := c.service.SearchUsers(search)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", &users)
+}
+
+func (c *controller) getRolesHandler(ctx *gin.Context) {
+	roles, err := c.service.GetRoles()
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", &roles)
+}
+
+func (c *controller) getUserHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	user, err := c.service.GetUser(uuidParam.ID)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", user)
+}
+
+func (c *controller) grantRoleHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	body, err := network.ReqBody[dto.RoleGrant](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	err = c.service.GrantRole(uuidParam.ID, body.Role)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "role granted successfully")
+}
+
+func (c *controller) revokeRoleHandler(ctx *gin.Context) {
+	param, err := network.ReqParams[dto.UserRole](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	admin := c.MustGetUser(ctx)
+
+	err = c.service.RevokeRole(admin, param.ID, param.Role)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "role revoked successfully")
+}
+
+func (c *controller) disableUserHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	admin := c.MustGetUser(ctx)
+
+	err = c.service.DisableUser(admin, uuidParam.ID)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "user disabled successfully")
+}
+
+func (c *controller) enableUserHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	err = c.service.EnableUser(uuidParam.ID)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "user enabled successfully")
+}
+
+func (c *controller) signOutUserHandler(ctx *gin.Context) {
+	uuidParam, err := network.ReqParams[coredto.UUID](ctx)
+	if err != nil {
+		network.SendBadRequestError(ctx, err.Error(), err)
+		return
+	}
+
+	err = c.service.SignOutUser(uuidParam.ID)
+	if err != nil {
+		network.SendMixedError(ctx, err)
+		return
+	}
+
+	network.SendSuccessMsgResponse(ctx, "user signed out from all sessions")
+}
@@ -0,0 +1,126 @@
+package admin
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/admin/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var adminUser = &userModel.User{ID: uuid.New(), Email: "admin@abc.com", Name: "admin"}
+
+func mockProviders() (*network.MockAuthenticationProvider, *network.MockAuthorizationProvider) {
+	authProvider := new(network.MockAuthenticationProvider)
+	authProvider.On("Middleware").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		common.NewContextPayload().SetUser(ctx, adminUser)
+		ctx.Next()
+	}))
+	authorizeProvider := new(network.MockAuthorizationProvider)
+	authorizeProvider.On("Middleware", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+	return authProvider, authorizeProvider
+}
+
+func TestAdminUserController_SearchBadRequest(t *testing.T) {
+	service := new(MockService)
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/admin/users?page=1&limit=10&role=author", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "SearchUsers", mock.Anything)
+}
+
+func TestAdminUserController_SearchSuccess(t *testing.T) {
+	service := new(MockService)
+	search := &dto.UserSearch{Page: 1, Limit: 10, Query: "ali", Role: userModel.RoleCodeAuthor}
+	service.On("SearchUsers", search).Return([]*dto.UserInfo{
+		dto.NewUserInfo(&userModel.User{
+			ID:        uuid.New(),
+			Email:     "alice@abc.com",
+			Name:      "alice",
+			Status:    false,
+			CreatedAt: time.Now(),
+		}, []userModel.RoleCode{userModel.RoleCodeAuthor}),
+	}, nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "GET", "/admin/users?page=1&limit=10&q=ali&role=AUTHOR", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"roles":["AUTHOR"]`)
+	assert.Contains(t, rr.Body.String(), `"status":false`)
+	service.AssertExpectations(t)
+}
+
+func TestAdminUserController_GrantRole(t *testing.T) {
+	service := new(MockService)
+	id := uuid.New()
+	service.On("GrantRole", id, userModel.RoleCodeAuthor).Return(nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "POST", "/admin/users/id/"+id.String()+"/roles", `{"role":"AUTHOR"}`, common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"role granted successfully"`)
+	service.AssertExpectations(t)
+}
+
+func TestAdminUserController_RevokeRoleInvalidId(t *testing.T) {
+	service := new(MockService)
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "DELETE", "/admin/users/id/abc/roles/AUTHOR", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	service.AssertNotCalled(t, "RevokeRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAdminUserController_RevokeRole(t *testing.T) {
+	service := new(MockService)
+	id := uuid.New()
+	service.On("RevokeRole", adminUser, id, userModel.RoleCodeEditor).Return(nil)
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "DELETE", "/admin/users/id/"+id.String()+"/roles/EDITOR", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	service.AssertExpectations(t)
+}
+
+func TestAdminUserController_DisableSelf(t *testing.T) {
+	service := new(MockService)
+	service.On("DisableUser", adminUser, adminUser.ID).
+		Return(network.NewBadRequestError("you can not disable yourself", nil))
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "PUT", "/admin/users/id/"+adminUser.ID.String()+"/disable", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"you can not disable yourself"`)
+}
+
+func TestAdminUserController_SignOutNotFound(t *testing.T) {
+	service := new(MockService)
+	id := uuid.New()
+	service.On("SignOutUser", id).Return(network.NewNotFoundError("user not found", nil))
+
+	authProvider, authorizeProvider := mockProviders()
+	c := NewController(authProvider, authorizeProvider, service)
+
+	rr := network.MockTestController(t, "DELETE", "/admin/users/id/"+id.String()+"/sessions", "", common.MockApiKey(c))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
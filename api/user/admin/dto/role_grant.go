@@ -0,0 +1,7 @@
+package dto
+
+import "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+
+type RoleGrant struct {
+	Role model.RoleCode `json:"role" validate:"required,uppercase"`
+}
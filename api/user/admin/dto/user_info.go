@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/google/uuid"
+)
+
+// UserInfo is the view of a user for the admins, it includes the disabled users
+type UserInfo struct {
+	ID            uuid.UUID        `json:"id" validate:"required"`
+	Email         string           `json:"email" validate:"required,email"`
+	Name          string           `json:"name" validate:"required"`
+	ProfilePicURL *string          `json:"profilePicUrl,omitempty" validate:"omitempty,url"`
+	Roles         []model.RoleCode `json:"roles" validate:"required"`
+	Verified      bool             `json:"verified"`
+	MfaEnabled    bool             `json:"mfaEnabled"`
+	Status        bool             `json:"status"`
+	CreatedAt     time.Time        `json:"createdAt" validate:"required"`
+}
+
+func NewUserInfo(user *model.User, roles []model.RoleCode) *UserInfo {
+	return &UserInfo{
+		ID:            user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		ProfilePicURL: user.ProfilePicURL,
+		Roles:         roles,
+		Verified:      user.Verified,
+		MfaEnabled:    user.MfaEnabled,
+		Status:        user.Status,
+		CreatedAt:     user.CreatedAt,
+	}
+}
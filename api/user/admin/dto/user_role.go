@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/google/uuid"
+)
+
+func EmptyUserRole() *UserRole {
+	return &UserRole{}
+}
+
+// UserRole is the path of a role of a user
+type UserRole struct {
+	Id   string         `uri:"id" binding:"required" validate:"required,uuid"`
+	Role model.RoleCode `uri:"role" binding:"required" validate:"required,uppercase"`
+	ID   uuid.UUID      `uri:"-" validate:"-"`
+}
+
+func (d *UserRole) GetValue() *UserRole {
+	id, err := uuid.Parse(d.Id)
+	if err == nil {
+		d.ID = id
+	}
+	return d
+}
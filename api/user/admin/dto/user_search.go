@@ -0,0 +1,11 @@
+package dto
+
+import "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+
+// UserSearch filters the users by a part of the email or name and by a role, users of every status are listed
+type UserSearch struct {
+	Page  int64          `form:"page" binding:"required" validate:"required,min=1,max=1000"`
+	Limit int64          `form:"limit" binding:"required" validate:"required,min=1,max=1000"`
+	Query string         `form:"q" validate:"omitempty,max=100"`
+	Role  model.RoleCode `form:"role" validate:"omitempty,uppercase"`
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/admin/dto"
+	userDto "github.com/afteracademy/goserve-example-api-server-postgres/api/user/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) SearchUsers(d *dto.UserSearch) ([]*dto.UserInfo, error) {
+	args := m.Called(d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.UserInfo), args.Error(1)
+}
+
+func (m *MockService) GetUser(id uuid.UUID) (*dto.UserInfo, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserInfo), args.Error(1)
+}
+
+func (m *MockService) GetRoles() ([]*userDto.RoleInfo, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*userDto.RoleInfo), args.Error(1)
+}
+
+func (m *MockService) GrantRole(id uuid.UUID, code userModel.RoleCode) error {
+	args := m.Called(id, code)
+	return args.Error(0)
+}
+
+func (m *MockService) RevokeRole(admin *userModel.User, id uuid.UUID, code userModel.RoleCode) error {
+	args := m.Called(admin, id, code)
+	return args.Error(0)
+}
+
+func (m *MockService) DisableUser(admin *userModel.User, id uuid.UUID) error {
+	args := m.Called(admin, id)
+	return args.Error(0)
+}
+
+func (m *MockService) EnableUser(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockService) SignOutUser(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
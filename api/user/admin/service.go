@@ -0,0 +1,359 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/cache"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/admin/dto"
+	userDto "github.com/afteracademy/goserve-example-api-server-postgres/api/user/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/afteracademy/goserve/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Service interface {
+	SearchUsers(d *dto.UserSearch) ([]*dto.UserInfo, error)
+	GetUser(id uuid.UUID) (*dto.UserInfo, error)
+	GetRoles() ([]*userDto.RoleInfo, error)
+	GrantRole(id uuid.UUID, code userModel.RoleCode) error
+	RevokeRole(admin *userModel.User, id uuid.UUID, code userModel.RoleCode) error
+	DisableUser(admin *userModel.User, id uuid.UUID) error
+	EnableUser(id uuid.UUID) error
+	SignOutUser(id uuid.UUID) error
+}
+
+type service struct {
+	db          postgres.Database
+	authService auth.Service
+	authCache   cache.Service
+}
+
+func NewService(db postgres.Database, authService auth.Service, authCache cache.Service) Service {
+	return &service{
+		db:          db,
+		authService: authService,
+		authCache:   authCache,
+	}
+}
+
+const userInfoQuery = `
+	SELECT
+		u.id,
+		u.email,
+		u.name,
+		u.profile_pic_url,
+		u.verified,
+		u.mfa_enabled,
+		u.status,
+		u.created_at,
+		u.updated_at,
+		COALESCE(
+			array_agg(r.code ORDER BY r.code) FILTER (WHERE r.id IS NOT NULL),
+			'{}'
+		)
+	FROM users u
+	LEFT JOIN user_roles ur
+		ON ur.user_id = u.id
+	LEFT JOIN roles r
+		ON r.id = ur.role_id
+`
+
+func scanUserInfo(row pgx.Row) (*dto.UserInfo, error) {
+	var user userModel.User
+	var roles []userModel.RoleCode
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.Name,
+		&user.ProfilePicURL,
+		&user.Verified,
+		&user.MfaEnabled,
+		&user.Status,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&roles,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if roles == nil {
+		roles = []userModel.RoleCode{}
+	}
+	return dto.NewUserInfo(&user, roles), nil
+}
+
+// likePattern matches the text anywhere, the wildcards typed by the admin are matched literally
+func likePattern(text string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+	return "%" + escaped + "%"
+}
+
+func (s *service) SearchUsers(d *dto.UserSearch) ([]*dto.UserInfo, error) {
+	ctx := context.Background()
+
+	query := userInfoQuery + `
+		WHERE ($1 = '' OR u.email ILIKE $2 OR u.name ILIKE $2)
+		  AND ($3 = '' OR EXISTS (
+			SELECT 1
+			FROM user_roles fur
+			INNER JOIN roles fr
+				ON fr.id = fur.role_id
+			WHERE fur.user_id = u.id
+			  AND fr.code = $3
+		  ))
+		GROUP BY u.id
+		ORDER BY u.created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	offset := (d.Page - 1) * d.Limit
+
+	rows, err := s.db.Pool().Query(
+		ctx,
+		query,
+		d.Query,
+		likePattern(d.Query),
+		string(d.Role),
+		d.Limit,
+		offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos := []*dto.UserInfo{}
+
+	for rows.Next() {
+		info, err := scanUserInfo(rows)
+		if err != nil {
+			return nil, err
+		}
+		dtos = append(dtos, info)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dtos, nil
+}
+
+func (s *service) GetUser(id uuid.UUID) (*dto.UserInfo, error) {
+	ctx := context.Background()
+
+	query := userInfoQuery + `
+		WHERE u.id = $1
+		GROUP BY u.id
+	`
+
+	info, err := scanUserInfo(s.db.Pool().QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, network.NewNotFoundError("user not found", err)
+		}
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func (s *service) GetRoles() ([]*userDto.RoleInfo, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT
+			id,
+			code,
+			status,
+			created_at,
+			updated_at
+		FROM roles
+		WHERE status = TRUE
+		ORDER BY code
+	`
+
+	rows, err := s.db.Pool().Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos := []*userDto.RoleInfo{}
+
+	for rows.Next() {
+		var role userModel.Role
+		if err := rows.Scan(
+			&role.ID,
+			&role.Code,
+			&role.Status,
+			&role.CreatedAt,
+			&role.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		dtos = append(dtos, userDto.NewRoleInfo(&role))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dtos, nil
+}
+
+func (s *service) GrantRole(id uuid.UUID, code userModel.RoleCode) error {
+	ctx := context.Background()
+
+	if err := s.ensureUserExists(ctx, id); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO user_roles (user_id, role_id)
+		SELECT $1, id
+		FROM roles
+		WHERE code = $2
+		  AND status = TRUE
+		ON CONFLICT DO NOTHING
+	`
+
+	tag, err := s.db.Pool().Exec(ctx, query, id, code)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		exists, err := s.hasRole(ctx, id, code)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return network.NewNotFoundError("role not found", nil)
+		}
+	}
+
+	// the roles of the user are cached for the authorization
+	s.authCache.ClearUser(id)
+	return nil
+}
+
+func (s *service) RevokeRole(admin *userModel.User, id uuid.UUID, code userModel.RoleCode) error {
+	if admin.ID == id && code == userModel.RoleCodeAdmin {
+		return network.NewBadRequestError("admin role can not be revoked from yourself", nil)
+	}
+
+	ctx := context.Background()
+
+	query := `
+		DELETE FROM user_roles ur
+		USING roles r
+		WHERE ur.role_id = r.id
+		  AND ur.user_id = $1
+		  AND r.code = $2
+	`
+
+	tag, err := s.db.Pool().Exec(ctx, query, id, code)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return network.NewNotFoundError("user does not have the role", nil)
+	}
+
+	s.authCache.ClearUser(id)
+	return nil
+}
+
+// DisableUser blocks the account, the authentication only loads active users so their
+// existing tokens stop working once the cached user is cleared
+func (s *service) DisableUser(admin *userModel.User, id uuid.UUID) error {
+	if admin.ID == id {
+		return network.NewBadRequestError("you can not disable yourself", nil)
+	}
+
+	if err := s.updateStatus(id, false); err != nil {
+		return err
+	}
+
+	return s.SignOutUser(id)
+}
+
+func (s *service) EnableUser(id uuid.UUID) error {
+	return s.updateStatus(id, true)
+}
+
+// SignOutUser removes all the sessions of the user, the refresh tokens can not be renewed after it
+func (s *service) SignOutUser(id uuid.UUID) error {
+	if err := s.ensureUserExists(context.Background(), id); err != nil {
+		return err
+	}
+	return s.authService.SignOutAll(&userModel.User{ID: id})
+}
+
+func (s *service) updateStatus(id uuid.UUID, status bool) error {
+	ctx := context.Background()
+
+	query := `
+		UPDATE users
+		SET status = $2,
+			updated_at = NOW()
+		WHERE id = $1
+	`
+
+	tag, err := s.db.Pool().Exec(ctx, query, id, status)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return network.NewNotFoundError("user not found", nil)
+	}
+
+	s.authCache.ClearUser(id)
+	return nil
+}
+
+func (s *service) ensureUserExists(ctx context.Context, id uuid.UUID) error {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM users
+			WHERE id = $1
+		)
+	`
+
+	var exists bool
+	if err := s.db.Pool().QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return err
+	}
+
+	if !exists {
+		return network.NewNotFoundError("user not found", nil)
+	}
+	return nil
+}
+
+func (s *service) hasRole(ctx context.Context, id uuid.UUID, code userModel.RoleCode) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM user_roles ur
+			INNER JOIN roles r
+				ON r.id = ur.role_id
+			WHERE ur.user_id = $1
+			  AND r.code = $2
+			  AND r.status = TRUE
+		)
+	`
+
+	var exists bool
+	err := s.db.Pool().QueryRow(ctx, query, id, code).Scan(&exists)
+	return exists, err
+}
@@ -1,6 +1,7 @@
 package user
 
 import (
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
 	"github.com/afteracademy/goserve-example-api-server-postgres/common"
 	coredto "github.com/afteracademy/goserve/v2/dto"
 	"github.com/afteracademy/goserve/v2/network"
@@ -26,8 +27,12 @@ func NewController(
 }
 
 func (c *controller) MountRoutes(group *gin.RouterGroup) {
-	group.GET("/id/:id", c.getPublicProfileHandler)
-	private := group.Use(c.Authentication())
+	group.GET("/id/:id", common.KeyPermission(authModel.ReadPermission), c.getPublicProfileHandler)
+	private := group.Use(
+		common.KeyPermission(authModel.GeneralPermission),
+		common.TokenScope(authModel.ScopeProfileRead),
+		c.Authentication(),
+	)
 	private.GET("/mine", c.getPrivateProfileHandler)
 }
 
@@ -11,6 +11,8 @@ type UserPrivate struct {
 	Name          string      `json:"name" binding:"required" validate:"required"`
 	ProfilePicURL *string     `json:"profilePicUrl,omitempty" validate:"omitempty,url"`
 	Roles         []*RoleInfo `json:"roles" validate:"required,dive,required"`
+	Verified      bool        `json:"verified"`
+	MfaEnabled    bool        `json:"mfaEnabled"`
 }
 
 func NewUserPrivate(user *model.User) *UserPrivate {
@@ -25,5 +27,7 @@ func NewUserPrivate(user *model.User) *UserPrivate {
 		Name:          user.Name,
 		ProfilePicURL: user.ProfilePicURL,
 		Roles:         roles,
+		Verified:      user.Verified,
+		MfaEnabled:    user.MfaEnabled,
 	}
 }
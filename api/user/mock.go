@@ -1,9 +1,12 @@
 package user
 
 import (
+	"context"
+
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/dto"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -53,6 +56,38 @@ func (m *MockService) CreateUser(
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
+func (m *MockService) CreateExternalUser(
+	email string, name string, profilePicURL *string, verified bool, roles []*model.Role,
+	link func(ctx context.Context, tx pgx.Tx, user *model.User) error,
+) (*model.User, error) {
+	args := m.Called(email, name, profilePicURL, verified, roles, link)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockService) CreateInvitedUser(
+	email string, password string, name string, profilePicURL *string, roles []*model.Role,
+	accept func(ctx context.Context, tx pgx.Tx, user *model.User) error,
+) (*model.User, error) {
+	args := m.Called(email, password, name, profilePicURL, roles, accept)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockService) MarkUserVerified(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockService) UpdateUserPassword(id uuid.UUID, password string) error {
+	args := m.Called(id, password)
+	return args.Error(0)
+}
+
 func (m *MockService) RemoveUserByEmail(email string) (bool, error) {
 	args := m.Called(email)
 	return args.Bool(0), args.Error(1)
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const PermissionsTableName = "permissions"
+
+// PermissionCode is declared by the routes and granted to users through their roles,
+// a role also holds the permissions of the roles it inherits
+type PermissionCode string
+
+const (
+	PermissionBlogWrite        PermissionCode = "blog.write"
+	PermissionBlogPublish      PermissionCode = "blog.publish"
+	PermissionApiKeyManage     PermissionCode = "apikey.manage"
+	PermissionLockoutManage    PermissionCode = "lockout.manage"
+	PermissionPermissionManage PermissionCode = "permission.manage"
+	PermissionUserManage       PermissionCode = "user.manage"
+	PermissionUserImpersonate  PermissionCode = "user.impersonate"
+	PermissionAuditRead        PermissionCode = "audit.read"
+	PermissionUserInvite       PermissionCode = "user.invite"
+	PermissionCommentModerate  PermissionCode = "comment.moderate"
+)
+
+type Permission struct {
+	ID          uuid.UUID
+	Code        PermissionCode
+	Description string
+	CreatedAt   time.Time
+}
@@ -17,6 +17,7 @@ type User struct {
 	ProfilePicURL *string
 	Roles         []*Role // not stored in DB directly
 	Verified      bool
+	MfaEnabled    bool
 	Status        bool
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
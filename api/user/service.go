@@ -2,12 +2,15 @@ package user
 
 import (
 	"context"
+	"errors"
 
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/cache"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/dto"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
 	"github.com/afteracademy/goserve/v2/network"
 	"github.com/afteracademy/goserve/v2/postgres"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type Service interface {
@@ -21,6 +24,16 @@ type Service interface {
 	CreateUser(
 		email string, password string, name string, profilePicURL *string, roles []*model.Role,
 	) (*model.User, error)
+	CreateExternalUser(
+		email string, name string, profilePicURL *string, verified bool, roles []*model.Role,
+		link func(ctx context.Context, tx pgx.Tx, user *model.User) error,
+	) (*model.User, error)
+	CreateInvitedUser(
+		email string, password string, name string, profilePicURL *string, roles []*model.Role,
+		accept func(ctx context.Context, tx pgx.Tx, user *model.User) error,
+	) (*model.User, error)
+	MarkUserVerified(id uuid.UUID) error
+	UpdateUserPassword(id uuid.UUID, password string) error
 
 	/*--------only for tests----------*/
 	CreateRole(code model.RoleCode) (*model.Role, error)
@@ -29,12 +42,14 @@ type Service interface {
 }
 
 type service struct {
-	db postgres.Database
+	db        postgres.Database
+	authCache cache.Service
 }
 
-func NewService(db postgres.Database) Service {
+func NewService(db postgres.Database, authCache cache.Service) Service {
 	return &service{
-		db: db,
+		db:        db,
+		authCache: authCache,
 	}
 }
 
@@ -51,8 +66,19 @@ func (s *service) FetchUserPublicProfile(userId uuid.UUID) (*dto.UserPublic, err
 	return dto.NewUserPublic(user), nil
 }
 
+// FetchUserById is read by the authentication on every request, so it goes through the auth cache
 func (s *service) FetchUserById(id uuid.UUID) (*model.User, error) {
-	return s.FindUserById(context.Background(), id)
+	if user, ok := s.authCache.GetUser(id); ok {
+		return user, nil
+	}
+
+	user, err := s.FindUserById(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.authCache.SetUser(user)
+	return user, nil
 }
 
 func (s *service) FetchUserByEmail(email string) (*model.User, error) {
@@ -186,6 +212,7 @@ func (s *service) FindUserById(
 			name,
 			profile_pic_url,
 			verified,
+			mfa_enabled,
 			status,
 			created_at,
 			updated_at
@@ -203,6 +230,7 @@ func (s *service) FindUserById(
 			&user.Name,
 			&user.ProfilePicURL,
 			&user.Verified,
+			&user.MfaEnabled,
 			&user.Status,
 			&user.CreatedAt,
 			&user.UpdatedAt,
@@ -234,6 +262,7 @@ func (s *service) FindUserByEmail(
 			name,
 			profile_pic_url,
 			verified,
+			mfa_enabled,
 			status,
 			created_at,
 			updated_at
@@ -252,6 +281,7 @@ func (s *service) FindUserByEmail(
 			&user.Name,
 			&user.ProfilePicURL,
 			&user.Verified,
+			&user.MfaEnabled,
 			&user.Status,
 			&user.CreatedAt,
 			&user.UpdatedAt,
@@ -314,6 +344,32 @@ func (s *service) FindUserRoles(ctx context.Context, user model.User) ([]*model.
 
 func (s *service) CreateUser(
 	email string, password string, name string, profilePicURL *string, roles []*model.Role,
+) (*model.User, error) {
+	return s.createUser(email, &password, name, profilePicURL, false, roles, nil)
+}
+
+// CreateExternalUser creates a user signing in with an external identity, it has no password
+// and is verified when the identity provider verified the email. The link runs in the same
+// transaction so that the user is only created along with its identity.
+func (s *service) CreateExternalUser(
+	email string, name string, profilePicURL *string, verified bool, roles []*model.Role,
+	link func(ctx context.Context, tx pgx.Tx, user *model.User) error,
+) (*model.User, error) {
+	return s.createUser(email, nil, name, profilePicURL, verified, roles, link)
+}
+
+// CreateInvitedUser creates a verified user, the invite mail proved the email, and runs accept
+// in the same transaction so that the user is only created along with the invite being used
+func (s *service) CreateInvitedUser(
+	email string, password string, name string, profilePicURL *string, roles []*model.Role,
+	accept func(ctx context.Context, tx pgx.Tx, user *model.User) error,
+) (*model.User, error) {
+	return s.createUser(email, &password, name, profilePicURL, true, roles, accept)
+}
+
+func (s *service) createUser(
+	email string, password *string, name string, profilePicURL *string, verified bool, roles []*model.Role,
+	inTx func(ctx context.Context, tx pgx.Tx, user *model.User) error,
 ) (*model.User, error) {
 	ctx := context.Background()
 
@@ -341,6 +397,7 @@ func (s *service) CreateUser(
 			name,
 			profile_pic_url,
 			verified,
+			mfa_enabled,
 			status,
 			created_at,
 			updated_at
@@ -353,7 +410,7 @@ func (s *service) CreateUser(
 		password,
 		name,
 		profilePicURL,
-		false,
+		verified,
 	).Scan(
 		&user.ID,
 		&user.Email,
@@ -361,6 +418,7 @@ func (s *service) CreateUser(
 		&user.Name,
 		&user.ProfilePicURL,
 		&user.Verified,
+		&user.MfaEnabled,
 		&user.Status,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -382,6 +440,12 @@ func (s *service) CreateUser(
 		}
 	}
 
+	if inTx != nil {
+		if err := inTx(ctx, tx, &user); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
@@ -391,6 +455,56 @@ func (s *service) CreateUser(
 	return &user, nil
 }
 
+func (s *service) MarkUserVerified(id uuid.UUID) error {
+	ctx := context.Background()
+
+	query := `
+		UPDATE users
+		SET
+			verified = TRUE,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		  AND status = TRUE
+	`
+
+	tag, err := s.db.Pool().Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return network.NewNotFoundError("user does not exists", nil)
+	}
+
+	s.authCache.ClearUser(id)
+	return nil
+}
+
+func (s *service) UpdateUserPassword(id uuid.UUID, password string) error {
+	ctx := context.Background()
+
+	query := `
+		UPDATE users
+		SET
+			password = $1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		  AND status = TRUE
+	`
+
+	tag, err := s.db.Pool().Exec(ctx, query, password, id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return network.NewNotFoundError("user does not exists", nil)
+	}
+
+	s.authCache.ClearUser(id)
+	return nil
+}
+
 func (s *service) FindUserPrivateProfile(
 	ctx context.Context,
 	user *model.User,
@@ -403,6 +517,7 @@ func (s *service) FindUserPrivateProfile(
 			name,
 			profile_pic_url,
 			verified,
+			mfa_enabled,
 			status,
 			created_at,
 			updated_at
@@ -420,6 +535,7 @@ func (s *service) FindUserPrivateProfile(
 			&result.Name,
 			&result.ProfilePicURL,
 			&result.Verified,
+			&result.MfaEnabled,
 			&result.Status,
 			&result.CreatedAt,
 			&result.UpdatedAt,
@@ -459,18 +575,25 @@ func (s *service) FindUserPublicProfile(
 	return &user, nil
 }
 
+// DeleteUserByEmail removes the reactions of the user with it, their trigger takes back the likes
 func (s *service) DeleteUserByEmail(ctx context.Context, email string) (bool, error) {
 	query := `
 		DELETE FROM users
 		WHERE email = $1
+		RETURNING id
 	`
 
-	tag, err := s.db.Pool().Exec(ctx, query, email)
+	var id uuid.UUID
+	err := s.db.Pool().QueryRow(ctx, query, email).Scan(&id)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
 		return false, err
 	}
 
-	return tag.RowsAffected() > 0, nil
+	s.authCache.ClearUser(id)
+	return true, nil
 }
 
 func (s *service) CreateRole(code model.RoleCode) (*model.Role, error) {
@@ -0,0 +1,153 @@
+package common
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/afteracademy/goserve/v2/utility"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// TokenTransportHeader is sent by the browser clients that want the tokens in cookies
+	TokenTransportHeader = "X-Token-Transport"
+	TokenTransportCookie = "cookie"
+	// CsrfTokenHeader carries the value of the csrf cookie on the state changing requests
+	CsrfTokenHeader = "X-CSRF-Token"
+
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+	CsrfTokenCookie    = "csrf_token"
+
+	// the refresh token is only sent to the refresh endpoint
+	refreshTokenCookiePath = "/auth/token"
+)
+
+// TokenCookies keeps the tokens of the browser clients in HttpOnly cookies, so that scripts
+// can not read them. Cookies are sent by the browser on their own, the state changing requests
+// are therefore checked with a double submit csrf token. Bearer clients are not affected.
+type TokenCookies interface {
+	// Requested tells whether the client asked for the tokens in cookies and the mode is enabled
+	Requested(ctx *gin.Context) bool
+	SetTokens(ctx *gin.Context, tokens *authDto.Tokens) error
+	Clear(ctx *gin.Context)
+	AccessToken(ctx *gin.Context) string
+	RefreshToken(ctx *gin.Context) string
+	// VerifyCsrf passes the safe methods, the others need the csrf header to match the cookie
+	VerifyCsrf(ctx *gin.Context) bool
+}
+
+type tokenCookies struct {
+	enabled  bool
+	domain   string
+	sameSite http.SameSite
+	// the access cookie outlives the access token so that an expired token can still be renewed
+	maxAge int
+}
+
+func NewTokenCookies(env *config.Env) TokenCookies {
+	return &tokenCookies{
+		enabled:  env.AuthCookieEnabled,
+		domain:   env.AuthCookieDomain,
+		sameSite: parseSameSite(env.AuthCookieSameSite),
+		maxAge:   int(env.RefreshTokenValiditySec),
+	}
+}
+
+func parseSameSite(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
+}
+
+func (c *tokenCookies) Requested(ctx *gin.Context) bool {
+	return c.enabled && strings.EqualFold(ctx.GetHeader(TokenTransportHeader), TokenTransportCookie)
+}
+
+func (c *tokenCookies) SetTokens(ctx *gin.Context, tokens *authDto.Tokens) error {
+	csrfToken, err := utility.GenerateRandomString(32)
+	if err != nil {
+		return err
+	}
+
+	c.set(ctx, AccessTokenCookie, tokens.AccessToken, "/", true, c.maxAge)
+	c.set(ctx, RefreshTokenCookie, tokens.RefreshToken, refreshTokenCookiePath, true, c.maxAge)
+	// readable by the scripts of the client to be sent back in the header
+	c.set(ctx, CsrfTokenCookie, csrfToken, "/", false, c.maxAge)
+	return nil
+}
+
+func (c *tokenCookies) Clear(ctx *gin.Context) {
+	c.set(ctx, AccessTokenCookie, "", "/", true, -1)
+	c.set(ctx, RefreshTokenCookie, "", refreshTokenCookiePath, true, -1)
+	c.set(ctx, CsrfTokenCookie, "", "/", false, -1)
+}
+
+func (c *tokenCookies) set(ctx *gin.Context, name string, value string, path string, httpOnly bool, maxAge int) {
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   c.domain,
+		MaxAge:   maxAge,
+		Secure:   true,
+		HttpOnly: httpOnly,
+		SameSite: c.sameSite,
+	})
+}
+
+func (c *tokenCookies) AccessToken(ctx *gin.Context) string {
+	return c.cookie(ctx, AccessTokenCookie)
+}
+
+func (c *tokenCookies) RefreshToken(ctx *gin.Context) string {
+	return c.cookie(ctx, RefreshTokenCookie)
+}
+
+func (c *tokenCookies) cookie(ctx *gin.Context, name string) string {
+	if !c.enabled {
+		return ""
+	}
+	value, err := ctx.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+func (c *tokenCookies) VerifyCsrf(ctx *gin.Context) bool {
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	header := ctx.GetHeader(CsrfTokenHeader)
+	cookie := c.cookie(ctx, CsrfTokenCookie)
+	if header == "" || cookie == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(header), []byte(cookie)) == 1
+}
+
+// SendUserAuth sends the signed in user, the clients asking for cookies get the tokens
+// as cookies and not in the body
+func SendUserAuth(ctx *gin.Context, cookies TokenCookies, data *authDto.UserAuth) {
+	if cookies.Requested(ctx) {
+		if err := cookies.SetTokens(ctx, data.Tokens); err != nil {
+			network.SendInternalServerError(ctx, "something went wrong", err)
+			return
+		}
+		data = &authDto.UserAuth{User: data.User}
+	}
+
+	network.SendSuccessDataResponse(ctx, "success", data)
+}
@@ -0,0 +1,121 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authDto "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveUserAuth(cookies TokenCookies, headers map[string]string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/", func(ctx *gin.Context) {
+		user := &model.User{
+			ID:    uuid.New(),
+			Email: "test@abc.com",
+			Name:  "test",
+			Roles: []*model.Role{{ID: uuid.New(), Code: model.RoleCodeLearner}},
+		}
+		SendUserAuth(ctx, cookies, authDto.NewUserAuth(user, authDto.NewTokens("access", "refresh")))
+	})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	engine.ServeHTTP(rr, req)
+	return rr
+}
+
+func findCookie(rr *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range rr.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestSendUserAuth_Bearer(t *testing.T) {
+	cookies := NewTokenCookies(&config.Env{AuthCookieEnabled: true})
+
+	rr := serveUserAuth(cookies, nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"accessToken":"access"`)
+	assert.Empty(t, rr.Result().Cookies())
+}
+
+func TestSendUserAuth_CookieModeDisabled(t *testing.T) {
+	cookies := NewTokenCookies(&config.Env{})
+
+	rr := serveUserAuth(cookies, map[string]string{TokenTransportHeader: TokenTransportCookie})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"accessToken":"access"`)
+	assert.Empty(t, rr.Result().Cookies())
+}
+
+func TestSendUserAuth_Cookies(t *testing.T) {
+	cookies := NewTokenCookies(&config.Env{
+		AuthCookieEnabled:       true,
+		AuthCookieSameSite:      "lax",
+		RefreshTokenValiditySec: 3600,
+	})
+
+	rr := serveUserAuth(cookies, map[string]string{TokenTransportHeader: TokenTransportCookie})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "tokens")
+
+	access := findCookie(rr, AccessTokenCookie)
+	if assert.NotNil(t, access) {
+		assert.Equal(t, "access", access.Value)
+		assert.True(t, access.HttpOnly)
+		assert.True(t, access.Secure)
+		assert.Equal(t, http.SameSiteLaxMode, access.SameSite)
+		assert.Equal(t, 3600, access.MaxAge)
+	}
+
+	refresh := findCookie(rr, RefreshTokenCookie)
+	if assert.NotNil(t, refresh) {
+		assert.Equal(t, "refresh", refresh.Value)
+		assert.True(t, refresh.HttpOnly)
+		assert.Equal(t, refreshTokenCookiePath, refresh.Path)
+	}
+
+	csrf := findCookie(rr, CsrfTokenCookie)
+	if assert.NotNil(t, csrf) {
+		assert.NotEmpty(t, csrf.Value)
+		assert.False(t, csrf.HttpOnly)
+	}
+}
+
+func TestTokenCookies_VerifyCsrf(t *testing.T) {
+	cookies := NewTokenCookies(&config.Env{AuthCookieEnabled: true})
+
+	verify := func(method string, cookie string, header string) bool {
+		gin.SetMode(gin.TestMode)
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(method, "/", nil)
+		if cookie != "" {
+			ctx.Request.AddCookie(&http.Cookie{Name: CsrfTokenCookie, Value: cookie})
+		}
+		if header != "" {
+			ctx.Request.Header.Set(CsrfTokenHeader, header)
+		}
+		return cookies.VerifyCsrf(ctx)
+	}
+
+	assert.True(t, verify(http.MethodGet, "", ""))
+	assert.False(t, verify(http.MethodPost, "", ""))
+	assert.False(t, verify(http.MethodPost, "csrf", ""))
+	assert.False(t, verify(http.MethodPost, "", "csrf"))
+	assert.False(t, verify(http.MethodDelete, "csrf", "other"))
+	assert.True(t, verify(http.MethodDelete, "csrf", "csrf"))
+}
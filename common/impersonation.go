@@ -0,0 +1,18 @@
+package common
+
+import (
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+// RejectImpersonation keeps impersonated sessions away from the sensitive routes, like the password,
+// mfa, sessions and the admin routes. It has to be mounted after the authentication which sets the actor.
+func RejectImpersonation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, exists := ctx.Get(payloadActor); exists {
+			network.SendForbiddenError(ctx, "permission denied: not allowed while impersonating", nil)
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,37 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveRejectImpersonation(handlers ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	handlers = append(handlers, RejectImpersonation(), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	engine.GET("/", handlers...)
+
+	rr := httptest.NewRecorder()
+	engine.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rr
+}
+
+func TestRejectImpersonation_OwnSession(t *testing.T) {
+	rr := serveRejectImpersonation()
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestRejectImpersonation_Impersonated(t *testing.T) {
+	rr := serveRejectImpersonation(func(ctx *gin.Context) {
+		NewContextPayload().SetActor(ctx, &userModel.User{ID: uuid.New()})
+	})
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
@@ -0,0 +1,36 @@
+package common
+
+import (
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+type mockApiKeyController struct {
+	network.Controller
+	apikey *authModel.ApiKey
+}
+
+// MockApiKey wraps a controller for the controller tests so that its routes get an api key
+// with every permission, the key protection root middleware sets the key in the server
+func MockApiKey(controller network.Controller) network.Controller {
+	return &mockApiKeyController{
+		Controller: controller,
+		apikey: &authModel.ApiKey{
+			Permissions: []authModel.Permission{
+				authModel.ReadPermission,
+				authModel.AuthorPermission,
+				authModel.GeneralPermission,
+				authModel.AdminPermission,
+			},
+		},
+	}
+}
+
+func (c *mockApiKeyController) MountRoutes(group *gin.RouterGroup) {
+	group.Use(func(ctx *gin.Context) {
+		NewContextPayload().SetApiKey(ctx, c.apikey)
+		ctx.Next()
+	})
+	c.Controller.MountRoutes(group)
+}
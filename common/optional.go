@@ -0,0 +1,18 @@
+package common
+
+import (
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+// OptionalAuthentication lets a public route know the signed in user, the requests carrying a bearer
+// token or the access token cookie are authenticated and the others go on without a user
+func OptionalAuthentication(cookies TokenCookies, authentication gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader(network.AuthorizationHeader) == "" && cookies.AccessToken(ctx) == "" {
+			ctx.Next()
+			return
+		}
+		authentication(ctx)
+	}
+}
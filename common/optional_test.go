@@ -0,0 +1,56 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func optionalRouter(authenticated *bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	authentication := func(ctx *gin.Context) {
+		*authenticated = true
+		ctx.Next()
+	}
+	router.GET("/", OptionalAuthentication(NewTokenCookies(&config.Env{AuthCookieEnabled: true}), authentication), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestOptionalAuthentication_Anonymous(t *testing.T) {
+	authenticated := false
+	rr := httptest.NewRecorder()
+	optionalRouter(&authenticated).ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.False(t, authenticated)
+}
+
+func TestOptionalAuthentication_BearerToken(t *testing.T) {
+	authenticated := false
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(network.AuthorizationHeader, "Bearer token")
+	rr := httptest.NewRecorder()
+	optionalRouter(&authenticated).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, authenticated)
+}
+
+func TestOptionalAuthentication_Cookie(t *testing.T) {
+	authenticated := false
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: "token"})
+	rr := httptest.NewRecorder()
+	optionalRouter(&authenticated).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, authenticated)
+}
@@ -12,6 +12,11 @@ const (
 	payloadApiKey   string = "apikey"
 	payloadUser     string = "user"
 	payloadKeystore string = "keystore"
+	// personal access token authentication
+	payloadAccessToken string = "accesstoken"
+	payloadTokenScopes string = "tokenscopes"
+	// admin impersonation
+	payloadActor string = "actor"
 )
 
 type ContextPayload interface {
@@ -19,8 +24,13 @@ type ContextPayload interface {
 	MustGetApiKey(ctx *gin.Context) *authModel.ApiKey
 	SetUser(ctx *gin.Context, value *userModel.User)
 	MustGetUser(ctx *gin.Context) *userModel.User
+	GetUser(ctx *gin.Context) (*userModel.User, bool)
 	SetKeystore(ctx *gin.Context, value *authModel.Keystore)
 	MustGetKeystore(ctx *gin.Context) *authModel.Keystore
+	SetAccessToken(ctx *gin.Context, value *authModel.AccessToken)
+	GetAccessToken(ctx *gin.Context) (*authModel.AccessToken, bool)
+	SetActor(ctx *gin.Context, value *userModel.User)
+	GetActor(ctx *gin.Context) (*userModel.User, bool)
 }
 
 type payload struct{}
@@ -53,6 +63,16 @@ func (u *payload) MustGetUser(ctx *gin.Context) *userModel.User {
 	return value
 }
 
+// GetUser finds the user of a request that may not be authenticated
+func (u *payload) GetUser(ctx *gin.Context) (*userModel.User, bool) {
+	value, exists := ctx.Get(payloadUser)
+	if !exists {
+		return nil, false
+	}
+	user, ok := value.(*userModel.User)
+	return user, ok
+}
+
 func (u *payload) SetKeystore(ctx *gin.Context, value *authModel.Keystore) {
 	ctx.Set(payloadKeystore, value)
 }
@@ -64,3 +84,31 @@ func (u *payload) MustGetKeystore(ctx *gin.Context) *authModel.Keystore {
 	}
 	return value
 }
+
+func (u *payload) SetAccessToken(ctx *gin.Context, value *authModel.AccessToken) {
+	ctx.Set(payloadAccessToken, value)
+}
+
+// GetAccessToken finds the personal access token of a request authenticated with one
+func (u *payload) GetAccessToken(ctx *gin.Context) (*authModel.AccessToken, bool) {
+	value, exists := ctx.Get(payloadAccessToken)
+	if !exists {
+		return nil, false
+	}
+	accessToken, ok := value.(*authModel.AccessToken)
+	return accessToken, ok
+}
+
+func (u *payload) SetActor(ctx *gin.Context, value *userModel.User) {
+	ctx.Set(payloadActor, value)
+}
+
+// GetActor finds the admin behind an impersonated request, the user of the request is the impersonated one
+func (u *payload) GetActor(ctx *gin.Context) (*userModel.User, bool) {
+	value, exists := ctx.Get(payloadActor)
+	if !exists {
+		return nil, false
+	}
+	actor, ok := value.(*userModel.User)
+	return actor, ok
+}
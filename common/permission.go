@@ -0,0 +1,35 @@
+package common
+
+import (
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+)
+
+// KeyPermission rejects the request unless its api key has one of the permissions,
+// it is mounted by controllers next to the role checks. The key protection root middleware
+// sets the key, a route without one is refused rather than let through unchecked.
+func KeyPermission(permissions ...authModel.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value, exists := ctx.Get(payloadApiKey)
+		if !exists {
+			network.SendForbiddenError(ctx, "permission denied: missing x-api-key", nil)
+			return
+		}
+
+		apikey, ok := value.(*authModel.ApiKey)
+		if !ok {
+			network.SendForbiddenError(ctx, "permission denied: invalid x-api-key", nil)
+			return
+		}
+
+		for _, permission := range permissions {
+			if apikey.HasPermission(permission) {
+				ctx.Next()
+				return
+			}
+		}
+
+		network.SendForbiddenError(ctx, "permission denied: x-api-key lacks permission", nil)
+	}
+}
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveWithApiKey(apikey *authModel.ApiKey, permission authModel.Permission) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/",
+		func(ctx *gin.Context) {
+			NewContextPayload().SetApiKey(ctx, apikey)
+			ctx.Next()
+		},
+		KeyPermission(permission),
+		network.MockSuccessMsgHandler("success"),
+	)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	engine.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestKeyPermission_Denied(t *testing.T) {
+	apikey := &authModel.ApiKey{Permissions: []authModel.Permission{authModel.ReadPermission}}
+
+	rr := serveWithApiKey(apikey, authModel.GeneralPermission)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: x-api-key lacks permission"`)
+}
+
+func TestKeyPermission_Implied(t *testing.T) {
+	apikey := &authModel.ApiKey{Permissions: []authModel.Permission{authModel.GeneralPermission}}
+
+	rr := serveWithApiKey(apikey, authModel.ReadPermission)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"success"`)
+}
+
+func TestKeyPermission_Missing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/", KeyPermission(authModel.ReadPermission), network.MockSuccessMsgHandler("success"))
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	engine.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"message":"permission denied: missing x-api-key"`)
+}
@@ -0,0 +1,36 @@
+package common
+
+import (
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/gin-gonic/gin"
+)
+
+// TokenScope declares the scopes of which a personal access token needs one to use the routes,
+// it has to be mounted before the authentication which does the check. Routes without
+// a declared scope are not open to personal access tokens at all.
+func TokenScope(scopes ...authModel.Scope) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(payloadTokenScopes, scopes)
+		ctx.Next()
+	}
+}
+
+// AccessTokenAllowed checks a personal access token against the scopes declared for the route
+func AccessTokenAllowed(ctx *gin.Context, accessToken *authModel.AccessToken) bool {
+	value, exists := ctx.Get(payloadTokenScopes)
+	if !exists {
+		return false
+	}
+
+	scopes, ok := value.([]authModel.Scope)
+	if !ok {
+		return false
+	}
+
+	for _, scope := range scopes {
+		if accessToken.HasScope(scope) {
+			return true
+		}
+	}
+	return false
+}
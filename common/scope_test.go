@@ -0,0 +1,39 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authModel "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func accessTokenAllowed(accessToken *authModel.AccessToken, handlers ...gin.HandlerFunc) bool {
+	gin.SetMode(gin.TestMode)
+	allowed := false
+	engine := gin.New()
+	handlers = append(handlers, func(ctx *gin.Context) {
+		allowed = AccessTokenAllowed(ctx, accessToken)
+	})
+	engine.GET("/", handlers...)
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	return allowed
+}
+
+func TestAccessTokenAllowed_NoScopeDeclared(t *testing.T) {
+	accessToken := &authModel.AccessToken{Scopes: []authModel.Scope{authModel.ScopeBlogWrite}}
+	assert.False(t, accessTokenAllowed(accessToken))
+}
+
+func TestAccessTokenAllowed_Implied(t *testing.T) {
+	accessToken := &authModel.AccessToken{Scopes: []authModel.Scope{authModel.ScopeBlogWrite}}
+	assert.True(t, accessTokenAllowed(accessToken, TokenScope(authModel.ScopeBlogRead)))
+}
+
+func TestAccessTokenAllowed_MissingScope(t *testing.T) {
+	accessToken := &authModel.AccessToken{Scopes: []authModel.Scope{authModel.ScopeBlogRead}}
+	assert.False(t, accessTokenAllowed(accessToken, TokenScope(authModel.ScopeBlogWrite, authModel.ScopeProfileRead)))
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -28,11 +29,86 @@ type Env struct {
 	// keys
 	RSAPrivateKeyPath string `mapstructure:"RSA_PRIVATE_KEY_PATH"`
 	RSAPublicKeyPath  string `mapstructure:"RSA_PUBLIC_KEY_PATH"`
+	// comma separated public keys of retired signing keys, tokens signed by them stay valid
+	RSARetiredPublicKeyPaths string `mapstructure:"RSA_RETIRED_PUBLIC_KEY_PATHS"`
 	// Token
 	AccessTokenValiditySec  uint64 `mapstructure:"ACCESS_TOKEN_VALIDITY_SEC"`
 	RefreshTokenValiditySec uint64 `mapstructure:"REFRESH_TOKEN_VALIDITY_SEC"`
 	TokenIssuer             string `mapstructure:"TOKEN_ISSUER"`
 	TokenAudience           string `mapstructure:"TOKEN_AUDIENCE"`
+	// admin impersonation
+	ImpersonationTokenValiditySec uint64 `mapstructure:"IMPERSONATION_TOKEN_VALIDITY_SEC"`
+	// cookie mode for the browser clients, the tokens are kept in HttpOnly cookies
+	AuthCookieEnabled  bool   `mapstructure:"AUTH_COOKIE_ENABLED"`
+	AuthCookieDomain   string `mapstructure:"AUTH_COOKIE_DOMAIN"`
+	AuthCookieSameSite string `mapstructure:"AUTH_COOKIE_SAME_SITE"`
+	// mail
+	MailSender    string `mapstructure:"MAIL_SENDER"`
+	MailFrom      string `mapstructure:"MAIL_FROM"`
+	MailOutboxDir string `mapstructure:"MAIL_OUTBOX_DIR"`
+	SMTPHost      string `mapstructure:"SMTP_HOST"`
+	SMTPPort      uint16 `mapstructure:"SMTP_PORT"`
+	SMTPUser      string `mapstructure:"SMTP_USER"`
+	SMTPPwd       string `mapstructure:"SMTP_PASSWORD"`
+	// email verification
+	EmailVerificationUrl         string `mapstructure:"EMAIL_VERIFICATION_URL"`
+	EmailVerificationValiditySec uint64 `mapstructure:"EMAIL_VERIFICATION_VALIDITY_SEC"`
+	EmailVerificationResendSec   uint64 `mapstructure:"EMAIL_VERIFICATION_RESEND_SEC"`
+	// password reset
+	PasswordResetUrl         string `mapstructure:"PASSWORD_RESET_URL"`
+	PasswordResetValiditySec uint64 `mapstructure:"PASSWORD_RESET_VALIDITY_SEC"`
+	PasswordResetResendSec   uint64 `mapstructure:"PASSWORD_RESET_RESEND_SEC"`
+	// magic link sign in
+	MagicLinkUrl         string `mapstructure:"MAGIC_LINK_URL"`
+	MagicLinkValiditySec uint64 `mapstructure:"MAGIC_LINK_VALIDITY_SEC"`
+	MagicLinkResendSec   uint64 `mapstructure:"MAGIC_LINK_RESEND_SEC"`
+	// invites
+	InviteUrl         string `mapstructure:"INVITE_URL"`
+	InviteValiditySec uint64 `mapstructure:"INVITE_VALIDITY_SEC"`
+	// password hashing, argon2id or bcrypt, the parameters left 0 get the defaults
+	PasswordHashAlgorithm string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	Argon2MemoryKiB       uint32 `mapstructure:"ARGON2_MEMORY_KIB"`
+	Argon2Iterations      uint32 `mapstructure:"ARGON2_ITERATIONS"`
+	Argon2Parallelism     uint8  `mapstructure:"ARGON2_PARALLELISM"`
+	BcryptCost            int    `mapstructure:"BCRYPT_COST"`
+	// password policy, one breached password per line in the list file
+	PasswordMinLength        uint16 `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordBreachedListPath string `mapstructure:"PASSWORD_BREACHED_LIST_PATH"`
+	// sign in lockout
+	LockoutEmailMaxAttempts uint16 `mapstructure:"LOCKOUT_EMAIL_MAX_ATTEMPTS"`
+	LockoutIPMaxAttempts    uint16 `mapstructure:"LOCKOUT_IP_MAX_ATTEMPTS"`
+	LockoutWindowSec        uint64 `mapstructure:"LOCKOUT_WINDOW_SEC"`
+	LockoutBaseSec          uint64 `mapstructure:"LOCKOUT_BASE_SEC"`
+	LockoutMaxSec           uint64 `mapstructure:"LOCKOUT_MAX_SEC"`
+	// mfa
+	MfaIssuer               string `mapstructure:"MFA_ISSUER"`
+	MfaChallengeValiditySec uint64 `mapstructure:"MFA_CHALLENGE_VALIDITY_SEC"`
+	// comma separated role codes that can only be used with mfa enabled
+	MfaRequiredRoles string `mapstructure:"MFA_REQUIRED_ROLES"`
+	// auth cache, 0 disables the cache or its in-process layer
+	AuthCacheTTLSec      uint64 `mapstructure:"AUTH_CACHE_TTL_SEC"`
+	AuthCacheLocalTTLSec uint64 `mapstructure:"AUTH_CACHE_LOCAL_TTL_SEC"`
+	// role permissions are reloaded from the database this often
+	PermissionReloadSec uint64 `mapstructure:"PERMISSION_RELOAD_SEC"`
+	// blog views, a viewer is counted once per blog within the window, 0 gets the defaults
+	BlogViewWindowSec uint64 `mapstructure:"BLOG_VIEW_WINDOW_SEC"`
+	BlogViewFlushSec  uint64 `mapstructure:"BLOG_VIEW_FLUSH_SEC"`
+	// a blog is flagged for the editors once this many users have an open report on it,
+	// the resolved reports no longer count, 0 gets the default of 3
+	BlogFlagReportThreshold uint16 `mapstructure:"BLOG_FLAG_REPORT_THRESHOLD"`
+	// oidc, comma separated provider names each configured with the OIDC_<NAME>_* keys
+	OidcProviderNames    string `mapstructure:"OIDC_PROVIDERS"`
+	OidcStateValiditySec uint64 `mapstructure:"OIDC_STATE_VALIDITY_SEC"`
+	OidcProviders        []OidcProvider
+}
+
+type OidcProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
 }
 
 func NewEnv(filename string, override bool) *Env {
@@ -53,5 +129,34 @@ func NewEnv(filename string, override bool) *Env {
 		log.Fatal("Error loading environment file", err)
 	}
 
+	env.OidcProviders = loadOidcProviders(env.OidcProviderNames)
+
 	return &env
 }
+
+func loadOidcProviders(names string) []OidcProvider {
+	providers := make([]OidcProvider, 0)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OidcProvider{
+			Name:         name,
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			RedirectURL:  viper.GetString(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(viper.GetString(prefix + "SCOPES")),
+		}
+
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			log.Fatalf("Error loading oidc provider %s: %sISSUER, %sCLIENT_ID and %sREDIRECT_URL are required", name, prefix, prefix, prefix)
+		}
+
+		providers = append(providers, provider)
+	}
+	return providers
+}
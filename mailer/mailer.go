@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"fmt"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Sender interface {
+	Send(msg *Message) error
+}
+
+// NewSender selects the sender implementation using MAIL_SENDER: smtp, file or memory.
+// It panics on any other value so that a mistyped sender can't drop the mails silently.
+func NewSender(env *config.Env) Sender {
+	switch env.MailSender {
+	case "smtp":
+		return NewSmtpSender(env.SMTPHost, env.SMTPPort, env.SMTPUser, env.SMTPPwd, env.MailFrom)
+	case "file":
+		return NewFileOutbox(env.MailOutboxDir)
+	case "memory":
+		return NewMemoryOutbox()
+	default:
+		panic(fmt.Errorf("MAIL_SENDER %q is not one of smtp, file or memory", env.MailSender))
+	}
+}
@@ -0,0 +1,70 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type fileOutbox struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileOutbox writes every message as a file in dir, useful for local development
+func NewFileOutbox(dir string) Sender {
+	return &fileOutbox{dir: dir}
+}
+
+func (o *fileOutbox) Send(msg *Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := os.MkdirAll(o.dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), strings.ReplaceAll(msg.To, "@", "_at_"))
+	content := "To: " + msg.To + "\nSubject: " + msg.Subject + "\n\n" + msg.Body + "\n"
+
+	return os.WriteFile(filepath.Join(o.dir, name), []byte(content), 0644)
+}
+
+type MemoryOutbox struct {
+	mu       sync.Mutex
+	messages []*Message
+}
+
+// NewMemoryOutbox keeps the messages in memory so that tests can read them back
+func NewMemoryOutbox() *MemoryOutbox {
+	return &MemoryOutbox{}
+}
+
+func (o *MemoryOutbox) Send(msg *Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+func (o *MemoryOutbox) Messages() []*Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	list := make([]*Message, len(o.messages))
+	copy(list, o.messages)
+	return list
+}
+
+func (o *MemoryOutbox) LastTo(to string) *Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := len(o.messages) - 1; i >= 0; i-- {
+		if o.messages[i].To == to {
+			return o.messages[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryOutbox(t *testing.T) {
+	outbox := NewMemoryOutbox()
+
+	assert.Nil(t, outbox.LastTo("a@abc.com"))
+
+	assert.NoError(t, outbox.Send(&Message{To: "a@abc.com", Subject: "first"}))
+	assert.NoError(t, outbox.Send(&Message{To: "b@abc.com", Subject: "other"}))
+	assert.NoError(t, outbox.Send(&Message{To: "a@abc.com", Subject: "second"}))
+
+	assert.Len(t, outbox.Messages(), 3)
+	assert.Equal(t, "second", outbox.LastTo("a@abc.com").Subject)
+	assert.Equal(t, "other", outbox.LastTo("b@abc.com").Subject)
+}
+
+func TestFileOutbox(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	outbox := NewFileOutbox(dir)
+
+	err := outbox.Send(&Message{To: "a@abc.com", Subject: "hello", Body: "body text"})
+	assert.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "Subject: hello")
+	assert.Contains(t, string(data), "body text")
+}
+
+func TestNewSender(t *testing.T) {
+	_, ok := NewSender(&config.Env{MailSender: "memory"}).(*MemoryOutbox)
+	assert.True(t, ok)
+
+	_, ok = NewSender(&config.Env{MailSender: "file", MailOutboxDir: t.TempDir()}).(*fileOutbox)
+	assert.True(t, ok)
+
+	assert.Panics(t, func() { NewSender(&config.Env{}) })
+	assert.Panics(t, func() { NewSender(&config.Env{MailSender: "smpt"}) })
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+type smtpSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSmtpSender(host string, port uint16, user string, pwd string, from string) Sender {
+	return &smtpSender{
+		addr: fmt.Sprintf("%s:%d", host, port),
+		auth: smtp.PlainAuth("", user, pwd, host),
+		from: from,
+	}
+}
+
+func (s *smtpSender) Send(msg *Message) error {
+	var b strings.Builder
+	b.WriteString("From: " + s.from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, []byte(b.String()))
+}
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS user_tokens_user_purpose_idx;
\ No newline at end of file
@@ -0,0 +1,2 @@
+CREATE INDEX user_tokens_user_purpose_idx
+ON user_tokens (user_id, purpose, created_at DESC);
\ No newline at end of file
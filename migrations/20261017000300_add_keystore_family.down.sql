@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS keystore_family_idx;
+
+ALTER TABLE keystore
+	DROP COLUMN IF EXISTS rotated_at,
+	DROP COLUMN IF EXISTS parent_id,
+	DROP COLUMN IF EXISTS family_id;
\ No newline at end of file
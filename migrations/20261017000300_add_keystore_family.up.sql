@@ -0,0 +1,14 @@
+ALTER TABLE keystore
+	ADD COLUMN family_id UUID,
+	ADD COLUMN parent_id UUID REFERENCES keystore(id) ON DELETE SET NULL,
+	ADD COLUMN rotated_at TIMESTAMP;
+
+-- every existing keystore is the root of its own family, the family of a new keystore
+-- is set by the service, a root has id = family_id and is never deleted by the cleanup
+UPDATE keystore SET family_id = id WHERE family_id IS NULL;
+
+ALTER TABLE keystore
+	ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX keystore_family_idx
+ON keystore (family_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS token_reuse_events;
\ No newline at end of file
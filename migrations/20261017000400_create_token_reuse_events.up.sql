@@ -0,0 +1,7 @@
+CREATE TABLE token_reuse_events (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	family_id UUID NOT NULL,
+	keystore_id UUID NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE keystore
+	ADD COLUMN user_agent TEXT,
+	ADD COLUMN ip TEXT,
+	ADD COLUMN last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS user_recovery_codes_user_idx;
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
+
+ALTER TABLE users
+	DROP COLUMN IF EXISTS mfa_enabled;
@@ -0,0 +1,3 @@
+ALTER TABLE api_keys
+	ADD COLUMN expires_at TIMESTAMP,
+	ADD COLUMN allowed_origins TEXT[];
@@ -0,0 +1,13 @@
+-- the raw keys can not be recovered from the hashes,
+-- every key has to be issued again after the rollback
+DELETE FROM api_keys;
+
+DROP INDEX IF EXISTS api_keys_key_hash_status_idx;
+
+ALTER TABLE api_keys
+	DROP COLUMN IF EXISTS key_prefix,
+	DROP COLUMN IF EXISTS key_hash,
+	ADD COLUMN key TEXT NOT NULL UNIQUE;
+
+CREATE INDEX api_keys_key_status_idx
+ON api_keys (key, status);
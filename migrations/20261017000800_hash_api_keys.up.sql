@@ -0,0 +1,20 @@
+ALTER TABLE api_keys
+	ADD COLUMN key_hash TEXT,
+	ADD COLUMN key_prefix TEXT;
+
+-- sha256 hex digest, same as utils.HashToken
+UPDATE api_keys
+SET
+	key_hash = encode(sha256(convert_to(key, 'UTF8')), 'hex'),
+	key_prefix = left(key, 6);
+
+DROP INDEX IF EXISTS api_keys_key_status_idx;
+
+ALTER TABLE api_keys
+	ALTER COLUMN key_hash SET NOT NULL,
+	ALTER COLUMN key_prefix SET NOT NULL,
+	ADD CONSTRAINT api_keys_key_hash_key UNIQUE (key_hash),
+	DROP COLUMN key;
+
+CREATE INDEX api_keys_key_hash_status_idx
+ON api_keys (key_hash, status);
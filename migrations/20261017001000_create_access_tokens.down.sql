@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS access_tokens_user_idx;
+
+DROP TABLE IF EXISTS access_tokens;
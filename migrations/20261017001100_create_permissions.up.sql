@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS permissions (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	code TEXT NOT NULL UNIQUE,
+	description TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+	role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	permission_id UUID NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+	PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS role_inheritance (
+	role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	inherited_role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	PRIMARY KEY (role_id, inherited_role_id),
+	CHECK (role_id <> inherited_role_id)
+);
+
+INSERT INTO permissions (code, description)
+VALUES
+	('blog.write', 'write and submit own blogs'),
+	('blog.publish', 'review, publish and unpublish blogs'),
+	('apikey.manage', 'manage the api keys'),
+	('lockout.manage', 'clear sign in lockouts'),
+	('permission.manage', 'inspect and reload the role permissions')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+INNER JOIN permissions p
+	ON (r.code = 'AUTHOR' AND p.code = 'blog.write')
+	OR (r.code = 'EDITOR' AND p.code = 'blog.publish')
+	OR (r.code = 'ADMIN' AND p.code IN ('apikey.manage', 'lockout.manage', 'permission.manage'))
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_inheritance (role_id, inherited_role_id)
+SELECT r.id, i.id
+FROM roles r
+INNER JOIN roles i
+	ON (r.code = 'ADMIN' AND i.code = 'EDITOR')
+	OR (r.code = 'EDITOR' AND i.code = 'AUTHOR')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,10 @@
+INSERT INTO permissions (code, description)
+VALUES ('user.manage', 'manage the users, their roles and account status')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+INNER JOIN permissions p
+	ON r.code = 'ADMIN' AND p.code = 'user.manage'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+	id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	action TEXT NOT NULL,
+	outcome TEXT NOT NULL,
+	actor_id UUID,
+	target_type TEXT NOT NULL DEFAULT '',
+	target_id TEXT NOT NULL DEFAULT '',
+	ip TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	metadata JSONB NOT NULL DEFAULT '{}',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actor_id, id DESC);
+CREATE INDEX IF NOT EXISTS audit_events_action_idx ON audit_events (action, id DESC);
+CREATE INDEX IF NOT EXISTS audit_events_target_idx ON audit_events (target_type, target_id, id DESC);
+
+-- the events are kept untouched, rows are never updated or deleted
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+INSERT INTO permissions (code, description)
+VALUES ('audit.read', 'query the security audit events')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+INNER JOIN permissions p
+	ON r.code = 'ADMIN' AND p.code = 'audit.read'
+ON CONFLICT DO NOTHING;
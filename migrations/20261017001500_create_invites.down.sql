@@ -0,0 +1,4 @@
+DELETE FROM permissions
+WHERE code = 'user.invite';
+
+DROP TABLE IF EXISTS invites;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS invites (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	email TEXT NOT NULL,
+	roles TEXT[] NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+	accepted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+	expires_at TIMESTAMP NOT NULL,
+	accepted_at TIMESTAMP,
+	revoked_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- an email has at most one pending invite, a new invite revokes the previous one
+CREATE UNIQUE INDEX IF NOT EXISTS invites_pending_email_idx
+	ON invites (email)
+	WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS invites_created_idx ON invites (created_at DESC);
+
+INSERT INTO permissions (code, description)
+VALUES ('user.invite', 'invite users with the author, editor or admin roles')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+INNER JOIN permissions p
+	ON r.code = 'EDITOR' AND p.code = 'user.invite'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,7 @@
+DELETE FROM permissions
+WHERE code = 'comment.moderate';
+
+DROP TABLE IF EXISTS comments;
+
+ALTER TABLE blogs
+	DROP COLUMN IF EXISTS comments;
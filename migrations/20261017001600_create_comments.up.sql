@@ -0,0 +1,41 @@
+ALTER TABLE blogs
+	ADD COLUMN IF NOT EXISTS comments BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS comments (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	blog_id UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+	parent_id UUID REFERENCES comments(id) ON DELETE CASCADE,
+	author_id UUID NOT NULL REFERENCES users(id),
+	text TEXT NOT NULL,
+	depth SMALLINT NOT NULL DEFAULT 0,
+	replies BIGINT NOT NULL DEFAULT 0,
+	pinned BOOLEAN NOT NULL DEFAULT FALSE,
+	hidden BOOLEAN NOT NULL DEFAULT FALSE,
+	status BOOLEAN NOT NULL DEFAULT TRUE,
+	edited_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- the comments on a blog, pinned first
+CREATE INDEX IF NOT EXISTS comments_blog_idx
+	ON comments (blog_id, pinned DESC, created_at DESC)
+	WHERE parent_id IS NULL;
+
+-- the replies of a comment, oldest first
+CREATE INDEX IF NOT EXISTS comments_parent_idx
+	ON comments (parent_id, created_at)
+	WHERE parent_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS comments_author_idx ON comments (author_id);
+
+INSERT INTO permissions (code, description)
+VALUES ('comment.moderate', 'hide, pin and delete the comments on any blog')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+INNER JOIN permissions p
+	ON r.code = 'EDITOR' AND p.code = 'comment.moderate'
+ON CONFLICT DO NOTHING;
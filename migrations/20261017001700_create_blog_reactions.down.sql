@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS blog_reactions;
+
+DROP FUNCTION IF EXISTS blog_reactions_take_back_likes();
+
+ALTER TABLE blogs
+	DROP COLUMN IF EXISTS likes;
@@ -0,0 +1,41 @@
+ALTER TABLE blogs
+	ADD COLUMN IF NOT EXISTS likes BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS blog_reactions (
+	blog_id UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	reaction TEXT NOT NULL DEFAULT 'LIKE',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (blog_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS blog_reactions_user_idx ON blog_reactions (user_id);
+
+-- a deleted user takes back the likes, the reactions removed by an unlike belong to an
+-- existing user and are counted by the like service
+CREATE OR REPLACE FUNCTION blog_reactions_take_back_likes() RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE blogs AS b
+	SET
+		likes = b.likes - r.removed,
+		score = LEAST(1, 0.01 + LN(1 + b.likes - r.removed + 2 * b.comments) / LN(10001))
+	FROM (
+		SELECT o.blog_id, COUNT(*) AS removed
+		FROM removed_reactions o
+		WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = o.user_id)
+		GROUP BY o.blog_id
+	) r
+	WHERE b.id = r.blog_id;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER blog_reactions_take_back_likes
+AFTER DELETE ON blog_reactions
+REFERENCING OLD TABLE AS removed_reactions
+FOR EACH STATEMENT EXECUTE FUNCTION blog_reactions_take_back_likes();
+
+-- the score of the existing blogs follows the same formula as the likes and the comments move
+UPDATE blogs
+SET score = LEAST(1, 0.01 + LN(1 + likes + 2 * comments) / LN(10001));
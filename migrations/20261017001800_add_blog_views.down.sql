@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS blog_view_flushes;
+
+ALTER TABLE blogs
+	DROP COLUMN IF EXISTS views;
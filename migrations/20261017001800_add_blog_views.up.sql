@@ -0,0 +1,10 @@
+ALTER TABLE blogs
+	ADD COLUMN IF NOT EXISTS views BIGINT NOT NULL DEFAULT 0;
+
+-- the flushes of the views counted in redis, a flush is added to the blogs only once
+CREATE TABLE IF NOT EXISTS blog_view_flushes (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS blog_view_flushes_created_idx ON blog_view_flushes (created_at);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS blog_reports;
+
+DROP INDEX IF EXISTS blogs_flagged_idx;
+
+ALTER TABLE blogs
+	DROP COLUMN IF EXISTS flagged;
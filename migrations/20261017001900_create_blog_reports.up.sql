@@ -0,0 +1,27 @@
+ALTER TABLE blogs
+	ADD COLUMN IF NOT EXISTS flagged BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- the moderation queue
+CREATE INDEX IF NOT EXISTS blogs_flagged_idx
+	ON blogs (id)
+	WHERE flagged = TRUE;
+
+CREATE TABLE IF NOT EXISTS blog_reports (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	blog_id UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+	reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	reason TEXT NOT NULL,
+	text TEXT,
+	resolution TEXT,
+	resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+	resolved_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- a user has one open report on a blog
+CREATE UNIQUE INDEX IF NOT EXISTS blog_reports_open_idx
+	ON blog_reports (blog_id, reporter_id)
+	WHERE resolution IS NULL;
+
+CREATE INDEX IF NOT EXISTS blog_reports_reporter_idx ON blog_reports (reporter_id);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS blog_revisions (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	blog_id UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+	version INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	title TEXT NOT NULL,
+	description TEXT NOT NULL,
+	text TEXT NOT NULL,
+	tags TEXT[],
+	restored_from INTEGER,
+	created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (blog_id, version)
+);
+
+-- the current draft of the existing blogs is their first revision
+INSERT INTO blog_revisions (blog_id, version, kind, title, description, text, tags, created_by, created_at)
+SELECT id, 1, 'DRAFT', title, description, draft_text, tags, author_id, updated_at
+FROM blogs
+ON CONFLICT (blog_id, version) DO NOTHING;
@@ -4,15 +4,39 @@ import (
 	"context"
 
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/accesstoken"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/apikey"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/audit"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/cache"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/hasher"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/impersonation"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/invite"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/jwks"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/lockout"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/magiclink"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/mfa"
 	authMW "github.com/afteracademy/goserve-example-api-server-postgres/api/auth/middleware"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/oidc"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/password"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/permission"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/session"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/verification"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/author"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/comment"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/editor"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/like"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/report"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/revision"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/view"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/blogs"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/contact"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/health"
 	"github.com/afteracademy/goserve-example-api-server-postgres/api/user"
+	userAdmin "github.com/afteracademy/goserve-example-api-server-postgres/api/user/admin"
+	"github.com/afteracademy/goserve-example-api-server-postgres/common"
 	"github.com/afteracademy/goserve-example-api-server-postgres/config"
+	"github.com/afteracademy/goserve-example-api-server-postgres/mailer"
 	coreMW "github.com/afteracademy/goserve/v2/middleware"
 	"github.com/afteracademy/goserve/v2/network"
 	"github.com/afteracademy/goserve/v2/postgres"
@@ -22,14 +46,27 @@ import (
 type Module network.Module[module]
 
 type module struct {
-	Context       context.Context
-	Env           *config.Env
-	DB            postgres.Database
-	Store         redis.Store
-	UserService   user.Service
-	AuthService   auth.Service
-	BlogService   blog.Service
-	HealthService health.Service
+	Context             context.Context
+	Env                 *config.Env
+	DB                  postgres.Database
+	Store               redis.Store
+	Mailer              mailer.Sender
+	KeyRing             jwks.KeyRing
+	AuthCache           cache.Service
+	AuditService        audit.Service
+	TokenCookies        common.TokenCookies
+	PasswordHasher      hasher.Hasher
+	PasswordPolicy      hasher.Policy
+	UserService         user.Service
+	VerificationService verification.Service
+	LockoutService      lockout.Service
+	MfaService          mfa.Service
+	AuthService         auth.Service
+	PermissionService   permission.Service
+	BlogService         blog.Service
+	ViewService         view.Service
+	RevisionService     revision.Service
+	HealthService       health.Service
 }
 
 func (m *module) GetInstance() *module {
@@ -38,16 +75,38 @@ func (m *module) GetInstance() *module {
 
 // OpenControllers are controllers that do not require api key authentication
 func (m *module) OpenControllers() []network.Controller {
-	return []network.Controller{health.NewController(m.HealthService)}
+	return []network.Controller{
+		health.NewController(m.HealthService),
+		jwks.NewController(jwks.NewService(m.KeyRing)),
+	}
 }
 
 func (m *module) Controllers() []network.Controller {
 	return []network.Controller{
-		auth.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.AuthService),
+		auth.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.AuthService, m.TokenCookies),
+		verification.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.VerificationService),
+		session.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), session.NewService(m.DB, m.AuthCache)),
+		mfa.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.MfaService),
+		oidc.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), oidc.NewService(m.DB, m.Env, m.Store, m.AuthService, m.UserService), m.TokenCookies),
+		lockout.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.LockoutService),
+		permission.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.PermissionService),
+		audit.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.AuditService),
+		apikey.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), apikey.NewService(m.DB, m.AuthCache)),
+		accesstoken.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), accesstoken.NewService(m.DB, m.AuthCache)),
+		password.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), password.NewService(m.Env, m.AuthService, m.UserService, m.VerificationService, m.LockoutService, m.Mailer, m.PasswordHasher, m.PasswordPolicy)),
+		magiclink.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), magiclink.NewService(m.Env, m.AuthService, m.UserService, m.VerificationService, m.Mailer), m.TokenCookies),
+		invite.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), invite.NewService(m.DB, m.Env, m.AuthService, m.UserService, m.AuditService, m.AuthCache, m.Mailer, m.PasswordHasher, m.PasswordPolicy), m.TokenCookies),
 		user.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.UserService),
-		blog.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.BlogService),
+		impersonation.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), impersonation.NewService(m.AuthService, m.UserService, m.AuditService)),
+		userAdmin.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), userAdmin.NewService(m.DB, m.AuthService, m.AuthCache)),
+		blog.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.BlogService, m.ViewService, m.TokenCookies),
 		author.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), author.NewService(m.DB, m.BlogService)),
 		editor.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), editor.NewService(m.DB, m.UserService)),
+		revision.NewAuthorController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.RevisionService),
+		revision.NewEditorController(m.AuthenticationProvider(), m.AuthorizationProvider(), m.RevisionService),
+		comment.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), comment.NewService(m.DB, m.Store, m.PermissionService, m.MfaService.RequiredRoles()...)),
+		like.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), like.NewService(m.DB)),
+		report.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), report.NewService(m.Env, m.DB)),
 		blogs.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), blogs.NewService(m.DB, m.Store)),
 		contact.NewController(m.AuthenticationProvider(), m.AuthorizationProvider(), contact.NewService(m.DB)),
 	}
@@ -62,27 +121,59 @@ func (m *module) RootMiddlewares() []network.RootMiddleware {
 }
 
 func (m *module) AuthenticationProvider() network.AuthenticationProvider {
-	return authMW.NewAuthenticationProvider(m.AuthService, m.UserService)
+	return authMW.NewAuthenticationProvider(
+		m.AuthService,
+		m.UserService,
+		m.PermissionService,
+		m.AuditService,
+		m.TokenCookies,
+		m.MfaService.RequiredRoles()...,
+	)
 }
 
 func (m *module) AuthorizationProvider() network.AuthorizationProvider {
-	return authMW.NewAuthorizationProvider()
+	return authMW.NewAuthorizationProvider(m.PermissionService, m.AuditService, m.MfaService.RequiredRoles()...)
 }
 
 func NewModule(context context.Context, env *config.Env, db postgres.Database, store redis.Store) Module {
-	userService := user.NewService(db)
-	authService := auth.NewService(db, env, userService)
+	mailSender := mailer.NewSender(env)
+	authCache := cache.NewService(env, store)
+	auditService := audit.NewService(db)
+	userService := user.NewService(db, authCache)
+	verificationService := verification.NewService(db, env, userService, mailSender)
+	keyRing := jwks.NewKeyRing(env)
+	lockoutService := lockout.NewService(env, store)
+	mfaService := mfa.NewService(db, env, store, authCache)
+	passwordHasher := hasher.NewHasher(env)
+	passwordPolicy := hasher.NewPolicy(env)
+	authService := auth.NewService(db, env, keyRing, userService, verificationService, lockoutService, mfaService, authCache, auditService, passwordHasher, passwordPolicy)
+	permissionService := permission.NewService(db, env)
 	blogService := blog.NewService(db, store, userService)
+	viewService := view.NewService(env, db, store)
+	revisionService := revision.NewService(db)
 	healthService := health.NewService()
 
 	return &module{
-		Context:       context,
-		Env:           env,
-		DB:            db,
-		Store:         store,
-		UserService:   userService,
-		AuthService:   authService,
-		BlogService:   blogService,
-		HealthService: healthService,
+		Context:             context,
+		Env:                 env,
+		DB:                  db,
+		Store:               store,
+		Mailer:              mailSender,
+		KeyRing:             keyRing,
+		AuthCache:           authCache,
+		AuditService:        auditService,
+		TokenCookies:        common.NewTokenCookies(env),
+		PasswordHasher:      passwordHasher,
+		PasswordPolicy:      passwordPolicy,
+		UserService:         userService,
+		VerificationService: verificationService,
+		LockoutService:      lockoutService,
+		MfaService:          mfaService,
+		AuthService:         authService,
+		PermissionService:   permissionService,
+		BlogService:         blogService,
+		ViewService:         viewService,
+		RevisionService:     revisionService,
+		HealthService:       healthService,
 	}
 }
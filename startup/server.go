@@ -55,6 +55,10 @@ func create(env *config.Env) (network.Router, Module, Shutdown) {
 	router.LoadControllers(module.Controllers())
 
 	shutdown := func() {
+		// the queued audit events are written before the database goes away
+		module.GetInstance().AuditService.Close()
+		// the views counted in redis are added to the blogs before the stores go away
+		module.GetInstance().ViewService.Close()
 		db.Disconnect()
 		store.Disconnect()
 	}
@@ -41,7 +41,7 @@ func TestIntegrationAuthController_SignupSuccess(t *testing.T) {
 		t.Fatalf("could not create key: %v", err)
 	}
 
-	apikey, err = module.GetInstance().AuthService.CreateApiKey(key, 1, []model.Permission{"test"}, []string{"comment"})
+	apikey, err = module.GetInstance().AuthService.CreateApiKey(key, 1, []model.Permission{model.GeneralPermission}, []string{"comment"})
 	if err != nil {
 		t.Fatalf("could not create apikey: %v", err)
 	}
@@ -51,7 +51,7 @@ func TestIntegrationAuthController_SignupSuccess(t *testing.T) {
 		t.Fatalf("could not create role: %v", err)
 	}
 
-	body := `{"email":"test@abc.com","password":"123456","name":"test name"}`
+	body := `{"email":"test@abc.com","password":"blue-Ocean7","name":"test name"}`
 
 	req, err := http.NewRequest("POST", "/auth/signup/basic", bytes.NewBuffer([]byte(body)))
 	if err != nil {
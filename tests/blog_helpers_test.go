@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/startup"
+	"github.com/google/uuid"
+)
+
+func createTestUser(t *testing.T, module startup.Module, email string, verified bool) *userModel.User {
+	t.Cleanup(func() {
+		module.GetInstance().UserService.RemoveUserByEmail(email)
+	})
+
+	user, err := module.GetInstance().UserService.CreateUser(email, "blue-Ocean7", "test name", nil, nil)
+	if err != nil {
+		t.Fatalf("could not create user: %v", err)
+	}
+
+	if verified {
+		_, err = module.GetInstance().DB.Pool().Exec(
+			context.Background(),
+			`UPDATE users SET verified = TRUE WHERE id = $1`,
+			user.ID,
+		)
+		if err != nil {
+			t.Fatalf("could not verify user: %v", err)
+		}
+		user.Verified = true
+	}
+	return user
+}
+
+// createPublishedBlog is removed before its author since the blogs keep their authors
+func createPublishedBlog(t *testing.T, module startup.Module, author *userModel.User) uuid.UUID {
+	ctx := context.Background()
+	db := module.GetInstance().DB
+
+	query := `
+		INSERT INTO blogs (title, description, text, draft_text, author_id, slug, drafted, published, published_at)
+		VALUES ('test blog', 'test description', 'test text', 'test text', $1, $2, FALSE, TRUE, CURRENT_TIMESTAMP)
+		RETURNING id
+	`
+
+	var id uuid.UUID
+	err := db.Pool().QueryRow(ctx, query, author.ID, "test-blog-"+uuid.NewString()).Scan(&id)
+	if err != nil {
+		t.Fatalf("could not create blog: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Pool().Exec(ctx, `DELETE FROM blogs WHERE id = $1`, id)
+	})
+	return id
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/permission"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/comment"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/comment/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/startup"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIntegrationCommentService_ModeratorRequiresMfa(t *testing.T) {
+	_, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	permissionService := new(permission.MockService)
+	permissionService.On("HasAnyPermission", userModel.RoleCodeEditor, mock.Anything).Return(true)
+	permissionService.On("HasAnyPermission", mock.Anything, mock.Anything).Return(false)
+
+	commentService := comment.NewService(
+		module.GetInstance().DB,
+		module.GetInstance().Store,
+		permissionService,
+		userModel.RoleCodeEditor,
+	)
+
+	commenter := createTestUser(t, module, "comment-test-commenter@abc.com", true)
+	author := createTestUser(t, module, "comment-test-author@abc.com", true)
+	blogId := createPublishedBlog(t, module, author)
+
+	created, err := commentService.CreateComment(&dto.CommentCreate{BlogID: blogId, Text: "test comment"}, commenter)
+	if err != nil {
+		t.Fatalf("could not create comment: %v", err)
+	}
+
+	editor := &userModel.User{
+		ID:    uuid.New(),
+		Roles: []*userModel.Role{{Code: userModel.RoleCodeEditor}},
+	}
+
+	err = commentService.HideComment(created.ID, editor, true)
+	assertApiErrorCode(t, err, http.StatusForbidden)
+
+	editor.MfaEnabled = true
+	err = commentService.HideComment(created.ID, editor, true)
+	assert.NoError(t, err)
+
+	// the blog author moderates without any role
+	err = commentService.HideComment(created.ID, author, false)
+	assert.NoError(t, err)
+
+	err = commentService.HideComment(created.ID, commenter, true)
+	assertApiErrorCode(t, err, http.StatusForbidden)
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/editor"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/startup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrationEditorService_ResolveFlagOfUnpublishedBlog(t *testing.T) {
+	_, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	ctx := context.Background()
+	db := module.GetInstance().DB
+	editorService := editor.NewService(db, module.GetInstance().UserService)
+
+	reporter := createTestUser(t, module, "editor-test-reporter@abc.com", true)
+	moderator := createTestUser(t, module, "editor-test-editor@abc.com", true)
+	author := createTestUser(t, module, "editor-test-author@abc.com", true)
+	blogId := createPublishedBlog(t, module, author)
+
+	_, err := db.Pool().Exec(ctx, `UPDATE blogs SET flagged = TRUE WHERE id = $1`, blogId)
+	assert.NoError(t, err)
+	_, err = db.Pool().Exec(
+		ctx,
+		`INSERT INTO blog_reports (blog_id, reporter_id, reason) VALUES ($1, $2, $3)`,
+		blogId, reporter.ID, model.ReportReasonSpam,
+	)
+	assert.NoError(t, err)
+
+	// unpublished by an editor before the flag was resolved
+	err = editorService.BlogPublication(blogId, false, moderator)
+	assert.NoError(t, err)
+
+	err = editorService.ResolveFlag(blogId, model.ReportUnpublished, moderator)
+	assert.NoError(t, err)
+
+	var flagged, published bool
+	err = db.Pool().QueryRow(ctx, `SELECT flagged, published FROM blogs WHERE id = $1`, blogId).Scan(&flagged, &published)
+	assert.NoError(t, err)
+	assert.False(t, flagged)
+	assert.False(t, published)
+
+	var resolution model.ReportResolution
+	err = db.Pool().QueryRow(ctx, `SELECT resolution FROM blog_reports WHERE blog_id = $1`, blogId).Scan(&resolution)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReportUnpublished, resolution)
+}
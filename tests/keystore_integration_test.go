@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	userModel "github.com/afteracademy/goserve-example-api-server-postgres/api/user/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/startup"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const keystoreTestEmail = "keystore-test@abc.com"
+
+func createKeystoreTestUser(t *testing.T, module startup.Module) *userModel.User {
+	t.Cleanup(func() {
+		module.GetInstance().UserService.RemoveUserByEmail(keystoreTestEmail)
+	})
+
+	user, err := module.GetInstance().UserService.CreateUser(keystoreTestEmail, "blue-Ocean7", "test name", nil, nil)
+	if err != nil {
+		t.Fatalf("could not create user: %v", err)
+	}
+	return user
+}
+
+func keystoreFamily(t *testing.T, module startup.Module, accessToken string) (uuid.UUID, string) {
+	claims, err := module.GetInstance().AuthService.DecodeToken(accessToken)
+	if err != nil {
+		t.Fatalf("could not decode token: %v", err)
+	}
+
+	var familyId uuid.UUID
+	err = module.GetInstance().DB.Pool().QueryRow(
+		context.Background(),
+		`SELECT family_id FROM keystore WHERE p_key = $1`,
+		claims.ID,
+	).Scan(&familyId)
+	if err != nil {
+		t.Fatalf("could not find keystore: %v", err)
+	}
+	return familyId, claims.ID
+}
+
+func countFamilyKeystores(t *testing.T, module startup.Module, familyId uuid.UUID) int {
+	var count int
+	err := module.GetInstance().DB.Pool().QueryRow(
+		context.Background(),
+		`SELECT COUNT(*) FROM keystore WHERE family_id = $1`,
+		familyId,
+	).Scan(&count)
+	if err != nil {
+		t.Fatalf("could not count keystores: %v", err)
+	}
+	return count
+}
+
+func TestIntegrationAuthService_RenewTokenReplayRevokesFamily(t *testing.T) {
+	_, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	authService := module.GetInstance().AuthService
+	clientInfo := dto.NewClientInfo("test", "127.0.0.1")
+	user := createKeystoreTestUser(t, module)
+
+	accessToken, refreshToken, err := authService.GenerateToken(user, clientInfo)
+	assert.NoError(t, err)
+	familyId, rootKey := keystoreFamily(t, module, accessToken)
+
+	tokens, err := authService.RenewToken(&dto.TokenRefresh{RefreshToken: refreshToken}, accessToken, clientInfo)
+	assert.NoError(t, err)
+
+	childFamilyId, childKey := keystoreFamily(t, module, tokens.AccessToken)
+	assert.Equal(t, familyId, childFamilyId)
+	assert.NotEqual(t, rootKey, childKey)
+
+	_, err = authService.RenewToken(&dto.TokenRefresh{RefreshToken: refreshToken}, accessToken, clientInfo)
+	assert.Error(t, err)
+
+	_, err = authService.FetchKeystore(user, childKey)
+	assert.Error(t, err)
+	assert.Equal(t, 0, countFamilyKeystores(t, module, familyId))
+}
+
+// the refresh token is renewed while the keystore row is locked by a concurrent rotation,
+// the renewal read the keystore before that rotation committed and so loses the race
+func TestIntegrationAuthService_RenewTokenLostRaceRevokesFamily(t *testing.T) {
+	_, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	ctx := context.Background()
+	db := module.GetInstance().DB
+	authService := module.GetInstance().AuthService
+	clientInfo := dto.NewClientInfo("test", "127.0.0.1")
+	user := createKeystoreTestUser(t, module)
+
+	accessToken, refreshToken, err := authService.GenerateToken(user, clientInfo)
+	assert.NoError(t, err)
+	familyId, rootKey := keystoreFamily(t, module, accessToken)
+
+	tx, err := db.Pool().Begin(ctx)
+	if err != nil {
+		t.Fatalf("could not begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `UPDATE keystore SET status = FALSE, rotated_at = CURRENT_TIMESTAMP WHERE p_key = $1`, rootKey)
+	if err != nil {
+		t.Fatalf("could not rotate keystore: %v", err)
+	}
+
+	renewed := make(chan error, 1)
+	go func() {
+		_, err := authService.RenewToken(&dto.TokenRefresh{RefreshToken: refreshToken}, accessToken, clientInfo)
+		renewed <- err
+	}()
+
+	waitForLockWait(t, module)
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("could not commit: %v", err)
+	}
+
+	select {
+	case err = <-renewed:
+		assert.Error(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("renewal did not return")
+	}
+
+	var events int
+	err = db.Pool().QueryRow(ctx, `SELECT COUNT(*) FROM token_reuse_events WHERE family_id = $1`, familyId).Scan(&events)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, events)
+	assert.Equal(t, 0, countFamilyKeystores(t, module, familyId))
+}
+
+// waitForLockWait returns once a query waits on a row lock, the renewal blocks on its rotation
+func waitForLockWait(t *testing.T, module startup.Module) {
+	ctx := context.Background()
+	for i := 0; i < 100; i++ {
+		var waiting int
+		err := module.GetInstance().DB.Pool().QueryRow(
+			ctx,
+			`SELECT COUNT(*) FROM pg_stat_activity WHERE datname = current_database() AND wait_event_type = 'Lock'`,
+		).Scan(&waiting)
+		if err != nil {
+			t.Fatalf("could not read the activity: %v", err)
+		}
+		if waiting > 0 {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("renewal did not wait on the keystore")
+}
+
+func TestIntegrationAuthService_SignOutRemovesFamily(t *testing.T) {
+	_, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	authService := module.GetInstance().AuthService
+	clientInfo := dto.NewClientInfo("test", "127.0.0.1")
+	user := createKeystoreTestUser(t, module)
+
+	accessToken, refreshToken, err := authService.GenerateToken(user, clientInfo)
+	assert.NoError(t, err)
+	familyId, _ := keystoreFamily(t, module, accessToken)
+
+	otherAccessToken, _, err := authService.GenerateToken(user, clientInfo)
+	assert.NoError(t, err)
+	otherFamilyId, _ := keystoreFamily(t, module, otherAccessToken)
+
+	for i := 0; i < 2; i++ {
+		tokens, err := authService.RenewToken(&dto.TokenRefresh{RefreshToken: refreshToken}, accessToken, clientInfo)
+		if err != nil {
+			t.Fatalf("could not renew: %v", err)
+		}
+		accessToken, refreshToken = tokens.AccessToken, tokens.RefreshToken
+	}
+	assert.Equal(t, 3, countFamilyKeystores(t, module, familyId))
+
+	_, primaryKey := keystoreFamily(t, module, accessToken)
+	keystore, err := authService.FetchKeystore(user, primaryKey)
+	assert.NoError(t, err)
+
+	err = authService.SignOut(keystore, clientInfo)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, countFamilyKeystores(t, module, familyId))
+	assert.Equal(t, 1, countFamilyKeystores(t, module, otherFamilyId))
+}
+
+func TestIntegrationAuthService_RotatedKeystoreRejectsAccessToken(t *testing.T) {
+	_, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	authService := module.GetInstance().AuthService
+	clientInfo := dto.NewClientInfo("test", "127.0.0.1")
+	user := createKeystoreTestUser(t, module)
+
+	accessToken, refreshToken, err := authService.GenerateToken(user, clientInfo)
+	assert.NoError(t, err)
+	_, primaryKey := keystoreFamily(t, module, accessToken)
+
+	// caches the keystore so that the rotation has to clear it
+	_, err = authService.FetchKeystore(user, primaryKey)
+	assert.NoError(t, err)
+
+	tokens, err := authService.RenewToken(&dto.TokenRefresh{RefreshToken: refreshToken}, accessToken, clientInfo)
+	assert.NoError(t, err)
+
+	_, err = authService.FetchKeystore(user, primaryKey)
+	assert.Error(t, err)
+
+	_, childKey := keystoreFamily(t, module, tokens.AccessToken)
+	_, err = authService.FetchKeystore(user, childKey)
+	assert.NoError(t, err)
+}
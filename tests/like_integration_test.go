@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/like"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/startup"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrationLikeService_LikesAndScore(t *testing.T) {
+	_, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	ctx := context.Background()
+	db := module.GetInstance().DB
+	likeService := like.NewService(db)
+
+	liker := createTestUser(t, module, "like-test-liker@abc.com", true)
+	other := createTestUser(t, module, "like-test-other@abc.com", true)
+	author := createTestUser(t, module, "like-test-author@abc.com", true)
+	blogId := createPublishedBlog(t, module, author)
+
+	blogLikes := func() (int64, float64) {
+		var (
+			likes    int64
+			comments int64
+			score    float64
+		)
+		err := db.Pool().QueryRow(ctx, `SELECT likes, comments, score FROM blogs WHERE id = $1`, blogId).
+			Scan(&likes, &comments, &score)
+		if err != nil {
+			t.Fatalf("could not read blog: %v", err)
+		}
+		expected := math.Min(1, 0.01+math.Log(float64(1+likes+2*comments))/math.Log(10001))
+		assert.InDelta(t, expected, score, 1e-9)
+		return likes, score
+	}
+
+	likes, score := blogLikes()
+	assert.Equal(t, int64(0), likes)
+
+	info, err := likeService.Like(blogId, liker, model.ReactionLike)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), info.Likes)
+
+	likedLikes, likedScore := blogLikes()
+	assert.Equal(t, likes+1, likedLikes)
+	assert.Greater(t, likedScore, score)
+
+	// liking again only changes the reaction
+	info, err = likeService.Like(blogId, liker, model.ReactionLove)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), info.Likes)
+
+	againLikes, againScore := blogLikes()
+	assert.Equal(t, likedLikes, againLikes)
+	assert.Equal(t, likedScore, againScore)
+
+	summary, err := likeService.GetReactions(blogId)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), summary.Reactions[model.ReactionLove])
+	assert.Equal(t, int64(0), summary.Reactions[model.ReactionLike])
+
+	info, err = likeService.Unlike(blogId, liker)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), info.Likes)
+
+	unlikedLikes, unlikedScore := blogLikes()
+	assert.Equal(t, likes, unlikedLikes)
+	assert.Equal(t, score, unlikedScore)
+
+	// unliking again leaves the likes as they are
+	info, err = likeService.Unlike(blogId, liker)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), info.Likes)
+
+	_, err = likeService.Like(uuid.New(), liker, model.ReactionLike)
+	assert.Error(t, err)
+
+	// a deleted user takes back the like
+	_, err = likeService.Like(blogId, other, model.ReactionLike)
+	assert.NoError(t, err)
+	otherLikes, _ := blogLikes()
+	assert.Equal(t, likes+1, otherLikes)
+
+	deleted, err := module.GetInstance().UserService.RemoveUserByEmail(other.Email)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	deletedLikes, deletedScore := blogLikes()
+	assert.Equal(t, likes, deletedLikes)
+	assert.Equal(t, score, deletedScore)
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/model"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/report"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/report/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/startup"
+	"github.com/afteracademy/goserve/v2/network"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrationReportService_FlagThreshold(t *testing.T) {
+	_, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	ctx := context.Background()
+	db := module.GetInstance().DB
+
+	env := *module.GetInstance().Env
+	env.BlogFlagReportThreshold = 2
+	reportService := report.NewService(&env, db)
+
+	reporter := createTestUser(t, module, "report-test-reporter@abc.com", true)
+	other := createTestUser(t, module, "report-test-other@abc.com", true)
+	unverified := createTestUser(t, module, "report-test-unverified@abc.com", false)
+	author := createTestUser(t, module, "report-test-author@abc.com", true)
+	blogId := createPublishedBlog(t, module, author)
+
+	openReports := func() (int, model.ReportReason) {
+		var (
+			count  int
+			reason model.ReportReason
+		)
+		err := db.Pool().QueryRow(
+			ctx,
+			`SELECT COUNT(*), MAX(reason) FROM blog_reports WHERE blog_id = $1 AND resolution IS NULL`,
+			blogId,
+		).Scan(&count, &reason)
+		if err != nil {
+			t.Fatalf("could not count reports: %v", err)
+		}
+		return count, reason
+	}
+
+	flagged := func() bool {
+		var f bool
+		err := db.Pool().QueryRow(ctx, `SELECT flagged FROM blogs WHERE id = $1`, blogId).Scan(&f)
+		if err != nil {
+			t.Fatalf("could not read blog: %v", err)
+		}
+		return f
+	}
+
+	err := reportService.ReportBlog(blogId, &dto.ReportCreate{Reason: model.ReportReasonSpam}, reporter)
+	assert.NoError(t, err)
+
+	// reporting again replaces the open report
+	err = reportService.ReportBlog(blogId, &dto.ReportCreate{Reason: model.ReportReasonHate}, reporter)
+	assert.NoError(t, err)
+
+	count, reason := openReports()
+	assert.Equal(t, 1, count)
+	assert.Equal(t, model.ReportReasonHate, reason)
+	assert.False(t, flagged())
+
+	err = reportService.ReportBlog(blogId, &dto.ReportCreate{Reason: model.ReportReasonSpam}, unverified)
+	assertApiErrorCode(t, err, http.StatusForbidden)
+
+	err = reportService.ReportBlog(blogId, &dto.ReportCreate{Reason: model.ReportReasonSpam}, author)
+	assertApiErrorCode(t, err, http.StatusBadRequest)
+
+	count, _ = openReports()
+	assert.Equal(t, 1, count)
+	assert.False(t, flagged())
+
+	err = reportService.ReportBlog(blogId, &dto.ReportCreate{Reason: model.ReportReasonSpam}, other)
+	assert.NoError(t, err)
+
+	count, _ = openReports()
+	assert.Equal(t, 2, count)
+	assert.True(t, flagged())
+
+	err = reportService.ReportBlog(uuid.New(), &dto.ReportCreate{Reason: model.ReportReasonSpam}, other)
+	assertApiErrorCode(t, err, http.StatusNotFound)
+}
+
+func assertApiErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+	var apiErr network.ApiError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, code, apiErr.GetCode())
+	}
+}
@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/dto"
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/auth/session"
+	"github.com/afteracademy/goserve-example-api-server-postgres/startup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrationSessionService_RootDeleted(t *testing.T) {
+	_, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	ctx := context.Background()
+	db := module.GetInstance().DB
+	authService := module.GetInstance().AuthService
+	clientInfo := dto.NewClientInfo("test", "127.0.0.1")
+	user := createKeystoreTestUser(t, module)
+
+	accessToken, refreshToken, err := authService.GenerateToken(user, clientInfo)
+	assert.NoError(t, err)
+	familyId, _ := keystoreFamily(t, module, accessToken)
+
+	tokens, err := authService.RenewToken(&dto.TokenRefresh{RefreshToken: refreshToken}, accessToken, clientInfo)
+	assert.NoError(t, err)
+	_, primaryKey := keystoreFamily(t, module, tokens.AccessToken)
+
+	_, err = db.Pool().Exec(ctx, `DELETE FROM keystore WHERE id = family_id AND family_id = $1`, familyId)
+	if err != nil {
+		t.Fatalf("could not delete the family root: %v", err)
+	}
+
+	current, err := authService.FetchKeystore(user, primaryKey)
+	assert.NoError(t, err)
+
+	sessionService := session.NewService(db, module.GetInstance().AuthCache)
+
+	sessions, err := sessionService.GetSessions(user, current)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, familyId, sessions[0].ID)
+	assert.True(t, sessions[0].Current)
+	assert.Equal(t, current.CreatedAt.Unix(), sessions[0].CreatedAt.Unix())
+
+	found, err := sessionService.GetSession(familyId, user, current)
+	assert.NoError(t, err)
+	assert.Equal(t, current.CreatedAt.Unix(), found.CreatedAt.Unix())
+}
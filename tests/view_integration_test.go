@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/afteracademy/goserve-example-api-server-postgres/api/blog/view"
+	"github.com/afteracademy/goserve-example-api-server-postgres/startup"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrationViewService_FlushRestoresStaleFlushing(t *testing.T) {
+	_, module, shutdown := startup.TestServer()
+	defer shutdown()
+
+	ctx := context.Background()
+	client := module.GetInstance().Store.GetInstance()
+	viewService := module.GetInstance().ViewService
+
+	author := createTestUser(t, module, "view-test-author@abc.com", true)
+	blogId := createPublishedBlog(t, module, author)
+
+	blogViews := func() int64 {
+		var views int64
+		err := module.GetInstance().DB.Pool().QueryRow(ctx, `SELECT views FROM blogs WHERE id = $1`, blogId).Scan(&views)
+		if err != nil {
+			t.Fatalf("could not read blog: %v", err)
+		}
+		return views
+	}
+
+	// the views pending from the other tests are flushed first
+	assert.NoError(t, viewService.Flush())
+	before := blogViews()
+
+	// left by a flusher that crashed a while ago
+	staleKey := "blog_views_flushing_" + uuid.NewString()
+	client.HSet(ctx, staleKey, blogId.String(), 3)
+	client.Expire(ctx, staleKey, 10*time.Minute)
+
+	// left by a flusher that added the views but could not delete the key
+	appliedId := uuid.New()
+	appliedKey := "blog_views_flushing_" + appliedId.String()
+	client.HSet(ctx, appliedKey, blogId.String(), 7)
+	client.Expire(ctx, appliedKey, 10*time.Minute)
+	_, err := module.GetInstance().DB.Pool().Exec(ctx, `INSERT INTO blog_view_flushes (id) VALUES ($1)`, appliedId)
+	assert.NoError(t, err)
+
+	// taken by a flusher that is still running
+	runningKey := "blog_views_flushing_" + uuid.NewString()
+	client.HSet(ctx, runningKey, blogId.String(), 5)
+	client.Expire(ctx, runningKey, time.Hour)
+	t.Cleanup(func() {
+		client.Del(ctx, staleKey, appliedKey, runningKey)
+	})
+
+	viewer := view.Viewer(nil, uuid.NewString(), "test")
+	assert.NoError(t, viewService.RecordView(blogId, viewer))
+	// counted once in the window
+	assert.NoError(t, viewService.RecordView(blogId, viewer))
+
+	assert.NoError(t, viewService.Flush())
+	assert.Equal(t, before+4, blogViews())
+
+	stale, err := client.Exists(ctx, staleKey, appliedKey).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), stale)
+
+	running, err := client.Exists(ctx, runningKey).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), running)
+
+	// nothing left to flush
+	assert.NoError(t, viewService.Flush())
+	assert.Equal(t, before+4, blogViews())
+}